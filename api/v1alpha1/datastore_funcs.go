@@ -37,3 +37,25 @@ func (in *ContentRef) GetContent(ctx context.Context, client client.Client) ([]b
 
 	return v, nil
 }
+
+// GetContent is the resolver for the content of the CA bundle:
+// the bare content, and the Secret reference, have priority over the ConfigMap reference.
+func (in *CABundle) GetContent(ctx context.Context, client client.Client) ([]byte, error) {
+	configMapRef := in.ConfigMapRef
+
+	if len(in.Content) > 0 || in.SecretRef != nil || configMapRef == nil {
+		return in.ContentRef.GetContent(ctx, client)
+	}
+
+	configMap, namespacedName := &corev1.ConfigMap{}, types.NamespacedName{Name: configMapRef.Name, Namespace: configMapRef.Namespace}
+	if err := client.Get(ctx, namespacedName, configMap); err != nil {
+		return nil, err
+	}
+
+	v, ok := configMap.Data[configMapRef.KeyPath]
+	if !ok {
+		return nil, fmt.Errorf("configmap %s does not have key %s", namespacedName.String(), configMapRef.KeyPath)
+	}
+
+	return []byte(v), nil
+}
@@ -43,6 +43,10 @@ func (d *DatastoreUsedSecret) ExtractValue() client.IndexerFunc {
 			}
 		}
 
+		if ds.Spec.SharedUser != nil && ds.Spec.SharedUser.Password.SecretRef != nil {
+			res = append(res, d.namespacedName(*ds.Spec.SharedUser.Password.SecretRef))
+		}
+
 		if ds.Spec.TLSConfig.CertificateAuthority.Certificate.SecretRef != nil {
 			res = append(res, d.namespacedName(*ds.Spec.TLSConfig.CertificateAuthority.Certificate.SecretRef))
 		}
@@ -59,6 +63,10 @@ func (d *DatastoreUsedSecret) ExtractValue() client.IndexerFunc {
 			res = append(res, d.namespacedName(*ds.Spec.TLSConfig.ClientCertificate.PrivateKey.SecretRef))
 		}
 
+		if ds.Spec.TLSConfig.ServerCABundle != nil && ds.Spec.TLSConfig.ServerCABundle.SecretRef != nil {
+			res = append(res, d.namespacedName(*ds.Spec.TLSConfig.ServerCABundle.SecretRef))
+		}
+
 		return res
 	}
 }
@@ -8,12 +8,14 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/clastix/kamaji/internal/constants"
 	kamajierrors "github.com/clastix/kamaji/internal/errors"
 )
 
@@ -70,3 +72,137 @@ func (in *TenantControlPlane) DeclaredControlPlaneAddress(ctx context.Context, c
 
 	return "", kamajierrors.MissingValidIPError{}
 }
+
+// DataStoreSchemaAndUser returns the schema and user names used to provision the Tenant Control Plane data
+// in the DataStore: it's a pure function, allowing admission webhooks to validate the names before their creation.
+func (in *TenantControlPlane) DataStoreSchemaAndUser() (schema string, user string) {
+	// the coalesce function prioritizes the return value stored in the TenantControlPlane status,
+	// although this is going to be populated by the UpdateTenantControlPlaneStatus handler of the resource datastore-setup:
+	// the default value will be used for fresh new configurations, and preserving a previous one:
+	// this will keep us safe from naming changes cases as occurred with the following commit:
+	// https://github.com/clastix/kamaji/pull/203/commits/09ce38f489cccca72ab728a259bc8fb2cf6e4770
+	coalesceFn := func(fromStatus string) string {
+		if len(fromStatus) > 0 {
+			return fromStatus
+		}
+		// The dash character (-) must be replaced with an underscore, PostgreSQL is complaining about it:
+		// https://github.com/clastix/kamaji/issues/328
+		return strings.ReplaceAll(fmt.Sprintf("%s_%s", in.GetNamespace(), in.GetName()), "-", "_")
+	}
+
+	schema, user = coalesceFn(in.Status.Storage.Setup.Schema), coalesceFn(in.Status.Storage.Setup.User)
+	// The schema rename requested by annotation takes precedence, being performed in place by the datastore setup.
+	if renamed := in.GetAnnotations()[constants.RenameDataStoreSchema]; len(renamed) > 0 {
+		schema = renamed
+	}
+
+	return schema, user
+}
+
+// DataStoreSchemaAndSharedUser returns the schema and user as DataStoreSchemaAndUser, although the Tenant Control Planes
+// not provisioned yet are using the shared user of the given DataStore, when configured.
+// The names are normalized according to the DataStore driver, such as lowercased for PostgreSQL.
+func (in *TenantControlPlane) DataStoreSchemaAndSharedUser(ds DataStore) (schema string, user string) {
+	schema, user = in.DataStoreSchemaAndUser()
+
+	if sharedUser := ds.Spec.SharedUser; sharedUser != nil && len(in.Status.Storage.Setup.User) == 0 {
+		user = sharedUser.Name
+	}
+	// PostgreSQL folds the unquoted identifiers to lowercase, thus the provisioned names are the folded ones.
+	if ds.Spec.Driver == KinePostgreSQLDriver {
+		schema, user = strings.ToLower(schema), strings.ToLower(user)
+	}
+
+	return schema, user
+}
+
+// The Konnectivity defaults, matching the ones set by the API server according to the KonnectivitySpec markers.
+const (
+	KonnectivityDefaultVersion           = "v0.0.32"
+	KonnectivityServerDefaultImage       = "registry.k8s.io/kas-network-proxy/proxy-server"
+	KonnectivityAgentDefaultImage        = "registry.k8s.io/kas-network-proxy/proxy-agent"
+	KonnectivityServerDefaultPort  int32 = 8132
+)
+
+// ApplyProfile fills in the addons not specified with the ones enabled by the selected profile,
+// resolving it to the concrete addon specifications consumed by the reconciliation: it's applied in memory
+// upon the Tenant Control Plane retrieval, and never persisted, thus the profile changes are reflected,
+// such as the addons removed when switching to the Minimal one.
+func (in *AddonsSpec) ApplyProfile() {
+	if in.Profile != FullAddonsProfile {
+		return
+	}
+
+	if in.CoreDNS == nil {
+		in.CoreDNS = &CoreDNSAddonSpec{}
+	}
+
+	if in.KubeProxy == nil {
+		in.KubeProxy = &KubeProxyAddonSpec{}
+	}
+
+	if in.Konnectivity == nil {
+		in.Konnectivity = &KonnectivitySpec{
+			KonnectivityServerSpec: KonnectivityServerSpec{
+				Port:    KonnectivityServerDefaultPort,
+				Version: KonnectivityDefaultVersion,
+				Image:   KonnectivityServerDefaultImage,
+			},
+			KonnectivityAgentSpec: KonnectivityAgentSpec{
+				Version: KonnectivityDefaultVersion,
+				Image:   KonnectivityAgentDefaultImage,
+			},
+		}
+	}
+}
+
+const (
+	CoreDNSHealthDefaultPort  int32 = 8080
+	CoreDNSReadyDefaultPort   int32 = 8181
+	CoreDNSMetricsDefaultPort int32 = 9153
+)
+
+// IsEnabled returns true unless the plugin has been explicitly disabled.
+func (in *CoreDNSPluginSpec) IsEnabled() bool {
+	return in == nil || in.Enabled == nil || *in.Enabled
+}
+
+// GetPort returns the port the plugin is listening on, or the given default one if not specified.
+func (in *CoreDNSPluginSpec) GetPort(defaultPort int32) int32 {
+	if in == nil || in.Port == nil {
+		return defaultPort
+	}
+
+	return *in.Port
+}
+
+// PluginPorts returns the ports of the enabled CoreDNS plugins keyed by their name, applying the defaults:
+// it's a pure function, allowing admission webhooks to validate the ports before the rendering of the Corefile.
+func (in *CoreDNSAddonSpec) PluginPorts() map[string]int32 {
+	ports := map[string]int32{}
+
+	for name, plugin := range map[string]struct {
+		spec        *CoreDNSPluginSpec
+		defaultPort int32
+	}{
+		"health":     {spec: in.Health, defaultPort: CoreDNSHealthDefaultPort},
+		"ready":      {spec: in.Ready, defaultPort: CoreDNSReadyDefaultPort},
+		"prometheus": {spec: in.Metrics, defaultPort: CoreDNSMetricsDefaultPort},
+	} {
+		if plugin.spec.IsEnabled() {
+			ports[name] = plugin.spec.GetPort(plugin.defaultPort)
+		}
+	}
+
+	return ports
+}
+
+// GetServiceAccountName returns the name of the ServiceAccount the CoreDNS Pods are running as,
+// falling back to the given default one.
+func (in *CoreDNSAddonSpec) GetServiceAccountName(defaultName string) string {
+	if in.ServiceAccount == nil || len(in.ServiceAccount.Name) == 0 {
+		return defaultName
+	}
+
+	return in.ServiceAccount.Name
+}
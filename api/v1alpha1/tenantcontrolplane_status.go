@@ -59,6 +59,8 @@ type DataStoreCertificateStatus struct {
 	SecretName string      `json:"secretName,omitempty"`
 	Checksum   string      `json:"checksum,omitempty"`
 	LastUpdate metav1.Time `json:"lastUpdate,omitempty"`
+	// The expiration time of the etcd client certificate, which is reissued once within the renewal window.
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
 }
 
 type DataStoreConfigStatus struct {
@@ -71,15 +73,119 @@ type DataStoreSetupStatus struct {
 	User       string      `json:"user,omitempty"`
 	LastUpdate metav1.Time `json:"lastUpdate,omitempty"`
 	Checksum   string      `json:"checksum,omitempty"`
+	// The identity of the Kamaji operator instance, such as the Pod name, which performed the latest changes
+	// against the datastore, such as the creation of the user, schema, and privileges.
+	ProvisionedBy string `json:"provisionedBy,omitempty"`
+	// The time of the latest changes performed against the datastore.
+	ProvisionedAt metav1.Time `json:"provisionedAt,omitempty"`
+	// Reports if the user has been cut off from the datastore by means of the kamaji.clastix.io/disable-datastore-user
+	// annotation: it's not cleared upon the annotation removal, since the user login must be restored manually.
+	Disabled bool `json:"disabled,omitempty"`
+	// The time the deletion of the datastore data has been requested, when the DataStore has a deletion grace period:
+	// the data is deleted once it has elapsed.
+	DeletionRequestedAt *metav1.Time `json:"deletionRequestedAt,omitempty"`
+	// The breakdown of the latest changes performed against the datastore.
+	LastChanges *DataStoreSetupChanges `json:"lastChanges,omitempty"`
+	// Reports if the privileges have been granted to the user WITH GRANT OPTION.
+	GrantOption bool `json:"grantOption,omitempty"`
+	// The privileges currently held by the user on the schema, expressed with the driver naming, as reported by
+	// the datastore upon the latest verification: they're refreshed along with the drift check interval.
+	GrantedPrivileges []string `json:"grantedPrivileges,omitempty"`
+	// The checksum of the DataStore extensions installed in the database.
+	ExtensionsChecksum string `json:"extensionsChecksum,omitempty"`
+	// The checksum of the default search_path set to the user in its database.
+	SearchPathChecksum string `json:"searchPathChecksum,omitempty"`
+	// The tablespace requested for the database, applied upon its creation only.
+	Tablespace string `json:"tablespace,omitempty"`
+	// The template database the schema has been initialized from upon its creation, if any.
+	TemplateDatabase string `json:"templateDatabase,omitempty"`
+	// Reports if the user is shared across the tenant schemas, as configured by the DataStore: the shared user is not
+	// deleted, nor disabled, by Kamaji, revoking only its privileges on the schema.
+	SharedUser bool `json:"sharedUser,omitempty"`
+	// Reports if the schema has been created by Kamaji, or adopted by means of the kamaji.clastix.io/adopt-datastore
+	// annotation since already existing along with its data.
+	Origin DataStoreSetupOrigin `json:"origin,omitempty"`
+	// The time the password of the user expires, when the DataStore password expiry is set:
+	// it's refreshed by Kamaji before lapsing.
+	PasswordExpiresAt *metav1.Time `json:"passwordExpiresAt,omitempty"`
+	// The kine image the privileges have been ensured for: they're ensured again upon its change,
+	// such as a kine upgrade relying on new schema objects. It's not reported by the etcd driver.
+	KineImage string `json:"kineImage,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Created;Adopted
+type DataStoreSetupOrigin string
+
+const (
+	DataStoreSetupOriginCreated DataStoreSetupOrigin = "Created"
+	DataStoreSetupOriginAdopted DataStoreSetupOrigin = "Adopted"
+)
+
+// DataStoreSetupChanges reports the outcome of the latest provisioning for each datastore object,
+// such as created, updated, or unchanged.
+type DataStoreSetupChanges struct {
+	Schema     string `json:"schema,omitempty"`
+	User       string `json:"user,omitempty"`
+	Privileges string `json:"privileges,omitempty"`
 }
 
 // StorageStatus defines the observed state of StorageStatus.
 type StorageStatus struct {
-	Driver        string                     `json:"driver,omitempty"`
-	DataStoreName string                     `json:"dataStoreName,omitempty"`
-	Config        DataStoreConfigStatus      `json:"config,omitempty"`
-	Setup         DataStoreSetupStatus       `json:"setup,omitempty"`
-	Certificate   DataStoreCertificateStatus `json:"certificate,omitempty"`
+	Driver        string `json:"driver,omitempty"`
+	DataStoreName string `json:"dataStoreName,omitempty"`
+	// The endpoints of the datastore used by the Tenant Control Plane, as host and port pairs with no credentials:
+	// the schema and user are reported in the setup status.
+	Endpoints   []string                   `json:"endpoints,omitempty"`
+	Config      DataStoreConfigStatus      `json:"config,omitempty"`
+	Setup       DataStoreSetupStatus       `json:"setup,omitempty"`
+	Certificate DataStoreCertificateStatus `json:"certificate,omitempty"`
+	// Tracks the latest migration of the data to a different DataStore, triggered by the change of the DataStore reference.
+	Migration *DataStoreMigrationStatus `json:"migration,omitempty"`
+	// The health of each member of the DataStore, refreshed periodically: it's reported by the etcd driver only.
+	Health *DataStoreHealthStatus `json:"health,omitempty"`
+}
+
+// DataStoreHealthStatus defines the observed health of the DataStore members.
+type DataStoreHealthStatus struct {
+	Endpoints []DataStoreEndpointHealth `json:"endpoints,omitempty"`
+	// The time of the latest health probe.
+	LastProbeTime metav1.Time `json:"lastProbeTime,omitempty"`
+}
+
+// DataStoreEndpointHealth is the health of a single DataStore member, reported by its endpoint.
+type DataStoreEndpointHealth struct {
+	Endpoint string `json:"endpoint"`
+	Healthy  bool   `json:"healthy"`
+	// The reason the member is unhealthy, such as the probe failure, or the raised alarms.
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Paused;Copying;Switching;Completed
+type DataStoreMigrationPhase string
+
+const (
+	// DataStoreMigrationPaused is reported when the migration is held by means of the
+	// kamaji.clastix.io/pause-datastore-migration annotation, before copying the data, or before switching to the target.
+	DataStoreMigrationPaused DataStoreMigrationPhase = "Paused"
+	// DataStoreMigrationCopying is reported when the data is being copied to the target DataStore by the migration Job.
+	DataStoreMigrationCopying DataStoreMigrationPhase = "Copying"
+	// DataStoreMigrationSwitching is reported when the data has been copied, and the Tenant Control Plane is being
+	// switched to the target DataStore.
+	DataStoreMigrationSwitching DataStoreMigrationPhase = "Switching"
+	// DataStoreMigrationCompleted is reported when the Tenant Control Plane is using the target DataStore,
+	// and the source data has been cleaned up, if requested.
+	DataStoreMigrationCompleted DataStoreMigrationPhase = "Completed"
+)
+
+// DataStoreMigrationStatus defines the observed state of the migration across DataStores.
+type DataStoreMigrationStatus struct {
+	// The DataStore the data is migrated from.
+	Source string `json:"source,omitempty"`
+	// The DataStore the data is migrated to.
+	Target string                  `json:"target,omitempty"`
+	Phase  DataStoreMigrationPhase `json:"phase,omitempty"`
+	// The time of the latest phase transition.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // KubeconfigStatus contains information about the generated kubeconfig.
@@ -171,8 +277,56 @@ type TenantControlPlaneStatus struct {
 	ControlPlaneEndpoint string `json:"controlPlaneEndpoint,omitempty"`
 	// Addons contains the status of the different Addons
 	Addons AddonsStatus `json:"addons,omitempty"`
+	// Conditions contains the latest observations of the Tenant Control Plane state.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+const (
+	// DataStoreAvailableCondition reports if the DataStore used by the Tenant Control Plane is reachable:
+	// it's set to false when the DataStore circuit breaker is open, when it's over its size limit,
+	// or when it's refusing the Kamaji credentials, as well as when its configuration is not valid.
+	DataStoreAvailableCondition = "DataStoreAvailable"
+
+	DataStoreAvailableReason          = "Available"
+	DataStoreCircuitBreakerOpenReason = "CircuitBreakerOpen"
+	DataStoreQuotaExceededReason      = "QuotaExceeded"
+	DataStorePermissionDeniedReason   = "PermissionDenied"
+	DataStoreInvalidConfigReason      = "InvalidConfiguration"
+
+	// DataStoreEncodingCondition reports if the character set and the collation of the tenant schema match the ones
+	// Kamaji creates the schemas with: it's set to false for the schemas created out of band with a different encoding,
+	// which are never re-created, thus requiring a manual intervention.
+	DataStoreEncodingCondition = "DataStoreEncoding"
+
+	DataStoreEncodingMatchReason    = "Match"
+	DataStoreEncodingMismatchReason = "Mismatch"
+
+	// CoreDNSAuthorizedCondition and KubeProxyAuthorizedCondition report if the tenant API server is accepting
+	// the addon resources applied by Kamaji: they're set to false when refused, such as upon a misconfigured RBAC.
+	CoreDNSAuthorizedCondition   = "CoreDNSAuthorized"
+	KubeProxyAuthorizedCondition = "KubeProxyAuthorized"
+
+	AddonAuthorizedReason = "Authorized"
+	AddonForbiddenReason  = "Forbidden"
+
+	// CoreDNSFailedCondition and KubeProxyFailedCondition report if the addon has exhausted the retry budget set by
+	// its maxAttempts field: they're set to true with the last apply error, and set back to false upon the next success.
+	CoreDNSFailedCondition   = "CoreDNSFailed"
+	KubeProxyFailedCondition = "KubeProxyFailed"
+
+	AddonAppliedReason              = "Applied"
+	AddonRetryBudgetExhaustedReason = "RetryBudgetExhausted"
+
+	// PausedCondition reports if the reconciliation of the datastore and addon resources is paused
+	// by means of the kamaji.clastix.io/paused annotation.
+	PausedCondition = "Paused"
+
+	PausedReason    = "Paused"
+	ReconcileReason = "Reconciling"
+)
+
 // KubernetesStatus defines the status of the resources deployed in the management cluster,
 // such as Deployment and Service.
 type KubernetesStatus struct {
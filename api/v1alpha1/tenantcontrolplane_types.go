@@ -7,6 +7,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // NetworkProfileSpec defines the desired state of NetworkProfile.
@@ -31,6 +32,10 @@ type NetworkProfileSpec struct {
 	PodCIDR string `json:"podCidr,omitempty"`
 	// +kubebuilder:default={"10.96.0.10"}
 	DNSServiceIPs []string `json:"dnsServiceIPs,omitempty"`
+	// The DNS domain of the tenant cluster, used by CoreDNS and by the kubelet configuration.
+	// +kubebuilder:default="cluster.local"
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	ClusterDomain string `json:"clusterDomain,omitempty"`
 }
 
 // +kubebuilder:validation:Enum=Hostname;InternalIP;ExternalIP;InternalDNS;ExternalDNS
@@ -177,6 +182,147 @@ type ServiceSpec struct {
 // AddonSpec defines the spec for every addon.
 type AddonSpec struct {
 	ImageOverrideTrait `json:",inline"`
+	// Patches are applied in order to the addon workload rendered by kubeadm, the DaemonSet for kube-proxy
+	// and the Deployment for CoreDNS, before being applied to the tenant cluster:
+	// they allow tweaks not exposed as first-class fields, such as additional environment variables.
+	// The patches are applied at every reconciliation, thus JSON patches must be idempotent.
+	Patches []AddonPatch `json:"patches,omitempty"`
+	// MaxAttempts is the number of consecutive failed applies of the addon resources after which the addon is marked
+	// as failed, by means of the CoreDNSFailed or KubeProxyFailed condition, and its reconciliation is stopped
+	// until the Tenant Control Plane spec changes: if not set, the failed applies are retried indefinitely.
+	// +kubebuilder:validation:Minimum=1
+	MaxAttempts *int32 `json:"maxAttempts,omitempty"`
+}
+
+// KubeProxyAddonSpec defines the spec for the kube-proxy addon.
+type KubeProxyAddonSpec struct {
+	AddonSpec `json:",inline"`
+	// MetricsBindAddress is the address the kube-proxy metrics server is listening on, such as 127.0.0.1:10249 or [::1]:10249:
+	// if not set, the kubeadm default is used.
+	// +kubebuilder:validation:Pattern=`^(\[[0-9A-Fa-f:.]+\]|[^\s:\[\]]*):[0-9]+$`
+	MetricsBindAddress string `json:"metricsBindAddress,omitempty"`
+	// Conntrack tunes the connection tracking table of the nodes, such as for the tenants with a high number of
+	// connections: the kube-proxy Pods are rolled out upon its changes.
+	Conntrack *KubeProxyConntrackSpec `json:"conntrack,omitempty"`
+}
+
+// KubeProxyConntrackSpec defines the conntrack settings of kube-proxy: the unset fields are left to the kube-proxy defaults.
+type KubeProxyConntrackSpec struct {
+	// MaxPerCore is the maximum number of NAT connections to track per CPU core:
+	// 0 leaves the limit as-is, ignoring min.
+	// +kubebuilder:validation:Minimum=0
+	MaxPerCore *int32 `json:"maxPerCore,omitempty"`
+	// Min is the minimum number of connection tracking records to allocate, regardless of maxPerCore.
+	// +kubebuilder:validation:Minimum=0
+	Min *int32 `json:"min,omitempty"`
+	// TCPEstablishedTimeout is how long an idle TCP connection is kept open, such as 24h.
+	TCPEstablishedTimeout *metav1.Duration `json:"tcpEstablishedTimeout,omitempty"`
+}
+
+// CoreDNSAddonSpec defines the spec for the CoreDNS addon.
+type CoreDNSAddonSpec struct {
+	AddonSpec `json:",inline"`
+	// Cache configures the cache plugin of the generated Corefile:
+	// if not set, the kubeadm default is used, caching the records up to 30 seconds.
+	Cache *CoreDNSCacheSpec `json:"cache,omitempty"`
+	// Health configures the health plugin, serving the liveness probe: if not set, it's listening on port 8080.
+	Health *CoreDNSPluginSpec `json:"health,omitempty"`
+	// Ready configures the ready plugin, serving the readiness probe: if not set, it's listening on port 8181.
+	Ready *CoreDNSPluginSpec `json:"ready,omitempty"`
+	// Metrics configures the prometheus plugin, exposing the metrics through the kube-dns Service:
+	// if not set, it's listening on port 9153.
+	Metrics *CoreDNSPluginSpec `json:"metrics,omitempty"`
+	// Log enables the log plugin of the generated Corefile, logging every served query:
+	// it's meant for troubleshooting, since the log volume grows along with the queries.
+	// +kubebuilder:default=false
+	Log bool `json:"log,omitempty"`
+	// PodDisruptionBudget creates a PodDisruptionBudget for the CoreDNS Pods, keeping them available
+	// upon the voluntary disruptions, such as the node drains: if not set, no PodDisruptionBudget is created.
+	PodDisruptionBudget *CoreDNSPodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+	// ServiceAccount customizes the ServiceAccount the CoreDNS Pods are running as, such as for the workload identity:
+	// if not set, the coredns one is used.
+	ServiceAccount *CoreDNSServiceAccountSpec `json:"serviceAccount,omitempty"`
+	// Autoscaler deploys the cluster-proportional-autoscaler, scaling the CoreDNS replicas along with the cores
+	// and the nodes of the tenant cluster: if not set, no autoscaler is deployed, and it's removed if existing.
+	Autoscaler *CoreDNSAutoscalerSpec `json:"autoscaler,omitempty"`
+}
+
+type CoreDNSAutoscalerSpec struct {
+	// Image of the cluster-proportional-autoscaler: if not set, registry.k8s.io/cpa/cluster-proportional-autoscaler:v1.8.8 is used.
+	Image string `json:"image,omitempty"`
+	// CoresPerReplica is the number of the tenant cluster cores served by each CoreDNS replica.
+	// +kubebuilder:default=256
+	// +kubebuilder:validation:Minimum=1
+	CoresPerReplica int32 `json:"coresPerReplica,omitempty"`
+	// NodesPerReplica is the number of the tenant cluster nodes served by each CoreDNS replica.
+	// +kubebuilder:default=16
+	// +kubebuilder:validation:Minimum=1
+	NodesPerReplica int32 `json:"nodesPerReplica,omitempty"`
+	// MinReplicas is the lower bound of the CoreDNS replicas.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the upper bound of the CoreDNS replicas: if not set, the replicas are not capped.
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// PreventSinglePointFailure runs at least two CoreDNS replicas when the tenant cluster has more than one node.
+	// +kubebuilder:default=true
+	PreventSinglePointFailure *bool `json:"preventSinglePointFailure,omitempty"`
+}
+
+type CoreDNSServiceAccountSpec struct {
+	// Name of the ServiceAccount in the kube-system namespace, created by Kamaji if not existing.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	// +kubebuilder:validation:MaxLength=253
+	Name string `json:"name,omitempty"`
+	// Annotations added to the ServiceAccount, such as the eks.amazonaws.com/role-arn one:
+	// the CoreDNS Pods are rolled out upon their changes.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type CoreDNSPodDisruptionBudgetSpec struct {
+	// MinAvailable is the number, or the percentage, of the CoreDNS Pods that must be still available
+	// upon the voluntary disruptions.
+	// +kubebuilder:default=1
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+}
+
+type CoreDNSPluginSpec struct {
+	// Enabled toggles the plugin, along with the Deployment probe, or the Service port, relying on it.
+	// +kubebuilder:default=true
+	Enabled *bool `json:"enabled,omitempty"`
+	// Port the plugin is listening on, which must not collide with the DNS one, and the other plugins.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+}
+
+type CoreDNSCacheSpec struct {
+	// Enabled toggles the cache plugin: disabling it prevents serving stale records,
+	// such as upon the failover of the stub domains.
+	// +kubebuilder:default=true
+	Enabled *bool `json:"enabled,omitempty"`
+	// TTL is the maximum number of seconds the records are cached for.
+	// +kubebuilder:validation:Minimum=1
+	TTL *int32 `json:"ttl,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=StrategicMerge;JSON
+
+type AddonPatchType string
+
+const (
+	StrategicMergeAddonPatchType AddonPatchType = "StrategicMerge"
+	JSONAddonPatchType           AddonPatchType = "JSON"
+)
+
+type AddonPatch struct {
+	// Type of the patch, a strategic merge patch or a JSON patch (RFC 6902).
+	// +kubebuilder:default=StrategicMerge
+	Type AddonPatchType `json:"type,omitempty"`
+	// Patch is the content of the patch, expressed in either JSON or YAML.
+	// +kubebuilder:validation:MinLength=1
+	Patch string `json:"patch"`
 }
 
 type ImageOverrideTrait struct {
@@ -223,23 +369,40 @@ type KonnectivitySpec struct {
 	KonnectivityAgentSpec KonnectivityAgentSpec `json:"agent,omitempty"`
 }
 
+// +kubebuilder:validation:Enum=Minimal;Full
+
+type AddonsProfile string
+
+var (
+	// MinimalAddonsProfile enables no addons, such as for the tenants bringing their own networking and DNS.
+	MinimalAddonsProfile AddonsProfile = "Minimal"
+	// FullAddonsProfile enables CoreDNS, kube-proxy, and Konnectivity with their default configuration.
+	FullAddonsProfile AddonsProfile = "Full"
+)
+
 // AddonsSpec defines the enabled addons and their features.
 type AddonsSpec struct {
+	// Selects a named set of addons, enabling the ones not specified with their default configuration:
+	// the specified ones take precedence, allowing the per-addon overrides. The profile is resolved upon each
+	// reconciliation, thus the addons it enables are removed when switching to the Minimal one; an addon of the
+	// Full profile can't be disabled singularly, requiring the Minimal one along with the wanted addons.
+	Profile AddonsProfile `json:"profile,omitempty"`
 	// Enables the DNS addon in the Tenant Cluster.
 	// The registry and the tag are configurable, the image is hard-coded to `coredns`.
-	CoreDNS *AddonSpec `json:"coreDNS,omitempty"`
+	CoreDNS *CoreDNSAddonSpec `json:"coreDNS,omitempty"`
 	// Enables the Konnectivity addon in the Tenant Cluster, required if the worker nodes are in a different network.
 	Konnectivity *KonnectivitySpec `json:"konnectivity,omitempty"`
 	// Enables the kube-proxy addon in the Tenant Cluster.
 	// The registry and the tag are configurable, the image is hard-coded to `kube-proxy`.
-	KubeProxy *AddonSpec `json:"kubeProxy,omitempty"`
+	KubeProxy *KubeProxyAddonSpec `json:"kubeProxy,omitempty"`
 }
 
 // TenantControlPlaneSpec defines the desired state of TenantControlPlane.
 type TenantControlPlaneSpec struct {
 	// DataStore allows to specify a DataStore that should be used to store the Kubernetes data for the given Tenant Control Plane.
 	// This parameter is optional and acts as an override over the default one which is used by the Kamaji Operator.
-	// Migration from a different DataStore to another one is not yet supported and the reconciliation will be blocked.
+	// Changing it migrates the data to the new DataStore, which must use the same driver: the migration phases
+	// are tracked in the storage status.
 	DataStore    string       `json:"dataStore,omitempty"`
 	ControlPlane ControlPlane `json:"controlPlane"`
 	// Kubernetes specification for tenant control plane
@@ -0,0 +1,77 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/clastix/kamaji/cmd/utils"
+)
+
+func NewCmd(scheme *runtime.Scheme) *cobra.Command {
+	// CLI flags
+	var (
+		tenantControlPlane string
+		statement          string
+		auditLogPath       string
+		timeout            time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:          "datastore-exec",
+		Short:        "Execute a one-off statement against the schema of a TenantControlPlane, recording it to the audit log",
+		SilenceUsage: true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(strings.TrimSpace(statement)) == 0 {
+				return fmt.Errorf("the statement cannot be empty")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancelFn := context.WithTimeout(context.Background(), timeout)
+			defer cancelFn()
+
+			log := ctrl.Log
+
+			if err := utils.SetFileAuditSink(auditLogPath); err != nil {
+				return err
+			}
+
+			tenantDataStore, err := utils.ConnectTenantDataStore(ctx, scheme, tenantControlPlane)
+			if err != nil {
+				return err
+			}
+			defer tenantDataStore.Connection.Close()
+
+			log.Info("executing the statement", "tenantControlPlane", tenantControlPlane, "dataStore", tenantDataStore.DataStore.GetName(), "schema", tenantDataStore.Schema())
+
+			if err = tenantDataStore.Connection.Exec(ctx, tenantDataStore.Schema(), statement); err != nil {
+				return err
+			}
+
+			log.Info("statement executed")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&tenantControlPlane, "tenant-control-plane", "", "Namespaced-name of the TenantControlPlane whose schema the statement is executed against (e.g.: default/test)")
+	cmd.Flags().StringVar(&statement, "statement", "", "Statement to execute with the DataStore credentials used by Kamaji")
+	cmd.Flags().StringVar(&auditLogPath, "audit-log-path", "", "Path of the file where the executed statement is recorded, with redacted passwords")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "Amount of time for the context timeout")
+
+	_ = cmd.MarkFlagRequired("tenant-control-plane")
+	_ = cmd.MarkFlagRequired("statement")
+	_ = cmd.MarkFlagRequired("audit-log-path")
+
+	return cmd
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package grants
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/pkg/datastore"
+)
+
+func NewCmd(scheme *runtime.Scheme) *cobra.Command {
+	// CLI flags
+	var (
+		dataStore string
+		user      string
+		timeout   time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:          "datastore-grants",
+		Short:        "Print the privileges held by a DataStore user across all the schemas",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancelFn := context.WithTimeout(context.Background(), timeout)
+			defer cancelFn()
+
+			client, err := ctrlclient.New(ctrl.GetConfigOrDie(), ctrlclient.Options{
+				Scheme: scheme,
+			})
+			if err != nil {
+				return err
+			}
+
+			ds := &kamajiv1alpha1.DataStore{}
+			if err = client.Get(ctx, types.NamespacedName{Name: dataStore}, ds); err != nil {
+				return err
+			}
+
+			connection, err := datastore.NewStorageConnection(ctx, client, *ds)
+			if err != nil {
+				return err
+			}
+			defer connection.Close()
+
+			grants, err := connection.ListGrants(ctx, user)
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+
+			return encoder.Encode(grants)
+		},
+	}
+
+	cmd.Flags().StringVar(&dataStore, "datastore", "", "Name of the DataStore to inspect")
+	cmd.Flags().StringVar(&user, "user", "", "Name of the DataStore user whose privileges are listed, such as the one of a TenantControlPlane")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "Amount of time for the context timeout")
+
+	_ = cmd.MarkFlagRequired("datastore")
+	_ = cmd.MarkFlagRequired("user")
+
+	return cmd
+}
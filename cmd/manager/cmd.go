@@ -9,6 +9,7 @@ import (
 	"io"
 	"os"
 	goRuntime "runtime"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -27,30 +28,51 @@ import (
 	"github.com/clastix/kamaji/controllers/soot"
 	"github.com/clastix/kamaji/internal"
 	"github.com/clastix/kamaji/internal/builders/controlplane"
-	datastoreutils "github.com/clastix/kamaji/internal/datastore/utils"
+	ds "github.com/clastix/kamaji/internal/resources/datastore"
+	"github.com/clastix/kamaji/internal/utilities"
 	"github.com/clastix/kamaji/internal/webhook"
 	"github.com/clastix/kamaji/internal/webhook/handlers"
 	"github.com/clastix/kamaji/internal/webhook/routes"
+	kamajidatastore "github.com/clastix/kamaji/pkg/datastore"
+	datastoreutils "github.com/clastix/kamaji/pkg/datastore/utils"
 )
 
 //nolint:maintidx
 func NewCmd(scheme *runtime.Scheme) *cobra.Command {
 	// CLI flags
 	var (
-		metricsBindAddress         string
-		healthProbeBindAddress     string
-		leaderElect                bool
-		tmpDirectory               string
-		kineImage                  string
-		controllerReconcileTimeout time.Duration
-		cacheResyncPeriod          time.Duration
-		datastore                  string
-		managerNamespace           string
-		managerServiceAccountName  string
-		managerServiceName         string
-		webhookCABundle            []byte
-		migrateJobImage            string
-		maxConcurrentReconciles    int
+		metricsBindAddress          string
+		healthProbeBindAddress      string
+		leaderElect                 bool
+		tmpDirectory                string
+		kineImage                   string
+		controllerReconcileTimeout  time.Duration
+		cacheResyncPeriod           time.Duration
+		datastore                   string
+		managerNamespace            string
+		managerServiceAccountName   string
+		managerPodName              string
+		managerServiceName          string
+		webhookCABundle             []byte
+		migrateJobImage             string
+		maxConcurrentReconciles     int
+		tenantClientQPS             float32
+		tenantClientBurst           int
+		tenantClientEndpoint        string
+		datastoreAuditLogPath       string
+		datastoreApplicationName    string
+		circuitBreakerThreshold     int
+		circuitBreakerCoolDown      time.Duration
+		datastoreWarmUp             bool
+		datastoreExistingUserPolicy string
+		datastoreCertRenewalWindow  time.Duration
+		datastoreDriftCheckInterval time.Duration
+		datastoreHealthInterval     time.Duration
+		datastorePasswordLength     int
+		datastorePasswordClasses    []string
+		datastorePasswordGenerator  ds.PasswordGenerator
+		debugReconcileTokenPath     string
+		debugReconcileToken         string
 
 		webhookCAPath string
 	)
@@ -83,6 +105,72 @@ func NewCmd(scheme *runtime.Scheme) *cobra.Command {
 				return fmt.Errorf("the controller reconcile timeout must be greater than zero")
 			}
 
+			if tenantClientQPS <= 0 || tenantClientBurst <= 0 {
+				return fmt.Errorf("the tenant client QPS and burst must be greater than zero")
+			}
+
+			utilities.SetTenantClientRateLimit(tenantClientQPS, tenantClientBurst)
+
+			if err = utilities.SetTenantClientEndpoint(utilities.TenantClientEndpoint(tenantClientEndpoint)); err != nil {
+				return err
+			}
+
+			if circuitBreakerThreshold < 0 || circuitBreakerCoolDown <= 0 {
+				return fmt.Errorf("the datastore circuit breaker threshold must be positive, and the cool-down greater than zero")
+			}
+
+			switch ds.ExistingUserPolicy(datastoreExistingUserPolicy) {
+			case ds.AdoptExistingUserPolicy, ds.FailExistingUserPolicy:
+			default:
+				return fmt.Errorf("the datastore existing user policy must be one of %s, %s", ds.AdoptExistingUserPolicy, ds.FailExistingUserPolicy)
+			}
+
+			if datastoreCertRenewalWindow <= 0 {
+				return fmt.Errorf("the datastore certificate renewal window must be greater than zero")
+			}
+
+			if datastoreDriftCheckInterval <= 0 {
+				return fmt.Errorf("the datastore drift check interval must be greater than zero")
+			}
+
+			if datastoreHealthInterval < 0 {
+				return fmt.Errorf("the datastore health check interval cannot be negative")
+			}
+
+			if datastorePasswordLength < 0 {
+				return fmt.Errorf("the datastore password length cannot be negative")
+			}
+
+			if datastorePasswordGenerator, err = ds.NewPasswordGenerator(datastorePasswordLength, datastorePasswordClasses); err != nil {
+				return fmt.Errorf("invalid datastore password policy: %w", err)
+			}
+
+			if len(datastoreAuditLogPath) > 0 {
+				sink, sinkErr := kamajidatastore.NewFileAuditSink(datastoreAuditLogPath)
+				if sinkErr != nil {
+					return fmt.Errorf("unable to open the datastore audit log file: %w", sinkErr)
+				}
+
+				kamajidatastore.SetAuditSink(sink)
+			}
+
+			if len(strings.TrimSpace(datastoreApplicationName)) == 0 {
+				return fmt.Errorf("the datastore application name must not be empty")
+			}
+
+			kamajidatastore.SetApplicationName(datastoreApplicationName, managerPodName)
+
+			if len(debugReconcileTokenPath) > 0 {
+				token, tokenErr := os.ReadFile(debugReconcileTokenPath)
+				if tokenErr != nil {
+					return fmt.Errorf("unable to read the debug reconcile token: %w", tokenErr)
+				}
+
+				if debugReconcileToken = strings.TrimSpace(string(token)); len(debugReconcileToken) == 0 {
+					return fmt.Errorf("the debug reconcile token must not be empty")
+				}
+			}
+
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -131,13 +219,23 @@ func NewCmd(scheme *runtime.Scheme) *cobra.Command {
 					KineContainerImage:   kineImage,
 					TmpBaseDirectory:     tmpDirectory,
 				},
-				CertificateChan:         certChannel,
-				TriggerChan:             tcpChannel,
-				KamajiNamespace:         managerNamespace,
-				KamajiServiceAccount:    managerServiceAccountName,
-				KamajiService:           managerServiceName,
-				KamajiMigrateImage:      migrateJobImage,
-				MaxConcurrentReconciles: maxConcurrentReconciles,
+				CertificateChan:                   certChannel,
+				TriggerChan:                       tcpChannel,
+				KamajiNamespace:                   managerNamespace,
+				KamajiServiceAccount:              managerServiceAccountName,
+				KamajiService:                     managerServiceName,
+				KamajiMigrateImage:                migrateJobImage,
+				KamajiPodName:                     managerPodName,
+				MaxConcurrentReconciles:           maxConcurrentReconciles,
+				DataStoreExistingUserPolicy:       ds.ExistingUserPolicy(datastoreExistingUserPolicy),
+				DataStoreCertificateRenewalWindow: datastoreCertRenewalWindow,
+				DataStoreDriftCheckInterval:       datastoreDriftCheckInterval,
+				DataStoreHealthCheckInterval:      datastoreHealthInterval,
+				DataStorePasswordGenerator:        datastorePasswordGenerator,
+				DataStoreCircuitBreaker: &kamajidatastore.CircuitBreaker{
+					Threshold: circuitBreakerThreshold,
+					CoolDown:  circuitBreakerCoolDown,
+				},
 			}
 
 			if err = reconciler.SetupWithManager(mgr); err != nil {
@@ -146,7 +244,23 @@ func NewCmd(scheme *runtime.Scheme) *cobra.Command {
 				return err
 			}
 
-			if err = (&controllers.CertificateLifecycle{Channel: certChannel}).SetupWithManager(mgr); err != nil {
+			if len(debugReconcileToken) > 0 {
+				if err = mgr.AddMetricsExtraHandler(controllers.DebugReconcilePath, &controllers.DebugReconcile{Reconciler: reconciler, Token: debugReconcileToken}); err != nil {
+					setupLog.Error(err, "unable to set up the debug reconcile endpoint")
+
+					return err
+				}
+			}
+
+			if datastoreWarmUp {
+				if err = mgr.Add(&controllers.DataStoreWarmUp{Reconciler: reconciler, Timeout: ds.DefaultReadyTimeout}); err != nil {
+					setupLog.Error(err, "unable to set up the DataStore warm-up")
+
+					return err
+				}
+			}
+
+			if err = (&controllers.CertificateLifecycle{Channel: certChannel, DataStoreCertificateRenewalWindow: datastoreCertRenewalWindow}).SetupWithManager(mgr); err != nil {
 				setupLog.Error(err, "unable to create controller", "controller", "CertificateLifecycle")
 
 				return err
@@ -175,6 +289,7 @@ func NewCmd(scheme *runtime.Scheme) *cobra.Command {
 					handlers.TenantControlPlaneName{},
 					handlers.TenantControlPlaneVersion{},
 					handlers.TenantControlPlaneKubeletAddresses{},
+					handlers.TenantControlPlaneAddons{},
 					handlers.TenantControlPlaneDataStore{Client: mgr.GetClient()},
 					handlers.TenantControlPlaneDeployment{
 						Client: mgr.GetClient(),
@@ -251,10 +366,26 @@ func NewCmd(scheme *runtime.Scheme) *cobra.Command {
 	cmd.Flags().StringVar(&migrateJobImage, "migrate-image", fmt.Sprintf("clastix/kamaji:v%s", internal.GitTag), "Specify the container image to launch when a TenantControlPlane is migrated to a new datastore.")
 	cmd.Flags().IntVar(&maxConcurrentReconciles, "max-concurrent-tcp-reconciles", 1, "Specify the number of workers for the Tenant Control Plane controller (beware of CPU consumption)")
 	cmd.Flags().StringVar(&managerNamespace, "pod-namespace", os.Getenv("POD_NAMESPACE"), "The Kubernetes Namespace on which the Operator is running in, required for the TenantControlPlane migration jobs.")
+	cmd.Flags().StringVar(&managerPodName, "pod-name", os.Getenv("POD_NAME"), "The Kubernetes Pod name of the Operator instance, recorded in the TenantControlPlane status upon the changes performed against the DataStore.")
 	cmd.Flags().StringVar(&managerServiceName, "webhook-service-name", "kamaji-webhook-service", "The Kamaji webhook server Service name which is used to get validation webhooks, required for the TenantControlPlane migration jobs.")
 	cmd.Flags().StringVar(&managerServiceAccountName, "serviceaccount-name", os.Getenv("SERVICE_ACCOUNT"), "The Kubernetes Namespace on which the Operator is running in, required for the TenantControlPlane migration jobs.")
 	cmd.Flags().StringVar(&webhookCAPath, "webhook-ca-path", "/tmp/k8s-webhook-server/serving-certs/ca.crt", "Path to the Manager webhook server CA, required for the TenantControlPlane migration jobs.")
 	cmd.Flags().DurationVar(&controllerReconcileTimeout, "controller-reconcile-timeout", 30*time.Second, "The reconciliation request timeout before the controller withdraw the external resource calls, such as dealing with the Datastore, or the Tenant Control Plane API endpoint.")
+	cmd.Flags().Float32Var(&tenantClientQPS, "tenant-client-qps", utilities.DefaultTenantClientQPS, "The maximum queries per second of the clients interacting with the Tenant Control Plane API servers, such as for the addons reconciliation.")
+	cmd.Flags().IntVar(&tenantClientBurst, "tenant-client-burst", utilities.DefaultTenantClientBurst, "The maximum burst for throttle of the clients interacting with the Tenant Control Plane API servers.")
+	cmd.Flags().StringVar(&tenantClientEndpoint, "tenant-client-endpoint", string(utilities.ServiceTenantClientEndpoint), "The Tenant Control Plane API server endpoint targeted by the clients, such as for the addons reconciliation: Service for the in-cluster Service, Advertised for the advertised endpoint, such as the Load Balancer one.")
+	cmd.Flags().IntVar(&circuitBreakerThreshold, "datastore-circuit-breaker-threshold", 5, "The number of consecutive failures against a DataStore pausing the reconciliation of the Tenant Control Planes using it: zero disables the circuit breaker.")
+	cmd.Flags().DurationVar(&circuitBreakerCoolDown, "datastore-circuit-breaker-cooldown", 30*time.Second, "The amount of time the reconciliations are paused once the DataStore circuit breaker is open, before probing the DataStore for its recovery.")
+	cmd.Flags().BoolVar(&datastoreWarmUp, "datastore-warm-up", false, "Connect in the background upon the startup to each DataStore used by the Tenant Control Planes, validating it ahead of their first reconciliations: the unreachable ones are logged, and recorded by the circuit breaker, with no impact on the startup.")
+	cmd.Flags().StringVar(&datastoreExistingUserPolicy, "datastore-existing-user-policy", string(ds.FailExistingUserPolicy), "How to handle the DataStore users already existing although not provisioned by Kamaji, such as the ones created out of band: Adopt takes them over setting the managed password, Fail refuses to use them.")
+	cmd.Flags().DurationVar(&datastoreCertRenewalWindow, "datastore-certificate-renewal-window", 24*time.Hour, "The amount of time before the expiration the etcd client certificates of the Tenant Control Planes are reissued, rolling out their Deployment.")
+	cmd.Flags().DurationVar(&datastoreDriftCheckInterval, "datastore-drift-check-interval", ds.DefaultDriftCheckInterval, "The interval after which the next reconciliation verifies the DataStore setup of the Tenant Control Planes, catching the external changes such as the removal of their user: the reconciliations in between skip the round-trips against the DataStore.")
+	cmd.Flags().DurationVar(&datastoreHealthInterval, "datastore-health-check-interval", ds.DefaultHealthCheckInterval, "The interval after which the health of each etcd DataStore member is probed again, and reported in the storage status of the Tenant Control Planes using it: zero disables the probes.")
+	cmd.Flags().IntVar(&datastorePasswordLength, "datastore-password-length", 0, "The length of the passwords generated for the DataStore users of the Tenant Control Planes, when not provided: zero generates random UUIDs.")
+	cmd.Flags().StringSliceVar(&datastorePasswordClasses, "datastore-password-classes", nil, "The character classes the generated DataStore user passwords must contain at least a character of, among lower, upper, digit, and symbol: when not specified, lower, upper, and digit are used. It requires the password length.")
+	cmd.Flags().StringVar(&datastoreAuditLogPath, "datastore-audit-log-path", "", "Path of the file where the DDL statements performed against the SQL DataStores are recorded, with redacted passwords: if empty, the audit is disabled.")
+	cmd.Flags().StringVar(&datastoreApplicationName, "datastore-application-name", kamajidatastore.DefaultApplicationName, "The base label of the connections opened against the SQL DataStores, followed by the Pod name of the Operator instance, and the Tenant Control Plane being reconciled: it's set as application_name with PostgreSQL, and as the program_name connection attribute with MySQL.")
+	cmd.Flags().StringVar(&debugReconcileTokenPath, "debug-reconcile-token-path", "", "Path of the file holding the bearer token authorizing the debug endpoint served along with the metrics on /debug/reconcile, which reconciles on demand a single resource of a Tenant Control Plane: if empty, the endpoint is disabled.")
 	cmd.Flags().DurationVar(&cacheResyncPeriod, "cache-resync-period", 10*time.Hour, "The controller-runtime.Manager cache resync period.")
 
 	cobra.OnInitialize(func() {
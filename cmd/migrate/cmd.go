@@ -16,7 +16,7 @@ import (
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
-	"github.com/clastix/kamaji/internal/datastore"
+	"github.com/clastix/kamaji/pkg/datastore"
 )
 
 func NewCmd(scheme *runtime.Scheme) *cobra.Command {
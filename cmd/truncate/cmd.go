@@ -0,0 +1,76 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package truncate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/clastix/kamaji/cmd/utils"
+)
+
+func NewCmd(scheme *runtime.Scheme) *cobra.Command {
+	// CLI flags
+	var (
+		tenantControlPlane string
+		confirm            string
+		auditLogPath       string
+		timeout            time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:          "datastore-truncate",
+		Short:        "Drop the data of a TenantControlPlane, preserving its DataStore schema, user, and privileges, recording it to the audit log",
+		SilenceUsage: true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if confirm != tenantControlPlane {
+				return fmt.Errorf("the truncation must be confirmed by repeating the tenant control plane namespaced name, expected %s, got %s", tenantControlPlane, confirm)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancelFn := context.WithTimeout(context.Background(), timeout)
+			defer cancelFn()
+
+			log := ctrl.Log
+
+			if err := utils.SetFileAuditSink(auditLogPath); err != nil {
+				return err
+			}
+
+			tenantDataStore, err := utils.ConnectTenantDataStore(ctx, scheme, tenantControlPlane)
+			if err != nil {
+				return err
+			}
+			defer tenantDataStore.Connection.Close()
+
+			log.Info("truncating the data", "tenantControlPlane", tenantControlPlane, "dataStore", tenantDataStore.DataStore.GetName(), "schema", tenantDataStore.Schema())
+
+			if err = tenantDataStore.Connection.TruncateData(ctx, tenantDataStore.Schema()); err != nil {
+				return err
+			}
+
+			log.Info("data truncated, the TenantControlPlane pods must be restarted to initialize the DataStore schema again")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&tenantControlPlane, "tenant-control-plane", "", "Namespaced-name of the TenantControlPlane whose data is dropped (e.g.: default/test)")
+	cmd.Flags().StringVar(&confirm, "confirm", "", "Namespaced-name of the TenantControlPlane again, confirming the truncation of its data")
+	cmd.Flags().StringVar(&auditLogPath, "audit-log-path", "", "Path of the file where the dropped objects are recorded")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "Amount of time for the context timeout")
+
+	_ = cmd.MarkFlagRequired("tenant-control-plane")
+	_ = cmd.MarkFlagRequired("confirm")
+	_ = cmd.MarkFlagRequired("audit-log-path")
+
+	return cmd
+}
@@ -0,0 +1,79 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/pkg/datastore"
+)
+
+// TenantDataStore is the DataStore schema provisioned for a TenantControlPlane, along with the connection to it.
+type TenantDataStore struct {
+	TenantControlPlane *kamajiv1alpha1.TenantControlPlane
+	DataStore          *kamajiv1alpha1.DataStore
+	Connection         datastore.Connection
+}
+
+// Schema returns the DataStore schema provisioned for the TenantControlPlane.
+func (t *TenantDataStore) Schema() string {
+	return t.TenantControlPlane.Status.Storage.Setup.Schema
+}
+
+// ConnectTenantDataStore retrieves the TenantControlPlane of the given <NAMESPACE>/NAME namespaced name, and connects
+// to the DataStore its schema has been provisioned to: the connection must be closed once done.
+func ConnectTenantDataStore(ctx context.Context, scheme *runtime.Scheme, tenantControlPlane string) (*TenantDataStore, error) {
+	client, err := ctrlclient.New(ctrl.GetConfigOrDie(), ctrlclient.Options{
+		Scheme: scheme,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(tenantControlPlane, string(types.Separator))
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("non well-formed namespaced name for the tenant control plane, expected <NAMESPACE>/NAME, got %s", tenantControlPlane)
+	}
+
+	tcp := &kamajiv1alpha1.TenantControlPlane{}
+	if err = client.Get(ctx, types.NamespacedName{Namespace: parts[0], Name: parts[1]}, tcp); err != nil {
+		return nil, err
+	}
+
+	if len(tcp.Status.Storage.DataStoreName) == 0 || len(tcp.Status.Storage.Setup.Schema) == 0 {
+		return nil, fmt.Errorf("the TenantControlPlane %s has no DataStore schema provisioned yet", tenantControlPlane)
+	}
+
+	ds := &kamajiv1alpha1.DataStore{}
+	if err = client.Get(ctx, types.NamespacedName{Name: tcp.Status.Storage.DataStoreName}, ds); err != nil {
+		return nil, err
+	}
+
+	connection, err := datastore.NewStorageConnection(ctx, client, *ds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TenantDataStore{TenantControlPlane: tcp, DataStore: ds, Connection: connection}, nil
+}
+
+// SetFileAuditSink records the statements performed against the DataStores to the file at the given path.
+func SetFileAuditSink(path string) error {
+	sink, err := datastore.NewFileAuditSink(path)
+	if err != nil {
+		return fmt.Errorf("unable to open the datastore audit log file: %w", err)
+	}
+
+	datastore.SetAuditSink(sink)
+
+	return nil
+}
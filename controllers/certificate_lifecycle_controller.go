@@ -26,12 +26,16 @@ import (
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
 	"github.com/clastix/kamaji/internal/constants"
 	"github.com/clastix/kamaji/internal/crypto"
+	ds "github.com/clastix/kamaji/internal/resources/datastore"
 	"github.com/clastix/kamaji/internal/utilities"
 )
 
 type CertificateLifecycle struct {
 	Channel CertificateChannel
-	client  client.Client
+	// DataStoreCertificateRenewalWindow is the amount of time before the expiration the etcd client certificates
+	// are reissued: the other certificates are rotated one day before their expiration.
+	DataStoreCertificateRenewalWindow time.Duration
+	client                            client.Client
 }
 
 func (s *CertificateLifecycle) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
@@ -58,10 +62,16 @@ func (s *CertificateLifecycle) Reconcile(ctx context.Context, request reconcile.
 	var crt *x509.Certificate
 	var err error
 
-	switch checkType {
-	case "x509":
+	window := 24 * time.Hour
+
+	switch {
+	case secret.GetLabels()[constants.ControlPlaneLabelResource] == (&ds.Certificate{}).GetName():
+		// The DataStore certificate Secret contains the CA certificate as well, thus the client one must be picked.
+		crt, err = crypto.ParseCertificateBytes(secret.Data["server.crt"])
+		window = s.DataStoreCertificateRenewalWindow
+	case checkType == "x509":
 		crt, err = s.extractCertificateFromBareSecret(secret)
-	case "kubeconfig":
+	case checkType == "kubeconfig":
 		crt, err = s.extractCertificateFromKubeconfig(secret)
 	default:
 		err = fmt.Errorf("unsupported strategy, %s", checkType)
@@ -73,7 +83,7 @@ func (s *CertificateLifecycle) Reconcile(ctx context.Context, request reconcile.
 		return reconcile.Result{}, nil
 	}
 
-	deadline := time.Now().AddDate(0, 0, 1)
+	deadline := time.Now().Add(window)
 
 	if deadline.After(crt.NotAfter) {
 		logger.Info("certificate near expiration, must be rotated")
@@ -5,8 +5,11 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -22,8 +25,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/pkg/datastore"
 )
 
+// dataStoreHealthcheckTimeout bounds the healthcheck, not to hold the DataStore reconciliation on unreachable instances.
+const dataStoreHealthcheckTimeout = 10 * time.Second
+
 type DataStore struct {
 	client client.Client
 	// TenantControlPlaneTrigger is the channel used to communicate across the controllers:
@@ -66,6 +73,9 @@ func (r *DataStore) Reconcile(ctx context.Context, request reconcile.Request) (r
 
 	ds.Status.UsedBy = tcpSets.List()
 
+	r.healthcheck(ctx, ds)
+	r.pruneRetainedSchemas(ctx, ds, tcpList.Items)
+
 	if err := r.client.Status().Update(ctx, ds); err != nil {
 		log.Error(err, "cannot update the status for the given instance")
 
@@ -81,6 +91,95 @@ func (r *DataStore) Reconcile(ctx context.Context, request reconcile.Request) (r
 	return reconcile.Result{}, nil
 }
 
+// healthcheck reports in the DataStore status if Kamaji is able to provision the Tenant Control Planes,
+// catching the misconfigured credentials upon the DataStore changes, rather than upon the first provisioning.
+func (r *DataStore) healthcheck(ctx context.Context, ds *kamajiv1alpha1.DataStore) {
+	ctx, cancelFn := context.WithTimeout(ctx, dataStoreHealthcheckTimeout)
+	defer cancelFn()
+
+	condition := metav1.Condition{
+		Type:               kamajiv1alpha1.DataStoreHealthyCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: ds.GetGeneration(),
+		Reason:             kamajiv1alpha1.DataStoreHealthyReason,
+		Message:            "the Kamaji user holds the privileges required to provision the Tenant Control Planes",
+	}
+
+	if err := r.checkHealth(ctx, *ds); err != nil {
+		log.FromContext(ctx).Error(err, "DataStore healthcheck failed")
+
+		condition.Status, condition.Message = metav1.ConditionFalse, err.Error()
+
+		switch datastore.ClassifyError(err) {
+		case datastore.PermissionErrorClass:
+			condition.Reason = kamajiv1alpha1.DataStorePermissionDeniedReason
+		case datastore.InvalidConfigErrorClass:
+			condition.Reason = kamajiv1alpha1.DataStoreInvalidConfigReason
+		default:
+			condition.Reason = kamajiv1alpha1.DataStoreUnreachableReason
+		}
+	}
+
+	meta.SetStatusCondition(&ds.Status.Conditions, condition)
+}
+
+// pruneRetainedSchemas removes from the DataStore status the retained schemas which have been either dropped by the
+// DataStore administrators, or adopted by a Tenant Control Plane: the failures are logged, keeping the schemas listed.
+func (r *DataStore) pruneRetainedSchemas(ctx context.Context, ds *kamajiv1alpha1.DataStore, tcps []kamajiv1alpha1.TenantControlPlane) {
+	if len(ds.Status.RetainedSchemas) == 0 {
+		return
+	}
+
+	ctx, cancelFn := context.WithTimeout(ctx, dataStoreHealthcheckTimeout)
+	defer cancelFn()
+
+	inUse := sets.NewString()
+	for _, tcp := range tcps {
+		inUse.Insert(tcp.Status.Storage.Setup.Schema)
+	}
+
+	connection, err := datastore.NewStorageConnection(ctx, r.client, *ds)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "cannot prune the retained schemas")
+
+		return
+	}
+	defer connection.Close()
+
+	retained := make([]string, 0, len(ds.Status.RetainedSchemas))
+
+	for _, schema := range ds.Status.RetainedSchemas {
+		if inUse.Has(schema) {
+			continue
+		}
+
+		exists, existsErr := connection.DBExists(ctx, schema)
+		if existsErr != nil {
+			log.FromContext(ctx).Error(existsErr, "cannot check the retained schema", "schema", schema)
+		}
+
+		if exists || existsErr != nil {
+			retained = append(retained, schema)
+		}
+	}
+
+	ds.Status.RetainedSchemas = retained
+}
+
+func (r *DataStore) checkHealth(ctx context.Context, ds kamajiv1alpha1.DataStore) error {
+	connection, err := datastore.NewStorageConnection(ctx, r.client, ds)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	if err = connection.Check(ctx); err != nil {
+		return err
+	}
+
+	return connection.Healthcheck(ctx)
+}
+
 func (r *DataStore) InjectClient(client client.Client) error {
 	r.client = client
 
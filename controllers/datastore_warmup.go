@@ -0,0 +1,95 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/pkg/datastore"
+)
+
+// DataStoreWarmUp connects in the background, upon the operator startup, to each DataStore used by the Tenant Control
+// Planes, validating it accepts connections ahead of their first reconciliations: the DataStores, and their Secrets,
+// are cached by the client, and the unreachable DataStores are recorded by the circuit breaker, rather than each
+// reconciliation paying the connection timeouts.
+// The connections are closed once validated, since they're never shared across the reconciliations.
+type DataStoreWarmUp struct {
+	Reconciler *TenantControlPlaneReconciler
+	// Timeout is the time waited for each DataStore to accept connections.
+	Timeout time.Duration
+}
+
+// NeedLeaderElection runs the warm-up along with the controllers, on the leader only.
+func (w *DataStoreWarmUp) NeedLeaderElection() bool {
+	return true
+}
+
+// Start warms up the DataStores concurrently: the failures are logged, and never returned, not to stop the manager.
+func (w *DataStoreWarmUp) Start(ctx context.Context) error {
+	logger := ctrl.Log.WithName("datastore-warm-up")
+
+	dataStores := &kamajiv1alpha1.DataStoreList{}
+	if err := w.Reconciler.Client.List(ctx, dataStores); err != nil {
+		logger.Error(err, "cannot list the DataStores, skipping the warm-up")
+
+		return nil
+	}
+
+	var wg sync.WaitGroup
+
+	for i := range dataStores.Items {
+		ds := dataStores.Items[i]
+		// The DataStores not used by any Tenant Control Plane are not going to be reconciled.
+		if len(ds.Status.UsedBy) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			w.warmUp(ctx, logger.WithValues("datastore", ds.GetName()), ds)
+		}()
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+func (w *DataStoreWarmUp) warmUp(ctx context.Context, logger logr.Logger, ds kamajiv1alpha1.DataStore) {
+	ctx, cancelFn := context.WithTimeout(ctx, w.Timeout)
+	defer cancelFn()
+
+	connection, err := datastore.NewStorageConnection(ctx, w.Reconciler.Client, ds)
+	if err != nil {
+		logger.Error(err, "cannot generate the DataStore connection")
+
+		w.Reconciler.dataStoreFailure(ctx, &ds)
+
+		return
+	}
+	defer func() {
+		if closeErr := connection.Close(); closeErr != nil {
+			logger.Error(closeErr, "cannot close the DataStore connection")
+		}
+	}()
+
+	if err = datastore.WaitReady(ctx, connection, w.Timeout); err != nil {
+		logger.Error(err, "DataStore is not accepting connections")
+
+		w.Reconciler.dataStoreFailure(ctx, &ds)
+
+		return
+	}
+
+	logger.Info("DataStore connection has been warmed up")
+}
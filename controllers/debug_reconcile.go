@@ -0,0 +1,165 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/juju/mutex/v2"
+	apimachineryerrors "k8s.io/apimachinery/pkg/api/errors"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/clastix/kamaji/internal/resources"
+	"github.com/clastix/kamaji/internal/resources/addons"
+	"github.com/clastix/kamaji/pkg/datastore"
+)
+
+// DebugReconcilePath is the path of the debug endpoint, served along with the metrics.
+const DebugReconcilePath = "/debug/reconcile"
+
+// DebugReconcileResponse is the outcome of the reconciliation of a single resource triggered by the debug endpoint.
+type DebugReconcileResponse struct {
+	Resource string                         `json:"resource"`
+	Result   controllerutil.OperationResult `json:"result,omitempty"`
+	Error    string                         `json:"error,omitempty"`
+}
+
+// DebugReconcile is the HTTP handler reconciling a single resource of a given Tenant Control Plane on demand,
+// such as the datastore setup or an addon, speeding up the troubleshooting with no need to restart the operator.
+// The requests must provide the namespace, name, and resource query parameters, and the bearer token:
+// the resource is defined and created or updated, with no changes to the Tenant Control Plane status.
+type DebugReconcile struct {
+	Reconciler *TenantControlPlaneReconciler
+	// Token is the bearer token authorizing the requests.
+	Token string
+}
+
+func (d *DebugReconcile) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only the POST method is allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if len(d.Token) == 0 || subtle.ConstantTimeCompare([]byte(token), []byte(d.Token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+		return
+	}
+
+	query := req.URL.Query()
+
+	namespacedName := k8stypes.NamespacedName{Namespace: query.Get("namespace"), Name: query.Get("name")}
+	resourceName := query.Get("resource")
+
+	if len(namespacedName.Namespace) == 0 || len(namespacedName.Name) == 0 || len(resourceName) == 0 {
+		http.Error(w, "the namespace, name, and resource query parameters are required", http.StatusBadRequest)
+
+		return
+	}
+
+	ctx, cancelFn := context.WithTimeout(req.Context(), d.Reconciler.Config.ReconcileTimeout)
+	defer cancelFn()
+
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithName("debug-reconcile").WithValues("tenantControlPlane", namespacedName.String(), "resource", resourceName))
+
+	status, response := d.reconcile(ctx, namespacedName, resourceName)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.FromContext(ctx).Error(err, "cannot write the debug reconciliation response")
+	}
+}
+
+func (d *DebugReconcile) reconcile(ctx context.Context, namespacedName k8stypes.NamespacedName, resourceName string) (int, DebugReconcileResponse) {
+	logger := log.FromContext(ctx)
+
+	response := DebugReconcileResponse{Resource: resourceName}
+
+	tenantControlPlane, err := d.Reconciler.getTenantControlPlane(ctx, namespacedName)()
+	if err != nil {
+		response.Error = err.Error()
+
+		if apimachineryerrors.IsNotFound(err) {
+			return http.StatusNotFound, response
+		}
+
+		return http.StatusInternalServerError, response
+	}
+	// Serializing with the controller reconciliations of the same Tenant Control Plane.
+	releaser, err := mutex.Acquire(d.Reconciler.mutexSpec(tenantControlPlane))
+	if err != nil {
+		response.Error = fmt.Sprintf("cannot acquire the Tenant Control Plane lock: %s", err.Error())
+
+		return http.StatusConflict, response
+	}
+	defer releaser.Release()
+
+	ds, err := d.Reconciler.dataStore(ctx, tenantControlPlane)
+	if err != nil {
+		response.Error = fmt.Sprintf("cannot retrieve the DataStore: %s", err.Error())
+
+		return http.StatusInternalServerError, response
+	}
+
+	connection, err := d.Reconciler.newDataStoreConnection(datastore.WithApplicationTenant(ctx, namespacedName.String()), d.Reconciler.Client, *ds)
+	if err != nil {
+		response.Error = fmt.Sprintf("cannot generate the DataStore connection: %s", err.Error())
+
+		return http.StatusInternalServerError, response
+	}
+	defer func() {
+		if closeErr := connection.Close(); closeErr != nil {
+			logger.Error(closeErr, "cannot close the DataStore connection")
+		}
+	}()
+
+	resource := d.lookup(d.Reconciler.groupResourceBuilderConfiguration(logger, tenantControlPlane, connection, ds), resourceName)
+	if resource == nil {
+		response.Error = fmt.Sprintf("the resource %s is not known", resourceName)
+
+		return http.StatusNotFound, response
+	}
+
+	logger.Info("reconciling the resource on demand")
+
+	if err = resource.Define(ctx, tenantControlPlane); err != nil {
+		response.Error = fmt.Sprintf("cannot define the resource: %s", err.Error())
+
+		return http.StatusInternalServerError, response
+	}
+
+	if response.Result, err = resource.CreateOrUpdate(ctx, tenantControlPlane); err != nil {
+		response.Error = err.Error()
+
+		return http.StatusInternalServerError, response
+	}
+
+	return http.StatusOK, response
+}
+
+// lookup returns the Tenant Control Plane resource, or the addon, with the given name.
+func (d *DebugReconcile) lookup(config GroupResourceBuilderConfiguration, name string) resources.Resource {
+	candidates := GetResources(config)
+	candidates = append(candidates, &addons.CoreDNS{Client: d.Reconciler.Client}, &addons.KubeProxy{Client: d.Reconciler.Client})
+	candidates = append(candidates, GetExternalKonnectivityResources(d.Reconciler.Client)...)
+
+	for _, resource := range candidates {
+		if resource.GetName() == name {
+			return resource
+		}
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ package controllers
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/uuid"
@@ -15,10 +16,10 @@ import (
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
 	"github.com/clastix/kamaji/controllers/finalizers"
 	builder "github.com/clastix/kamaji/internal/builders/controlplane"
-	"github.com/clastix/kamaji/internal/datastore"
 	"github.com/clastix/kamaji/internal/resources"
 	ds "github.com/clastix/kamaji/internal/resources/datastore"
 	"github.com/clastix/kamaji/internal/resources/konnectivity"
+	"github.com/clastix/kamaji/pkg/datastore"
 )
 
 type GroupResourceBuilderConfiguration struct {
@@ -32,6 +33,16 @@ type GroupResourceBuilderConfiguration struct {
 	KamajiServiceAccount string
 	KamajiService        string
 	KamajiMigrateImage   string
+	KamajiPodName        string
+	ExistingUserPolicy   ds.ExistingUserPolicy
+	// CertificateRenewalWindow is the amount of time before the expiration the DataStore certificates are reissued.
+	CertificateRenewalWindow time.Duration
+	// DriftCheckInterval is the interval after which the DataStore setup is verified against any external drift.
+	DriftCheckInterval time.Duration
+	// HealthCheckInterval is the interval after which the health of the etcd DataStore members is probed again.
+	HealthCheckInterval time.Duration
+	// PasswordGenerator generates the DataStore user passwords, when not provided.
+	PasswordGenerator ds.PasswordGenerator
 }
 
 type GroupDeletableResourceBuilderConfiguration struct {
@@ -40,6 +51,7 @@ type GroupDeletableResourceBuilderConfiguration struct {
 	tcpReconcilerConfig TenantControlPlaneReconcilerConfig
 	tenantControlPlane  kamajiv1alpha1.TenantControlPlane
 	connection          datastore.Connection
+	dataStore           kamajiv1alpha1.DataStore
 }
 
 // GetResources returns a list of resources that will be used to provide tenant control planes
@@ -59,12 +71,21 @@ func GetDeletableResources(tcp *kamajiv1alpha1.TenantControlPlane, config GroupD
 		res = append(res, &ds.Setup{
 			Client:     config.client,
 			Connection: config.connection,
+			DataStore:  config.dataStore,
 		})
 	}
 
 	return res
 }
 
+// isDataStoreSetupResource returns true for the resource performing the operations against the DataStore,
+// whose outcome is tracked by the DataStore circuit breaker.
+func isDataStoreSetupResource(resource resources.Resource) bool {
+	_, ok := resource.(*ds.Setup)
+
+	return ok
+}
+
 func getDefaultResources(config GroupResourceBuilderConfiguration) []resources.Resource {
 	resources := getDataStoreMigratingResources(config.client, config.KamajiNamespace, config.KamajiMigrateImage, config.KamajiServiceAccount, config.KamajiService)
 	resources = append(resources, getUpgradeResources(config.client)...)
@@ -72,7 +93,7 @@ func getDefaultResources(config GroupResourceBuilderConfiguration) []resources.R
 	resources = append(resources, getKubeadmConfigResources(config.client, getTmpDirectory(config.tcpReconcilerConfig.TmpBaseDirectory, config.tenantControlPlane), config.DataStore)...)
 	resources = append(resources, getKubernetesCertificatesResources(config.client, config.tcpReconcilerConfig, config.tenantControlPlane)...)
 	resources = append(resources, getKubeconfigResources(config.client, config.tcpReconcilerConfig, config.tenantControlPlane)...)
-	resources = append(resources, getKubernetesStorageResources(config.client, config.Connection, config.DataStore)...)
+	resources = append(resources, getKubernetesStorageResources(config.client, config.Connection, config.DataStore, config.KamajiPodName, config.ExistingUserPolicy, config.CertificateRenewalWindow, config.DriftCheckInterval, config.HealthCheckInterval, config.tcpReconcilerConfig.KineContainerImage, config.PasswordGenerator)...)
 	resources = append(resources, getKonnectivityServerRequirementsResources(config.client)...)
 	resources = append(resources, getKubernetesDeploymentResources(config.client, config.tcpReconcilerConfig, config.DataStore)...)
 	resources = append(resources, getKonnectivityServerPatchResources(config.client)...)
@@ -191,21 +212,32 @@ func getKubeconfigResources(c client.Client, tcpReconcilerConfig TenantControlPl
 	}
 }
 
-func getKubernetesStorageResources(c client.Client, dbConnection datastore.Connection, datastore kamajiv1alpha1.DataStore) []resources.Resource {
+func getKubernetesStorageResources(c client.Client, dbConnection datastore.Connection, datastore kamajiv1alpha1.DataStore, operatorIdentity string, existingUserPolicy ds.ExistingUserPolicy, certificateRenewalWindow, driftCheckInterval, healthCheckInterval time.Duration, kineImage string, passwordGenerator ds.PasswordGenerator) []resources.Resource {
 	return []resources.Resource{
 		&ds.Config{
-			Client:     c,
-			ConnString: dbConnection.GetConnectionString(),
-			DataStore:  datastore,
+			Client:            c,
+			ConnString:        dbConnection.GetConnectionString(),
+			DataStore:         datastore,
+			PasswordGenerator: passwordGenerator,
 		},
 		&ds.Setup{
-			Client:     c,
-			Connection: dbConnection,
-			DataStore:  datastore,
+			Client:             c,
+			Connection:         dbConnection,
+			DataStore:          datastore,
+			OperatorIdentity:   operatorIdentity,
+			ExistingUserPolicy: existingUserPolicy,
+			DriftCheckInterval: driftCheckInterval,
+			KineImage:          kineImage,
 		},
 		&ds.Certificate{
-			Client:    c,
-			DataStore: datastore,
+			Client:        c,
+			DataStore:     datastore,
+			RenewalWindow: certificateRenewalWindow,
+		},
+		&ds.Health{
+			Connection: dbConnection,
+			DataStore:  datastore,
+			Interval:   healthCheckInterval,
 		},
 	}
 }
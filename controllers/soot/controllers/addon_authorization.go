@@ -0,0 +1,59 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+)
+
+// addonForbiddenRequeueAfter is the interval the addons refused by the tenant API server are retried after:
+// a misconfigured RBAC requires the intervention of the tenant cluster administrators.
+const addonForbiddenRequeueAfter = 5 * time.Minute
+
+// updateAddonAuthorizedCondition reports in the Tenant Control Plane status if the tenant API server is accepting
+// the addon resources, given the outcome of their reconciliation: the condition is recorded upon the first refusal,
+// and set back to true upon the next success, leaving the status untouched for the addons never refused.
+func updateAddonAuthorizedCondition(ctx context.Context, c client.Client, tcp *kamajiv1alpha1.TenantControlPlane, conditionType string, forbiddenErr error) error {
+	condition := metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  kamajiv1alpha1.AddonAuthorizedReason,
+		Message: "the addon resources are accepted by the tenant API server",
+	}
+
+	if forbiddenErr != nil {
+		condition.Status, condition.Reason, condition.Message = metav1.ConditionFalse, kamajiv1alpha1.AddonForbiddenReason, forbiddenErr.Error()
+	}
+
+	current := meta.FindStatusCondition(tcp.Status.Conditions, conditionType)
+
+	switch {
+	case current == nil && forbiddenErr == nil:
+		return nil
+	case current != nil && current.Status == condition.Status && current.Reason == condition.Reason:
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() (err error) {
+		defer func() {
+			if err != nil {
+				_ = c.Get(ctx, k8stypes.NamespacedName{Namespace: tcp.GetNamespace(), Name: tcp.GetName()}, tcp)
+			}
+		}()
+
+		condition.ObservedGeneration = tcp.GetGeneration()
+		meta.SetStatusCondition(&tcp.Status.Conditions, condition)
+
+		return c.Status().Update(ctx, tcp)
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+)
+
+// addonRetryBudget counts the consecutive failed applies of an addon for a given Tenant Control Plane generation:
+// the count is kept in memory, since the soot controllers are serving a single Tenant Control Plane,
+// whilst the exhausted budget is recorded by the addon failed condition, thus surviving the restarts.
+type addonRetryBudget struct {
+	generation int64
+	attempts   int32
+}
+
+// fail records a failed apply for the given generation, returning true if the budget is exhausted:
+// a nil budget allows retrying indefinitely.
+func (b *addonRetryBudget) fail(generation int64, maxAttempts *int32) bool {
+	if b.generation != generation {
+		b.generation, b.attempts = generation, 0
+	}
+
+	b.attempts++
+
+	return maxAttempts != nil && b.attempts >= *maxAttempts
+}
+
+// reset restores the budget upon a successful apply.
+func (b *addonRetryBudget) reset() {
+	b.attempts = 0
+}
+
+// isAddonFailed returns true if the addon has been marked as failed for the current Tenant Control Plane generation:
+// its reconciliation is stopped until the spec changes.
+func isAddonFailed(tcp *kamajiv1alpha1.TenantControlPlane, conditionType string) bool {
+	condition := meta.FindStatusCondition(tcp.Status.Conditions, conditionType)
+
+	return condition != nil && condition.Status == metav1.ConditionTrue && condition.ObservedGeneration == tcp.GetGeneration()
+}
+
+// updateAddonFailedCondition reports in the Tenant Control Plane status if the addon has exhausted its retry budget,
+// with the last apply error: the condition is recorded upon the exhaustion, and set back to false upon the next
+// success, leaving the status untouched for the addons never failed.
+func updateAddonFailedCondition(ctx context.Context, c client.Client, tcp *kamajiv1alpha1.TenantControlPlane, conditionType string, failedErr error) error {
+	condition := metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  kamajiv1alpha1.AddonAppliedReason,
+		Message: "the addon resources have been applied",
+	}
+
+	if failedErr != nil {
+		condition.Status, condition.Reason, condition.Message = metav1.ConditionTrue, kamajiv1alpha1.AddonRetryBudgetExhaustedReason, failedErr.Error()
+	}
+
+	current := meta.FindStatusCondition(tcp.Status.Conditions, conditionType)
+
+	switch {
+	case current == nil && failedErr == nil:
+		return nil
+	case current != nil && current.Status == condition.Status && current.Reason == condition.Reason && failedErr == nil:
+		return nil
+	// The exhaustion is recorded again for each generation, stopping the reconciliation until the next spec change.
+	case current != nil && current.Status == condition.Status && current.ObservedGeneration == tcp.GetGeneration():
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() (err error) {
+		defer func() {
+			if err != nil {
+				_ = c.Get(ctx, k8stypes.NamespacedName{Namespace: tcp.GetNamespace(), Name: tcp.GetName()}, tcp)
+			}
+		}()
+
+		condition.ObservedGeneration = tcp.GetGeneration()
+		meta.SetStatusCondition(&tcp.Status.Conditions, condition)
+
+		return c.Status().Update(ctx, tcp)
+	})
+}
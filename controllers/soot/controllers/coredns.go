@@ -7,8 +7,10 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -21,7 +23,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
 	"github.com/clastix/kamaji/controllers/utils"
+	kamajierrors "github.com/clastix/kamaji/internal/errors"
 	"github.com/clastix/kamaji/internal/kubeadm"
 	"github.com/clastix/kamaji/internal/resources"
 	"github.com/clastix/kamaji/internal/resources/addons"
@@ -33,6 +37,8 @@ type CoreDNS struct {
 	AdminClient               client.Client
 	GetTenantControlPlaneFunc utils.TenantControlPlaneRetrievalFn
 	TriggerChannel            chan event.GenericEvent
+
+	retryBudget addonRetryBudget
 }
 
 func (c *CoreDNS) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
@@ -43,15 +49,64 @@ func (c *CoreDNS) Reconcile(ctx context.Context, request reconcile.Request) (rec
 		return reconcile.Result{}, err
 	}
 
+	// The addon exhausted its retry budget: it's not retried until the spec changes.
+	if isAddonFailed(tcp, kamajiv1alpha1.CoreDNSFailedCondition) {
+		c.logger.Info("addon retry budget exhausted, waiting for a spec change")
+
+		return reconcile.Result{}, nil
+	}
+
 	c.logger.Info("start processing")
 
 	resource := &addons.CoreDNS{Client: c.AdminClient}
 
 	result, handlingErr := resources.Handle(ctx, resource, tcp)
+	// Retrying shortly the addon resources refused by the tenant API server is pointless, until its RBAC is amended.
+	if forbiddenErr := (kamajierrors.AddonForbiddenError{}); errors.As(handlingErr, &forbiddenErr) {
+		c.logger.Info("addon resources forbidden by the tenant API server, enqueuing back", "error", handlingErr.Error(), "requeueAfter", addonForbiddenRequeueAfter)
+
+		if conditionErr := updateAddonAuthorizedCondition(ctx, c.AdminClient, tcp, kamajiv1alpha1.CoreDNSAuthorizedCondition, handlingErr); conditionErr != nil {
+			c.logger.Error(conditionErr, "cannot update the addon condition")
+		}
+
+		return reconcile.Result{RequeueAfter: addonForbiddenRequeueAfter}, nil
+	}
+
 	if handlingErr != nil {
 		c.logger.Error(handlingErr, "resource process failed", "resource", resource.GetName())
 
-		return reconcile.Result{}, handlingErr
+		var maxAttempts *int32
+		if addon := tcp.Spec.Addons.CoreDNS; addon != nil {
+			maxAttempts = addon.MaxAttempts
+		}
+
+		if !c.retryBudget.fail(tcp.GetGeneration(), maxAttempts) {
+			return reconcile.Result{}, handlingErr
+		}
+
+		c.logger.Info("addon retry budget exhausted, marking it as failed", "maxAttempts", *maxAttempts)
+
+		if conditionErr := updateAddonFailedCondition(ctx, c.AdminClient, tcp, kamajiv1alpha1.CoreDNSFailedCondition, handlingErr); conditionErr != nil {
+			c.logger.Error(conditionErr, "cannot update the addon condition")
+
+			return reconcile.Result{}, conditionErr
+		}
+
+		return reconcile.Result{}, nil
+	}
+
+	c.retryBudget.reset()
+
+	if err = updateAddonFailedCondition(ctx, c.AdminClient, tcp, kamajiv1alpha1.CoreDNSFailedCondition, nil); err != nil {
+		c.logger.Error(err, "cannot update the addon condition")
+
+		return reconcile.Result{}, err
+	}
+
+	if err = updateAddonAuthorizedCondition(ctx, c.AdminClient, tcp, kamajiv1alpha1.CoreDNSAuthorizedCondition, nil); err != nil {
+		c.logger.Error(err, "cannot update the addon condition")
+
+		return reconcile.Result{}, err
 	}
 
 	if result == controllerutil.OperationResultNone {
@@ -59,6 +114,20 @@ func (c *CoreDNS) Reconcile(ctx context.Context, request reconcile.Request) (rec
 
 		return reconcile.Result{}, nil
 	}
+	// The addon is waiting for the datastore setup, with no changes to the tenant cluster.
+	if result == resources.OperationResultEnqueueBack && !resource.ShouldCleanup(tcp) {
+		c.logger.V(1).Info("waiting for the datastore setup, enqueuing back")
+
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	if !resource.ShouldCleanup(tcp) {
+		if err = resource.Validate(ctx, tcp); err != nil {
+			c.logger.Error(err, "addon validation failed", "resource", resource.GetName())
+
+			return reconcile.Result{}, err
+		}
+	}
 
 	if err = utils.UpdateStatus(ctx, c.AdminClient, tcp, resource); err != nil {
 		c.logger.Error(err, "update status failed", "resource", resource.GetName())
@@ -66,6 +135,13 @@ func (c *CoreDNS) Reconcile(ctx context.Context, request reconcile.Request) (rec
 		return reconcile.Result{}, err
 	}
 
+	// The clean-up is still in progress, checking it again.
+	if result == resources.OperationResultEnqueueBack {
+		c.logger.Info("reconciliation pending, enqueuing back")
+
+		return reconcile.Result{Requeue: true}, nil
+	}
+
 	c.logger.Info("reconciliation processed")
 
 	return reconcile.Result{}, nil
@@ -85,5 +161,6 @@ func (c *CoreDNS) SetupWithManager(mgr manager.Manager) error {
 		Owns(&corev1.Service{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
 		Complete(c)
 }
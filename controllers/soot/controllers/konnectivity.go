@@ -44,6 +44,8 @@ func (k *KonnectivityAgent) Reconcile(ctx context.Context, _ reconcile.Request)
 		return reconcile.Result{}, err
 	}
 
+	statusBatch := &utils.StatusBatch{}
+
 	for _, resource := range controllers.GetExternalKonnectivityResources(k.AdminClient) {
 		k.logger.Info("start processing", "resource", resource.GetName())
 
@@ -51,6 +53,10 @@ func (k *KonnectivityAgent) Reconcile(ctx context.Context, _ reconcile.Request)
 		if handlingErr != nil {
 			k.logger.Error(handlingErr, "resource process failed", "resource", resource.GetName())
 
+			if err = statusBatch.Flush(ctx, k.AdminClient, tcp); err != nil {
+				k.logger.Error(err, "update status failed")
+			}
+
 			return reconcile.Result{}, handlingErr
 		}
 
@@ -60,11 +66,29 @@ func (k *KonnectivityAgent) Reconcile(ctx context.Context, _ reconcile.Request)
 			continue
 		}
 
-		if err = utils.UpdateStatus(ctx, k.AdminClient, tcp, resource); err != nil {
+		if err = statusBatch.Stage(ctx, tcp, resource); err != nil {
 			k.logger.Error(err, "update status failed", "resource", resource.GetName())
 
 			return reconcile.Result{}, err
 		}
+		// The clean-up is still in progress, checking it again.
+		if result == resources.OperationResultEnqueueBack {
+			k.logger.Info("resource pending, enqueuing back", "resource", resource.GetName())
+
+			if err = statusBatch.Flush(ctx, k.AdminClient, tcp); err != nil {
+				k.logger.Error(err, "update status failed")
+
+				return reconcile.Result{}, err
+			}
+
+			return reconcile.Result{Requeue: true}, nil
+		}
+	}
+
+	if err = statusBatch.Flush(ctx, k.AdminClient, tcp); err != nil {
+		k.logger.Error(err, "update status failed")
+
+		return reconcile.Result{}, err
 	}
 
 	k.logger.Info("reconciliation completed")
@@ -56,6 +56,13 @@ func (k *KubeadmPhase) Reconcile(ctx context.Context, _ reconcile.Request) (reco
 		return reconcile.Result{}, err
 	}
 
+	// The clean-up is still in progress, checking it again.
+	if result == resources.OperationResultEnqueueBack {
+		k.logger.Info("reconciliation pending, enqueuing back")
+
+		return reconcile.Result{Requeue: true}, nil
+	}
+
 	k.logger.Info("reconciliation processed")
 
 	return reconcile.Result{}, nil
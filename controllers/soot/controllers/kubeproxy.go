@@ -7,6 +7,7 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -21,7 +22,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
 	"github.com/clastix/kamaji/controllers/utils"
+	kamajierrors "github.com/clastix/kamaji/internal/errors"
 	"github.com/clastix/kamaji/internal/kubeadm"
 	"github.com/clastix/kamaji/internal/resources"
 	"github.com/clastix/kamaji/internal/resources/addons"
@@ -32,7 +35,8 @@ type KubeProxy struct {
 	GetTenantControlPlaneFunc utils.TenantControlPlaneRetrievalFn
 	TriggerChannel            chan event.GenericEvent
 
-	logger logr.Logger
+	logger      logr.Logger
+	retryBudget addonRetryBudget
 }
 
 func (k *KubeProxy) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
@@ -43,15 +47,64 @@ func (k *KubeProxy) Reconcile(ctx context.Context, _ reconcile.Request) (reconci
 		return reconcile.Result{}, err
 	}
 
+	// The addon exhausted its retry budget: it's not retried until the spec changes.
+	if isAddonFailed(tcp, kamajiv1alpha1.KubeProxyFailedCondition) {
+		k.logger.Info("addon retry budget exhausted, waiting for a spec change")
+
+		return reconcile.Result{}, nil
+	}
+
 	k.logger.Info("start processing")
 
 	resource := &addons.KubeProxy{Client: k.AdminClient}
 
 	result, handlingErr := resources.Handle(ctx, resource, tcp)
+	// Retrying shortly the addon resources refused by the tenant API server is pointless, until its RBAC is amended.
+	if forbiddenErr := (kamajierrors.AddonForbiddenError{}); errors.As(handlingErr, &forbiddenErr) {
+		k.logger.Info("addon resources forbidden by the tenant API server, enqueuing back", "error", handlingErr.Error(), "requeueAfter", addonForbiddenRequeueAfter)
+
+		if conditionErr := updateAddonAuthorizedCondition(ctx, k.AdminClient, tcp, kamajiv1alpha1.KubeProxyAuthorizedCondition, handlingErr); conditionErr != nil {
+			k.logger.Error(conditionErr, "cannot update the addon condition")
+		}
+
+		return reconcile.Result{RequeueAfter: addonForbiddenRequeueAfter}, nil
+	}
+
 	if handlingErr != nil {
 		k.logger.Error(handlingErr, "resource process failed", "resource", resource.GetName())
 
-		return reconcile.Result{}, handlingErr
+		var maxAttempts *int32
+		if addon := tcp.Spec.Addons.KubeProxy; addon != nil {
+			maxAttempts = addon.MaxAttempts
+		}
+
+		if !k.retryBudget.fail(tcp.GetGeneration(), maxAttempts) {
+			return reconcile.Result{}, handlingErr
+		}
+
+		k.logger.Info("addon retry budget exhausted, marking it as failed", "maxAttempts", *maxAttempts)
+
+		if conditionErr := updateAddonFailedCondition(ctx, k.AdminClient, tcp, kamajiv1alpha1.KubeProxyFailedCondition, handlingErr); conditionErr != nil {
+			k.logger.Error(conditionErr, "cannot update the addon condition")
+
+			return reconcile.Result{}, conditionErr
+		}
+
+		return reconcile.Result{}, nil
+	}
+
+	k.retryBudget.reset()
+
+	if err = updateAddonFailedCondition(ctx, k.AdminClient, tcp, kamajiv1alpha1.KubeProxyFailedCondition, nil); err != nil {
+		k.logger.Error(err, "cannot update the addon condition")
+
+		return reconcile.Result{}, err
+	}
+
+	if err = updateAddonAuthorizedCondition(ctx, k.AdminClient, tcp, kamajiv1alpha1.KubeProxyAuthorizedCondition, nil); err != nil {
+		k.logger.Error(err, "cannot update the addon condition")
+
+		return reconcile.Result{}, err
 	}
 
 	if result == controllerutil.OperationResultNone {
@@ -59,6 +112,20 @@ func (k *KubeProxy) Reconcile(ctx context.Context, _ reconcile.Request) (reconci
 
 		return reconcile.Result{}, nil
 	}
+	// The addon is waiting for the datastore setup, with no changes to the tenant cluster.
+	if result == resources.OperationResultEnqueueBack && !resource.ShouldCleanup(tcp) {
+		k.logger.V(1).Info("waiting for the datastore setup, enqueuing back")
+
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	if !resource.ShouldCleanup(tcp) {
+		if err = resource.Validate(ctx, tcp); err != nil {
+			k.logger.Error(err, "addon validation failed", "resource", resource.GetName())
+
+			return reconcile.Result{}, err
+		}
+	}
 
 	if err = utils.UpdateStatus(ctx, k.AdminClient, tcp, resource); err != nil {
 		k.logger.Error(err, "update status failed")
@@ -66,6 +133,13 @@ func (k *KubeProxy) Reconcile(ctx context.Context, _ reconcile.Request) (reconci
 		return reconcile.Result{}, err
 	}
 
+	// The clean-up is still in progress, checking it again.
+	if result == resources.OperationResultEnqueueBack {
+		k.logger.Info("reconciliation pending, enqueuing back")
+
+		return reconcile.Result{Requeue: true}, nil
+	}
+
 	k.logger.Info("reconciliation processed")
 
 	return reconcile.Result{}, nil
@@ -60,6 +60,8 @@ func (m *Manager) retrieveTenantControlPlane(ctx context.Context, request reconc
 		if err := m.client.Get(ctx, request.NamespacedName, tcp); err != nil {
 			return nil, err
 		}
+		// The addons profile is resolved in memory, the Tenant Control Plane must not be updated as it is.
+		tcp.Spec.Addons.ApplyProfile()
 
 		return tcp, nil
 	}
@@ -71,8 +73,8 @@ func (m *Manager) cleanup(ctx context.Context, req reconcile.Request, tenantCont
 	if tenantControlPlane != nil && controllerutil.ContainsFinalizer(tenantControlPlane, finalizers.SootFinalizer) {
 		defer func() {
 			err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
-				tcp, tcpErr := m.retrieveTenantControlPlane(ctx, req)()
-				if tcpErr != nil {
+				tcp := &kamajiv1alpha1.TenantControlPlane{}
+				if tcpErr := m.client.Get(ctx, req.NamespacedName, tcp); tcpErr != nil {
 					return tcpErr
 				}
 
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/juju/mutex/v2"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
@@ -16,6 +17,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apimachineryerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/clock"
@@ -34,9 +37,23 @@ import (
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
 	"github.com/clastix/kamaji/controllers/finalizers"
 	"github.com/clastix/kamaji/controllers/utils"
-	"github.com/clastix/kamaji/internal/datastore"
+	"github.com/clastix/kamaji/internal/constants"
 	kamajierrors "github.com/clastix/kamaji/internal/errors"
+	kamajimetrics "github.com/clastix/kamaji/internal/metrics"
 	"github.com/clastix/kamaji/internal/resources"
+	ds "github.com/clastix/kamaji/internal/resources/datastore"
+	"github.com/clastix/kamaji/internal/utilities"
+	"github.com/clastix/kamaji/pkg/datastore"
+	datastoreerrors "github.com/clastix/kamaji/pkg/datastore/errors"
+)
+
+const (
+	// dataStoreTransientRequeueAfter is the delay before retrying upon the transient DataStore errors,
+	// such as the connectivity ones.
+	dataStoreTransientRequeueAfter = 5 * time.Second
+	// dataStorePermissionRequeueAfter is the delay before retrying upon the DataStore permission errors,
+	// which are not expected to be solved on their own.
+	dataStorePermissionRequeueAfter = 5 * time.Minute
 )
 
 // TenantControlPlaneReconciler reconciles a TenantControlPlane object.
@@ -49,13 +66,30 @@ type TenantControlPlaneReconciler struct {
 	KamajiServiceAccount    string
 	KamajiService           string
 	KamajiMigrateImage      string
+	KamajiPodName           string
 	MaxConcurrentReconciles int
+	// DataStoreExistingUserPolicy defines the handling of the DataStore users created out of band.
+	DataStoreExistingUserPolicy ds.ExistingUserPolicy
+	// DataStoreCertificateRenewalWindow is the amount of time before the expiration the etcd client certificates are reissued.
+	DataStoreCertificateRenewalWindow time.Duration
+	// DataStoreDriftCheckInterval is the interval after which the DataStore setup is verified against any external drift.
+	DataStoreDriftCheckInterval time.Duration
+	// DataStoreHealthCheckInterval is the interval after which the health of the etcd DataStore members is probed again,
+	// enqueuing back the Tenant Control Planes accordingly: the probes are disabled when zero.
+	DataStoreHealthCheckInterval time.Duration
+	// DataStorePasswordGenerator generates the DataStore user passwords, when not provided.
+	DataStorePasswordGenerator ds.PasswordGenerator
+	// DataStoreCircuitBreaker short-circuits the reconciliations of the Tenant Control Planes
+	// using a DataStore that failed consecutively, reducing the noise during the outages.
+	DataStoreCircuitBreaker *datastore.CircuitBreaker
 	// CertificateChan is the channel used by the CertificateLifecycleController that is checking for
 	// certificates and kubeconfig user certs validity: a generic event for the given TCP will be triggered
 	// once the validity threshold for the given certificate is reached.
 	CertificateChan CertificateChannel
 
 	clock mutex.Clock
+	// newDataStoreConnection opens the DataStore connection of each reconciliation, which is closed once completed.
+	newDataStoreConnection func(ctx context.Context, client client.Client, ds kamajiv1alpha1.DataStore) (datastore.Connection, error)
 }
 
 // TenantControlPlaneReconcilerConfig gives the necessary configuration for TenantControlPlaneReconciler.
@@ -76,7 +110,7 @@ type TenantControlPlaneReconcilerConfig struct {
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
 
-func (r *TenantControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *TenantControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res ctrl.Result, err error) {
 	log := log.FromContext(ctx)
 
 	var cancelFn context.CancelFunc
@@ -87,6 +121,8 @@ func (r *TenantControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 	if err != nil {
 		if apimachineryerrors.IsNotFound(err) {
 			log.Info("resource may have been deleted, skipping")
+			// The finalizer could have been removed manually, unblocking the deletion.
+			kamajimetrics.TenantControlPlaneDeletionBlocked.DeleteLabelValues(req.Namespace, req.Name, kamajimetrics.DeletionBlockedDataStoreReason)
 
 			return ctrl.Result{}, nil
 		}
@@ -95,6 +131,10 @@ func (r *TenantControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 		return ctrl.Result{}, err
 	}
+	// Logging the statements performed against the DataStore regardless of the verbosity, for troubleshooting purposes.
+	if _, ok := tenantControlPlane.GetAnnotations()[constants.LogDataStoreStatements]; ok {
+		ctx = datastore.WithStatementLogging(ctx)
+	}
 
 	releaser, err := mutex.Acquire(r.mutexSpec(tenantControlPlane))
 	if err != nil {
@@ -114,12 +154,28 @@ func (r *TenantControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 		}
 	}
 	defer releaser.Release()
+	// The status changes, such as the conditions, are staged and persisted once upon the end of the reconciliation,
+	// including the early returns, in order to not lose the ones already staged.
+	statusBatch := &utils.StatusBatch{}
+
+	defer func() {
+		// The Tenant Control Plane could have been deleted, once its finalizers have been removed.
+		if flushErr := statusBatch.Flush(ctx, r.Client, tenantControlPlane); client.IgnoreNotFound(flushErr) != nil {
+			log.Error(flushErr, "update of the staged status failed")
+
+			if err == nil {
+				res, err = ctrl.Result{}, flushErr
+			}
+		}
+	}()
 
 	markedToBeDeleted := tenantControlPlane.GetDeletionTimestamp() != nil
 
 	if markedToBeDeleted && !controllerutil.ContainsFinalizer(tenantControlPlane, finalizers.DatastoreFinalizer) {
 		return ctrl.Result{}, nil
 	}
+
+	r.updatePausedCondition(tenantControlPlane, statusBatch)
 	// Retrieving the DataStore to use for the current reconciliation
 	ds, err := r.dataStore(ctx, tenantControlPlane)
 	if err != nil {
@@ -128,15 +184,30 @@ func (r *TenantControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, err
 	}
 
-	dsConnection, err := datastore.NewStorageConnection(ctx, r.Client, *ds)
+	// Labelling the DataStore connections with the Tenant Control Plane, attributing them on the DataStore side.
+	dsConnection, err := r.newDataStoreConnection(datastore.WithApplicationTenant(ctx, req.NamespacedName.String()), r.Client, *ds)
 	if err != nil {
-		log.Error(err, "cannot generate the DataStore connection for the given instance")
+		return r.dataStoreErrorResult(ctx, tenantControlPlane, statusBatch, ds, errors.Wrap(err, "cannot generate the DataStore connection for the given instance"))
+	}
+	defer func() {
+		if closeErr := dsConnection.Close(); closeErr != nil {
+			log.Error(closeErr, "cannot close the DataStore connection")
+		}
+	}()
 
-		return ctrl.Result{}, err
+	if allowed, requeueAfter := r.dataStoreCircuitAllows(ctx, tenantControlPlane, statusBatch, ds, dsConnection); !allowed {
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
-	defer dsConnection.Close()
 
 	if markedToBeDeleted && controllerutil.ContainsFinalizer(tenantControlPlane, finalizers.DatastoreFinalizer) {
+		// The addons controllers must be stopped before cleaning up the DataStore, which is backing
+		// the tenant API Server they're interacting with.
+		if controllerutil.ContainsFinalizer(tenantControlPlane, finalizers.SootFinalizer) {
+			log.Info("marked for deletion, waiting for the addons controllers to be stopped")
+
+			return ctrl.Result{RequeueAfter: time.Second}, nil
+		}
+
 		log.Info("marked for deletion, performing clean-up")
 
 		groupDeletableResourceBuilderConfiguration := GroupDeletableResourceBuilderConfiguration{
@@ -145,37 +216,50 @@ func (r *TenantControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 			tcpReconcilerConfig: r.Config,
 			tenantControlPlane:  *tenantControlPlane,
 			connection:          dsConnection,
+			dataStore:           *ds,
 		}
 
 		for _, resource := range GetDeletableResources(tenantControlPlane, groupDeletableResourceBuilderConfiguration) {
 			if err = resources.HandleDeletion(ctx, resource, tenantControlPlane); err != nil {
+				if graceErr := (kamajierrors.DeletionGracePeriodError{}); errors.As(err, &graceErr) {
+					log.Info("resource deletion is pending", "resource", resource.GetName(), "requeueAfter", graceErr.RequeueAfter)
+
+					return ctrl.Result{RequeueAfter: graceErr.RequeueAfter}, nil
+				}
+
+				r.dataStoreFailure(ctx, ds)
+
+				kamajimetrics.TenantControlPlaneDeletionBlocked.WithLabelValues(tenantControlPlane.GetNamespace(), tenantControlPlane.GetName(), kamajimetrics.DeletionBlockedDataStoreReason).Set(1)
+
 				log.Error(err, "resource deletion failed", "resource", resource.GetName())
 
 				return ctrl.Result{}, err
 			}
 		}
 
+		kamajimetrics.TenantControlPlaneDeletionBlocked.DeleteLabelValues(tenantControlPlane.GetNamespace(), tenantControlPlane.GetName(), kamajimetrics.DeletionBlockedDataStoreReason)
+
 		log.Info("resource deletions have been completed")
 
 		return ctrl.Result{}, nil
 	}
 
-	groupResourceBuilderConfiguration := GroupResourceBuilderConfiguration{
-		client:               r.Client,
-		log:                  log,
-		tcpReconcilerConfig:  r.Config,
-		tenantControlPlane:   *tenantControlPlane,
-		Connection:           dsConnection,
-		DataStore:            *ds,
-		KamajiNamespace:      r.KamajiNamespace,
-		KamajiServiceAccount: r.KamajiServiceAccount,
-		KamajiService:        r.KamajiService,
-		KamajiMigrateImage:   r.KamajiMigrateImage,
-	}
-	registeredResources := GetResources(groupResourceBuilderConfiguration)
+	registeredResources := GetResources(r.groupResourceBuilderConfiguration(log, tenantControlPlane, dsConnection, ds))
 
 	for _, resource := range registeredResources {
 		result, err := resources.Handle(ctx, resource, tenantControlPlane)
+		if isDataStoreSetupResource(resource) {
+			switch {
+			case errors.As(err, &datastoreerrors.QuotaExceededError{}):
+				// The DataStore is healthy, although the new tenants can't be provisioned.
+				r.updateDataStoreCondition(tenantControlPlane, statusBatch, metav1.ConditionFalse, kamajiv1alpha1.DataStoreQuotaExceededReason, err.Error())
+			case err != nil && !kamajierrors.ShouldReconcileErrorBeIgnored(err):
+				return r.dataStoreErrorResult(ctx, tenantControlPlane, statusBatch, ds, errors.Wrap(err, "handling of the DataStore setup failed"))
+			default:
+				r.dataStoreSuccess(ctx, tenantControlPlane, statusBatch, ds)
+			}
+		}
+
 		if err != nil {
 			if kamajierrors.ShouldReconcileErrorBeIgnored(err) {
 				log.V(1).Info("sentinel error, enqueuing back request", "error", err.Error())
@@ -192,7 +276,7 @@ func (r *TenantControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 			continue
 		}
 
-		if err = utils.UpdateStatus(ctx, r.Client, tenantControlPlane, resource); err != nil {
+		if err = statusBatch.Stage(ctx, tenantControlPlane, resource); err != nil {
 			log.Error(err, "update of the resource failed", "resource", resource.GetName())
 
 			return ctrl.Result{}, err
@@ -208,10 +292,36 @@ func (r *TenantControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 	}
 
 	log.Info(fmt.Sprintf("%s has been reconciled", tenantControlPlane.GetName()))
+	// Refreshing the health of the DataStore members, regardless of any change.
+	if ds.Spec.Driver == kamajiv1alpha1.EtcdDriver && r.DataStoreHealthCheckInterval > 0 {
+		return ctrl.Result{RequeueAfter: r.DataStoreHealthCheckInterval}, nil
+	}
 
 	return ctrl.Result{}, nil
 }
 
+// groupResourceBuilderConfiguration returns the configuration of the resources reconciled for the given Tenant Control Plane.
+func (r *TenantControlPlaneReconciler) groupResourceBuilderConfiguration(log logr.Logger, tenantControlPlane *kamajiv1alpha1.TenantControlPlane, connection datastore.Connection, ds *kamajiv1alpha1.DataStore) GroupResourceBuilderConfiguration {
+	return GroupResourceBuilderConfiguration{
+		client:                   r.Client,
+		log:                      log,
+		tcpReconcilerConfig:      r.Config,
+		tenantControlPlane:       *tenantControlPlane,
+		Connection:               connection,
+		DataStore:                *ds,
+		KamajiNamespace:          r.KamajiNamespace,
+		KamajiServiceAccount:     r.KamajiServiceAccount,
+		KamajiService:            r.KamajiService,
+		KamajiMigrateImage:       r.KamajiMigrateImage,
+		KamajiPodName:            r.KamajiPodName,
+		ExistingUserPolicy:       r.DataStoreExistingUserPolicy,
+		CertificateRenewalWindow: r.DataStoreCertificateRenewalWindow,
+		DriftCheckInterval:       r.DataStoreDriftCheckInterval,
+		HealthCheckInterval:      r.DataStoreHealthCheckInterval,
+		PasswordGenerator:        r.DataStorePasswordGenerator,
+	}
+}
+
 func (r *TenantControlPlaneReconciler) mutexSpec(obj client.Object) mutex.Spec {
 	return mutex.Spec{
 		Name:    strings.ReplaceAll(fmt.Sprintf("kamaji%s", obj.GetUID()), "-", ""),
@@ -225,6 +335,7 @@ func (r *TenantControlPlaneReconciler) mutexSpec(obj client.Object) mutex.Spec {
 // SetupWithManager sets up the controller with the Manager.
 func (r *TenantControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.clock = clock.RealClock{}
+	r.newDataStoreConnection = datastore.NewStorageConnection
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Watches(&source.Channel{Source: r.CertificateChan}, handler.Funcs{GenericFunc: func(genericEvent event.GenericEvent, limitingInterface workqueue.RateLimitingInterface) {
@@ -289,6 +400,8 @@ func (r *TenantControlPlaneReconciler) getTenantControlPlane(ctx context.Context
 		if err := r.APIReader.Get(ctx, namespacedName, tcp); err != nil {
 			return nil, err
 		}
+		// The addons profile is resolved in memory, the Tenant Control Plane must not be updated as it is.
+		tcp.Spec.Addons.ApplyProfile()
 
 		return tcp, nil
 	}
@@ -300,6 +413,126 @@ func (r *TenantControlPlaneReconciler) RemoveFinalizer(ctx context.Context, tena
 	return r.Client.Update(ctx, tenantControlPlane)
 }
 
+// dataStoreCircuitAllows checks the DataStore circuit breaker: when open, the Tenant Control Plane condition is
+// updated, and the reconciliation must be enqueued back after the returned cool-down period.
+// Once the cool-down period is elapsed, a single reconciliation probes the DataStore for its recovery.
+func (r *TenantControlPlaneReconciler) dataStoreCircuitAllows(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane, statusBatch *utils.StatusBatch, ds *kamajiv1alpha1.DataStore, connection datastore.Connection) (bool, time.Duration) {
+	log := log.FromContext(ctx)
+
+	allowed, probe, remaining := r.DataStoreCircuitBreaker.Allow(ds.GetName())
+	if !allowed {
+		log.V(1).Info("DataStore circuit breaker is open, skipping reconciliation", "datastore", ds.GetName(), "requeueAfter", remaining)
+
+		message := fmt.Sprintf("the DataStore %s failed consecutively, the reconciliation is paused until its recovery", ds.GetName())
+		r.updateDataStoreCondition(tenantControlPlane, statusBatch, metav1.ConditionFalse, kamajiv1alpha1.DataStoreCircuitBreakerOpenReason, message)
+
+		return false, remaining
+	}
+
+	if !probe {
+		return true, 0
+	}
+
+	if err := connection.Check(ctx); err != nil {
+		log.V(1).Info("DataStore recovery probe failed", "datastore", ds.GetName(), "error", err.Error())
+
+		r.DataStoreCircuitBreaker.Failure(ds.GetName())
+
+		return false, r.DataStoreCircuitBreaker.CoolDown
+	}
+
+	if r.DataStoreCircuitBreaker.Success(ds.GetName()) {
+		log.Info("DataStore has recovered, circuit breaker is closed", "datastore", ds.GetName())
+	}
+
+	return true, 0
+}
+
+// dataStoreErrorResult returns the reconciliation result according to the class of the given DataStore error:
+// the transient errors are retried shortly, and the permission ones after a longer delay, since they require
+// the intervention of the DataStore administrators, while the invalid configuration ones are retried only upon
+// the DataStore changes. Both are reported by the DataStore condition, and any other error is returned,
+// relying on the controller backoff.
+func (r *TenantControlPlaneReconciler) dataStoreErrorResult(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane, statusBatch *utils.StatusBatch, ds *kamajiv1alpha1.DataStore, err error) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	switch datastore.ClassifyError(err) {
+	case datastore.TransientErrorClass:
+		r.dataStoreFailure(ctx, ds)
+
+		log.Info("transient DataStore error, enqueuing back", "error", err.Error(), "requeueAfter", dataStoreTransientRequeueAfter)
+
+		return ctrl.Result{RequeueAfter: dataStoreTransientRequeueAfter}, nil
+	case datastore.PermissionErrorClass:
+		log.Error(err, "DataStore permission denied, enqueuing back", "requeueAfter", dataStorePermissionRequeueAfter)
+
+		r.updateDataStoreCondition(tenantControlPlane, statusBatch, metav1.ConditionFalse, kamajiv1alpha1.DataStorePermissionDeniedReason, err.Error())
+
+		return ctrl.Result{RequeueAfter: dataStorePermissionRequeueAfter}, nil
+	case datastore.InvalidConfigErrorClass:
+		log.Error(err, "invalid DataStore configuration, waiting for its change")
+
+		r.updateDataStoreCondition(tenantControlPlane, statusBatch, metav1.ConditionFalse, kamajiv1alpha1.DataStoreInvalidConfigReason, err.Error())
+
+		return ctrl.Result{}, nil
+	default:
+		r.dataStoreFailure(ctx, ds)
+
+		log.Error(err, "DataStore error")
+
+		return ctrl.Result{}, err
+	}
+}
+
+func (r *TenantControlPlaneReconciler) dataStoreFailure(ctx context.Context, ds *kamajiv1alpha1.DataStore) {
+	if r.DataStoreCircuitBreaker.Failure(ds.GetName()) {
+		log.FromContext(ctx).Info("DataStore failed consecutively, circuit breaker is open",
+			"datastore", ds.GetName(),
+			"failures", r.DataStoreCircuitBreaker.Threshold,
+			"coolDown", r.DataStoreCircuitBreaker.CoolDown,
+			"tenantControlPlanes", len(ds.Status.UsedBy),
+		)
+	}
+}
+
+func (r *TenantControlPlaneReconciler) dataStoreSuccess(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane, statusBatch *utils.StatusBatch, ds *kamajiv1alpha1.DataStore) {
+	if r.DataStoreCircuitBreaker.Success(ds.GetName()) {
+		log.FromContext(ctx).Info("DataStore has recovered, circuit breaker is closed", "datastore", ds.GetName())
+	}
+
+	r.updateDataStoreCondition(tenantControlPlane, statusBatch, metav1.ConditionTrue, kamajiv1alpha1.DataStoreAvailableReason, fmt.Sprintf("the DataStore %s is available", ds.GetName()))
+}
+
+// updatePausedCondition reflects the paused state of the reconciliation in the Tenant Control Plane status.
+func (r *TenantControlPlaneReconciler) updatePausedCondition(tenantControlPlane *kamajiv1alpha1.TenantControlPlane, statusBatch *utils.StatusBatch) {
+	if utilities.IsPaused(tenantControlPlane) {
+		r.updateCondition(tenantControlPlane, statusBatch, kamajiv1alpha1.PausedCondition, metav1.ConditionTrue, kamajiv1alpha1.PausedReason, fmt.Sprintf("the reconciliation of the datastore and addons is paused by the %s annotation", constants.PausedReconciliation))
+
+		return
+	}
+
+	r.updateCondition(tenantControlPlane, statusBatch, kamajiv1alpha1.PausedCondition, metav1.ConditionFalse, kamajiv1alpha1.ReconcileReason, "the reconciliation is not paused")
+}
+
+func (r *TenantControlPlaneReconciler) updateDataStoreCondition(tenantControlPlane *kamajiv1alpha1.TenantControlPlane, statusBatch *utils.StatusBatch, status metav1.ConditionStatus, reason, message string) {
+	r.updateCondition(tenantControlPlane, statusBatch, kamajiv1alpha1.DataStoreAvailableCondition, status, reason, message)
+}
+
+// updateCondition stages the given condition in the status batch, persisted along with the other status changes.
+func (r *TenantControlPlaneReconciler) updateCondition(tenantControlPlane *kamajiv1alpha1.TenantControlPlane, statusBatch *utils.StatusBatch, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	if condition := meta.FindStatusCondition(tenantControlPlane.Status.Conditions, conditionType); condition != nil && condition.Status == status && condition.Reason == reason {
+		return
+	}
+
+	statusBatch.StageCondition(tenantControlPlane, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: tenantControlPlane.GetGeneration(),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
 // dataStore retrieves the override DataStore for the given Tenant Control Plane if specified,
 // otherwise fallback to the default one specified in the Kamaji setup.
 func (r *TenantControlPlaneReconciler) dataStore(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (*kamajiv1alpha1.DataStore, error) {
@@ -312,6 +545,12 @@ func (r *TenantControlPlaneReconciler) dataStore(ctx context.Context, tenantCont
 	if err := r.Client.Get(ctx, k8stypes.NamespacedName{Name: dataStoreName}, ds); err != nil {
 		return nil, errors.Wrap(err, "cannot retrieve *kamajiv1alpha.DataStore object")
 	}
+	// Resolving the environment variables references, since the DataStore endpoints are propagated to the
+	// Tenant Control Plane components, such as the kine and etcd configuration.
+	resolved, err := datastore.ResolveEnv(*ds)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot resolve the DataStore environment variables")
+	}
 
-	return ds, nil
+	return &resolved, nil
 }
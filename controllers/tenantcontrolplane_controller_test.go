@@ -0,0 +1,129 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/controllers/finalizers"
+	"github.com/clastix/kamaji/pkg/datastore"
+	"github.com/clastix/kamaji/pkg/datastore/datastoretest"
+)
+
+var _ = Describe("Tenant Control Plane reconciliation", func() {
+	var (
+		ctx         context.Context
+		mu          sync.Mutex
+		connections []*datastoretest.Connection
+		reconciler  *TenantControlPlaneReconciler
+	)
+
+	newReconciler := func(objects ...client.Object) *TenantControlPlaneReconciler {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(kamajiv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+
+		return &TenantControlPlaneReconciler{
+			Client:    c,
+			APIReader: c,
+			Config: TenantControlPlaneReconcilerConfig{
+				ReconcileTimeout:     10 * time.Second,
+				DefaultDataStoreName: "default",
+			},
+			clock: clock.RealClock{},
+			newDataStoreConnection: func(context.Context, client.Client, kamajiv1alpha1.DataStore) (datastore.Connection, error) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				connection := datastoretest.NewConnection()
+				connections = append(connections, connection)
+
+				return connection, nil
+			},
+		}
+	}
+
+	newTenantControlPlane := func() *kamajiv1alpha1.TenantControlPlane {
+		return &kamajiv1alpha1.TenantControlPlane{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "default",
+				UID:       "8b2c9d6e-7f41-4c3a-9e2d-5a1b6c7d8e9f",
+			},
+		}
+	}
+
+	dataStore := &kamajiv1alpha1.DataStore{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec:       kamajiv1alpha1.DataStoreSpec{Driver: kamajiv1alpha1.KinePostgreSQLDriver},
+	}
+
+	reconcile := func(times int) {
+		for i := 0; i < times; i++ {
+			_, _ = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: k8stypes.NamespacedName{Namespace: "default", Name: "test"}})
+		}
+	}
+
+	expectNoLeak := func(times int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		Expect(connections).To(HaveLen(times))
+
+		for _, connection := range connections {
+			Expect(connection.Closed).To(BeTrue())
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		connections = nil
+	})
+
+	It("should close the DataStore connection of each reconciliation", func() {
+		reconciler = newReconciler(newTenantControlPlane(), dataStore.DeepCopy())
+
+		reconcile(3)
+
+		expectNoLeak(3)
+	})
+
+	It("should close the DataStore connection when the circuit breaker is open", func() {
+		reconciler = newReconciler(newTenantControlPlane(), dataStore.DeepCopy())
+		reconciler.DataStoreCircuitBreaker = &datastore.CircuitBreaker{Threshold: 1, CoolDown: time.Minute}
+		reconciler.DataStoreCircuitBreaker.Failure(dataStore.GetName())
+
+		reconcile(3)
+
+		expectNoLeak(3)
+	})
+
+	It("should close the DataStore connection when waiting for the addons controllers upon the deletion", func() {
+		tcp := newTenantControlPlane()
+		tcp.SetFinalizers([]string{finalizers.DatastoreFinalizer, finalizers.SootFinalizer})
+		tcp.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+
+		reconciler = newReconciler(tcp, dataStore.DeepCopy())
+
+		reconcile(3)
+
+		expectNoLeak(3)
+	})
+})
@@ -0,0 +1,84 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/internal/resources"
+)
+
+// StatusBatch accumulates the status changes of the resources handled in a reconciliation,
+// persisting them with a single update of the Tenant Control Plane status, reducing the conflicts and the API writes.
+type StatusBatch struct {
+	staged     []resources.Resource
+	conditions []metav1.Condition
+}
+
+// Stage applies the status changes of the given resource to the in-memory Tenant Control Plane,
+// allowing the next resources to rely on them, and records the resource for the next Flush.
+func (b *StatusBatch) Stage(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane, resource resources.Resource) error {
+	if err := resource.UpdateTenantControlPlaneStatus(ctx, tcp); err != nil {
+		return fmt.Errorf("error applying TenantcontrolPlane status: %w", err)
+	}
+
+	b.staged = append(b.staged, resource)
+
+	return nil
+}
+
+// StageCondition sets the given condition to the in-memory Tenant Control Plane, and records it for the next Flush.
+func (b *StatusBatch) StageCondition(tcp *kamajiv1alpha1.TenantControlPlane, condition metav1.Condition) {
+	meta.SetStatusCondition(&tcp.Status.Conditions, condition)
+
+	b.conditions = append(b.conditions, condition)
+}
+
+// Flush persists the staged status changes with a single update: since the in-memory Tenant Control Plane could have
+// been retrieved again in the meanwhile, such as upon a conflict, the staged changes are applied in order before
+// every attempt. The batch is emptied once the update succeeds.
+func (b *StatusBatch) Flush(ctx context.Context, client client.Client, tcp *kamajiv1alpha1.TenantControlPlane) error {
+	if len(b.staged) == 0 && len(b.conditions) == 0 {
+		return nil
+	}
+
+	updateErr := retry.RetryOnConflict(retry.DefaultRetry, func() (err error) {
+		defer func() {
+			if err != nil {
+				_ = client.Get(ctx, types.NamespacedName{Name: tcp.Name, Namespace: tcp.Namespace}, tcp)
+			}
+		}()
+
+		for _, resource := range b.staged {
+			if err = resource.UpdateTenantControlPlaneStatus(ctx, tcp); err != nil {
+				return fmt.Errorf("error applying TenantcontrolPlane status: %w", err)
+			}
+		}
+
+		for _, condition := range b.conditions {
+			meta.SetStatusCondition(&tcp.Status.Conditions, condition)
+		}
+
+		if err = client.Status().Update(ctx, tcp); err != nil {
+			return fmt.Errorf("error updating tenantControlPlane status: %w", err)
+		}
+
+		return nil
+	})
+	if updateErr != nil {
+		return updateErr
+	}
+
+	b.staged, b.conditions = nil, nil
+
+	return nil
+}
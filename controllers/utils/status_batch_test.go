@@ -0,0 +1,72 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+)
+
+var _ = Describe("Status batch", func() {
+	var (
+		ctx       context.Context
+		k8sClient client.Client
+		tcp       *kamajiv1alpha1.TenantControlPlane
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		Expect(kamajiv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&kamajiv1alpha1.TenantControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		}).Build()
+
+		tcp = &kamajiv1alpha1.TenantControlPlane{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "test", Namespace: "default"}, tcp)).To(Succeed())
+	})
+
+	It("should persist the staged conditions with a single update", func() {
+		batch := &StatusBatch{}
+		batch.StageCondition(tcp, metav1.Condition{Type: "Paused", Status: metav1.ConditionFalse, Reason: "Reconcile"})
+		batch.StageCondition(tcp, metav1.Condition{Type: "DataStoreAvailable", Status: metav1.ConditionTrue, Reason: "Available"})
+
+		Expect(batch.Flush(ctx, k8sClient, tcp)).To(Succeed())
+
+		persisted := &kamajiv1alpha1.TenantControlPlane{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(tcp), persisted)).To(Succeed())
+		Expect(meta.IsStatusConditionFalse(persisted.Status.Conditions, "Paused")).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(persisted.Status.Conditions, "DataStoreAvailable")).To(BeTrue())
+		// The batch is emptied once persisted.
+		Expect(batch.Flush(ctx, k8sClient, tcp)).To(Succeed())
+		Expect(tcp.GetResourceVersion()).To(Equal(persisted.GetResourceVersion()))
+	})
+
+	It("should apply the staged conditions again upon a conflict, preserving the concurrent changes", func() {
+		concurrent := tcp.DeepCopy()
+		concurrent.Status.Kubernetes.Version.Version = "v1.26.0"
+		Expect(k8sClient.Status().Update(ctx, concurrent)).To(Succeed())
+
+		batch := &StatusBatch{}
+		batch.StageCondition(tcp, metav1.Condition{Type: "DataStoreAvailable", Status: metav1.ConditionFalse, Reason: "QuotaExceeded"})
+
+		Expect(batch.Flush(ctx, k8sClient, tcp)).To(Succeed())
+
+		persisted := &kamajiv1alpha1.TenantControlPlane{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(tcp), persisted)).To(Succeed())
+		Expect(meta.FindStatusCondition(persisted.Status.Conditions, "DataStoreAvailable")).To(HaveField("Reason", "QuotaExceeded"))
+		Expect(persisted.Status.Kubernetes.Version.Version).To(Equal("v1.26.0"))
+	})
+})
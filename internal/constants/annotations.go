@@ -7,4 +7,35 @@ const (
 	// Checksum is the annotation label that we use to store the checksum for the resource:
 	// it allows to check by comparing it if the resource has been changed and must be aligned with the reconciliation.
 	Checksum = "kamaji.clastix.io/checksum"
+	// PausedReconciliation is the annotation used to freeze the reconciliation of the datastore and addon resources
+	// for a given Tenant Control Plane, such as during maintenance: the value is ignored.
+	PausedReconciliation = "kamaji.clastix.io/paused"
+	// DisableDataStoreUser is the annotation used to cut a Tenant Control Plane off from its datastore during an incident,
+	// revoking the privileges and disabling the login of its user: the value is ignored.
+	DisableDataStoreUser = "kamaji.clastix.io/disable-datastore-user"
+	// LogDataStoreStatements is the annotation used to log the statements performed against the SQL DataStores
+	// for a given Tenant Control Plane, regardless of the verbosity: the value is ignored.
+	LogDataStoreStatements = "kamaji.clastix.io/log-datastore-statements"
+	// ForceAddonsResync is the annotation used to re-apply in place the addons of a Tenant Control Plane,
+	// overwriting the manual changes to the fields set by Kamaji: the value is the comma-separated list of the addons,
+	// such as coredns,kube-proxy, and each addon is removed from the list once resynced.
+	ForceAddonsResync = "kamaji.clastix.io/force-addons-resync"
+	// DataStoreGrantOption is the annotation used to override for a given Tenant Control Plane the DataStore setting
+	// granting the privileges WITH GRANT OPTION: the value must be true or false.
+	DataStoreGrantOption = "kamaji.clastix.io/datastore-grant-option"
+	// DataStoreTablespace is the annotation used to override for a given Tenant Control Plane the DataStore tablespace
+	// its database is created in: the value is the tablespace name.
+	DataStoreTablespace = "kamaji.clastix.io/datastore-tablespace"
+	// AdoptDataStore is the annotation used to take over the datastore schema, user, and privileges already existing
+	// for a given Tenant Control Plane, such as upon its import, ensuring them with no data loss: the value is ignored.
+	AdoptDataStore = "kamaji.clastix.io/adopt-datastore"
+	// PauseDataStoreMigration is the annotation used to hold the migration of a Tenant Control Plane to a different
+	// DataStore, before copying the data, or before switching to the target one: the value is ignored.
+	PauseDataStoreMigration = "kamaji.clastix.io/pause-datastore-migration"
+	// CleanUpDataStoreMigration is the annotation used to purge the data of a Tenant Control Plane from the source
+	// DataStore once migrated, according to its retention policy: the value is ignored.
+	CleanUpDataStoreMigration = "kamaji.clastix.io/cleanup-datastore-migration"
+	// RenameDataStoreSchema is the annotation used to rename in place the datastore schema of a given Tenant Control Plane,
+	// preserving its data, such as to correct a typo: the value is the new schema name, supported by PostgreSQL only.
+	RenameDataStoreSchema = "kamaji.clastix.io/rename-datastore-schema"
 )
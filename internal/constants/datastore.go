@@ -0,0 +1,12 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package constants
+
+const (
+	// DataStoreCAKey is the key of the datastore certificate Secret storing the DataStore Certificate Authority.
+	DataStoreCAKey = "ca.crt"
+	// DataStoreServerCAKey is the key of the datastore certificate Secret storing the bundle verifying the data store
+	// certificate, set only when the DataStore server CA bundle is specified.
+	DataStoreServerCAKey = "server-ca.crt"
+)
@@ -3,12 +3,27 @@
 
 package errors
 
+import (
+	"fmt"
+	"time"
+)
+
 type MigrationInProcessError struct{}
 
 func (n MigrationInProcessError) Error() string {
 	return "cannot continue reconciliation, the current TenantControlPlane is still in migration status"
 }
 
+// DeletionGracePeriodError is returned when the deletion of the Tenant Control Plane data is pending,
+// waiting for the DataStore deletion grace period to elapse.
+type DeletionGracePeriodError struct {
+	RequeueAfter time.Duration
+}
+
+func (d DeletionGracePeriodError) Error() string {
+	return fmt.Sprintf("the deletion of the TenantControlPlane data is pending, waiting %s for the grace period to elapse", d.RequeueAfter.String())
+}
+
 type NonExposedLoadBalancerError struct{}
 
 func (n NonExposedLoadBalancerError) Error() string {
@@ -20,3 +35,18 @@ type MissingValidIPError struct{}
 func (m MissingValidIPError) Error() string {
 	return "the actual resource doesn't have yet a valid IP address"
 }
+
+// AddonForbiddenError is returned when the tenant API server refuses the addon resources, such as upon a misconfigured
+// RBAC: retrying shortly is pointless until the RBAC is amended.
+type AddonForbiddenError struct {
+	Addon string
+	Err   error
+}
+
+func (a AddonForbiddenError) Error() string {
+	return fmt.Sprintf("the tenant API server refused the %s addon resources, check the RBAC of the tenant cluster: %s", a.Addon, a.Err.Error())
+}
+
+func (a AddonForbiddenError) Unwrap() error {
+	return a.Err
+}
@@ -3,7 +3,11 @@
 
 package errors
 
-import "github.com/pkg/errors"
+import (
+	"github.com/pkg/errors"
+
+	datastoreerrors "github.com/clastix/kamaji/pkg/datastore/errors"
+)
 
 func ShouldReconcileErrorBeIgnored(err error) bool {
 	switch {
@@ -13,6 +17,12 @@ func ShouldReconcileErrorBeIgnored(err error) bool {
 		return true
 	case errors.As(err, &MigrationInProcessError{}):
 		return true
+	case errors.As(err, &datastoreerrors.StatementTimeoutError{}):
+		return true
+	case errors.As(err, &datastoreerrors.NotReadyError{}):
+		return true
+	case errors.As(err, &datastoreerrors.LockedError{}):
+		return true
 	default:
 		return false
 	}
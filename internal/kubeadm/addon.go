@@ -5,9 +5,14 @@ package kubeadm
 
 import (
 	"bytes"
+	"fmt"
 
 	"k8s.io/client-go/kubernetes"
+	kubeproxyconfig "k8s.io/kube-proxy/config/v1alpha1"
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	"k8s.io/kubernetes/cmd/kubeadm/app/componentconfigs"
 	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	"k8s.io/kubernetes/cmd/kubeadm/app/images"
 	"k8s.io/kubernetes/cmd/kubeadm/app/phases/addons/dns"
 	"k8s.io/kubernetes/cmd/kubeadm/app/phases/addons/proxy"
 )
@@ -44,15 +49,54 @@ func AddCoreDNS(client kubernetes.Interface, config *Configuration) ([]byte, err
 }
 
 func AddKubeProxy(client kubernetes.Interface, config *Configuration) ([]byte, error) {
-	// This is a workaround since the function EnsureProxyAddon is picking repository and tag from the InitConfiguration
+	// This is a workaround since the function EnsureProxyAddon is picking the repository from the InitConfiguration
 	// struct, although is counterintuitive
 	config.InitConfiguration.ClusterConfiguration.CIImageRepository = config.Parameters.KubeProxyOptions.Repository
-	config.InitConfiguration.KubernetesVersion = config.Parameters.KubeProxyOptions.Tag
+
+	if err := setKubeProxyConntrack(&config.InitConfiguration.ClusterConfiguration, config.Parameters.KubeProxyConntrackOptions); err != nil {
+		return nil, err
+	}
 
 	b := bytes.NewBuffer([]byte{})
 	if err := proxy.EnsureProxyAddon(&config.InitConfiguration.ClusterConfiguration, &config.InitConfiguration.LocalAPIEndpoint, client, b, true); err != nil {
 		return nil, err
 	}
+	// The manifests are rendered for the Tenant Control Plane Kubernetes version, while the image tag is derived
+	// from it by kubeadm: replacing it with the one from the addon options, such as a custom build.
+	rendered := images.GetKubernetesImage(constants.KubeProxy, &config.InitConfiguration.ClusterConfiguration)
+	desired := images.GetGenericImage(config.Parameters.KubeProxyOptions.Repository, constants.KubeProxy, config.Parameters.KubeProxyOptions.Tag)
 
-	return b.Bytes(), nil
+	return bytes.ReplaceAll(b.Bytes(), []byte(rendered), []byte(desired)), nil
+}
+
+// setKubeProxyConntrack overrides the conntrack settings of the kube-proxy component config,
+// which is rendered by kubeadm in the kube-proxy ConfigMap.
+func setKubeProxyConntrack(config *kubeadmapi.ClusterConfiguration, opts *KubeProxyConntrackOptions) error {
+	if opts == nil {
+		return nil
+	}
+
+	componentConfig, ok := config.ComponentConfigs[componentconfigs.KubeProxyGroup]
+	if !ok {
+		return fmt.Errorf("the kube-proxy component config is missing")
+	}
+
+	kubeProxyConfig, ok := componentConfig.Get().(*kubeproxyconfig.KubeProxyConfiguration)
+	if !ok {
+		return fmt.Errorf("unexpected kube-proxy component config type %T", componentConfig.Get())
+	}
+
+	if opts.MaxPerCore != nil {
+		kubeProxyConfig.Conntrack.MaxPerCore = opts.MaxPerCore
+	}
+
+	if opts.Min != nil {
+		kubeProxyConfig.Conntrack.Min = opts.Min
+	}
+
+	if opts.TCPEstablishedTimeout != nil {
+		kubeProxyConfig.Conntrack.TCPEstablishedTimeout = opts.TCPEstablishedTimeout
+	}
+
+	return nil
 }
@@ -18,6 +18,8 @@ const (
 	defaultCAFile   = "/etc/kubernetes/pki/etcd/ca.crt"
 	defaultCertFile = "/etc/kubernetes/pki/apiserver-etcd-client.crt"
 	defaultKeyFile  = "/etc/kubernetes/pki/apiserver-etcd-client.key"
+	// defaultClusterDomain is used for the Tenant Control Planes created before the cluster domain customization.
+	defaultClusterDomain = "cluster.local"
 )
 
 func CreateKubeadmInitConfiguration(params Parameters) (*Configuration, error) {
@@ -49,8 +51,13 @@ func CreateKubeadmInitConfiguration(params Parameters) (*Configuration, error) {
 			KeyFile:   keyFile,
 		},
 	}
+	dnsDomain := params.TenantControlPlaneClusterDomain
+	if len(dnsDomain) == 0 {
+		dnsDomain = defaultClusterDomain
+	}
+
 	conf.Networking = kubeadmapi.Networking{
-		DNSDomain:     "cluster.local",
+		DNSDomain:     dnsDomain,
 		PodSubnet:     params.TenantControlPlanePodCIDR,
 		ServiceSubnet: params.TenantControlPlaneServiceCIDR,
 	}
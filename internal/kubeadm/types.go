@@ -5,6 +5,7 @@ package kubeadm
 
 import (
 	json "github.com/json-iterator/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientcmdapiv1 "k8s.io/client-go/tools/clientcmd/api/v1"
 	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
 
@@ -32,22 +33,24 @@ func (c *Configuration) Checksum() string {
 }
 
 type Parameters struct {
-	TenantControlPlaneName         string
-	TenantControlPlaneNamespace    string
-	TenantControlPlaneEndpoint     string
-	TenantControlPlaneAddress      string
-	TenantControlPlaneCertSANs     []string
-	TenantControlPlanePort         int32
-	TenantControlPlanePodCIDR      string
-	TenantControlPlaneServiceCIDR  string
-	TenantDNSServiceIPs            []string
-	TenantControlPlaneVersion      string
-	TenantControlPlaneCGroupDriver string
-	ETCDs                          []string
-	CertificatesDir                string
-	KubeconfigDir                  string
-	KubeProxyOptions               *AddonOptions
-	CoreDNSOptions                 *AddonOptions
+	TenantControlPlaneName          string
+	TenantControlPlaneNamespace     string
+	TenantControlPlaneEndpoint      string
+	TenantControlPlaneAddress       string
+	TenantControlPlaneCertSANs      []string
+	TenantControlPlanePort          int32
+	TenantControlPlanePodCIDR       string
+	TenantControlPlaneServiceCIDR   string
+	TenantDNSServiceIPs             []string
+	TenantControlPlaneClusterDomain string
+	TenantControlPlaneVersion       string
+	TenantControlPlaneCGroupDriver  string
+	ETCDs                           []string
+	CertificatesDir                 string
+	KubeconfigDir                   string
+	KubeProxyOptions                *AddonOptions
+	KubeProxyConntrackOptions       *KubeProxyConntrackOptions
+	CoreDNSOptions                  *AddonOptions
 }
 
 type AddonOptions struct {
@@ -55,6 +58,13 @@ type AddonOptions struct {
 	Tag        string
 }
 
+// KubeProxyConntrackOptions are the conntrack settings overriding the kube-proxy defaults, when set.
+type KubeProxyConntrackOptions struct {
+	MaxPerCore            *int32
+	Min                   *int32
+	TCPEstablishedTimeout *metav1.Duration
+}
+
 type KubeletConfiguration struct {
 	TenantControlPlaneDomain        string
 	TenantControlPlaneDNSServiceIPs []string
@@ -0,0 +1,24 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metrics provides the Kamaji metrics, served along with the controller-runtime ones.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// DeletionBlockedDataStoreReason is the reason of the deletions blocked by the failing datastore clean-up.
+const DeletionBlockedDataStoreReason = "datastore"
+
+// TenantControlPlaneDeletionBlocked reports the Tenant Control Planes whose deletion is blocked, since their finalizer
+// can't be removed, along with the reason: it allows alerting on the wedged deletions.
+var TenantControlPlaneDeletionBlocked = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "kamaji_tcp_deletion_blocked",
+	Help: "Reports with value 1 the Tenant Control Planes whose deletion is blocked, along with the reason.",
+}, []string{"namespace", "name", "reason"})
+
+func init() {
+	metrics.Registry.MustRegister(TenantControlPlaneDeletionBlocked)
+}
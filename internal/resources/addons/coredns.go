@@ -6,24 +6,52 @@ package addons
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"regexp"
 
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientset "k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/internal/constants"
 	"github.com/clastix/kamaji/internal/kubeadm"
 	"github.com/clastix/kamaji/internal/resources"
 	"github.com/clastix/kamaji/internal/resources/utils"
 	"github.com/clastix/kamaji/internal/utilities"
 )
 
+const coreDNSCorefileKey = "Corefile"
+
+const (
+	coreDNSMetricsPortName          = "metrics"
+	coreDNSPrometheusPortAnnotation = "prometheus.io/port"
+	coreDNSPrometheusAnnotation     = "prometheus.io/scrape"
+)
+
+var (
+	// corefileCacheRegexp matches the cache plugin directive of the Corefile rendered by kubeadm.
+	corefileCacheRegexp = regexp.MustCompile(`(?m)^([ \t]*)cache [0-9]+\n`)
+	// corefileHealthRegexp matches the health plugin block, capturing its opening line.
+	corefileHealthRegexp = regexp.MustCompile(`(?m)^([ \t]*)health(?: :[0-9]+)? \{\n(?:.*\n)*?[ \t]*\}\n`)
+	corefileHealthHeader = regexp.MustCompile(`(?m)^([ \t]*)health(?: :[0-9]+)? \{`)
+	// corefileReadyRegexp matches the ready plugin directive.
+	corefileReadyRegexp = regexp.MustCompile(`(?m)^([ \t]*)ready(?: :[0-9]+)?\n`)
+	// corefilePrometheusRegexp matches the prometheus plugin directive.
+	corefilePrometheusRegexp = regexp.MustCompile(`(?m)^([ \t]*)prometheus :[0-9]+\n`)
+	// corefileErrorsRegexp matches the errors plugin directive, followed by the log one, if any.
+	corefileErrorsRegexp = regexp.MustCompile(`(?m)^([ \t]*)errors\n(?:[ \t]*log\n)?`)
+)
+
 type CoreDNS struct {
 	Client client.Client
 
@@ -33,9 +61,16 @@ type CoreDNS struct {
 	clusterRole        *rbacv1.ClusterRole
 	clusterRoleBinding *rbacv1.ClusterRoleBinding
 	serviceAccount     *corev1.ServiceAccount
+	// podDisruptionBudget is nil when not requested, and removed if existing.
+	podDisruptionBudget *policyv1.PodDisruptionBudget
+	// autoscaler resources are rendered when requested only, and removed if existing otherwise:
+	// the CoreDNS replicas are left to the autoscaler when rendered.
+	autoscaler  coreDNSAutoscaler
+	autoscaling bool
+	patches     []kamajiv1alpha1.AddonPatch
 }
 
-func (c *CoreDNS) Define(context.Context, *kamajiv1alpha1.TenantControlPlane) error {
+func (c *CoreDNS) Define(_ context.Context, tcp *kamajiv1alpha1.TenantControlPlane) error {
 	c.deployment = &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      kubeadm.CoreDNSName,
@@ -70,6 +105,16 @@ func (c *CoreDNS) Define(context.Context, *kamajiv1alpha1.TenantControlPlane) er
 			Namespace: kubeadm.KubeSystemNamespace,
 		},
 	}
+	c.autoscaler = defineCoreDNSAutoscaler()
+	if tcp.Spec.Addons.CoreDNS != nil {
+		c.serviceAccount.SetName(tcp.Spec.Addons.CoreDNS.GetServiceAccountName(kubeadm.CoreDNSName))
+	}
+	c.podDisruptionBudget = &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kubeadm.CoreDNSName,
+			Namespace: kubeadm.KubeSystemNamespace,
+		},
+	}
 
 	return nil
 }
@@ -78,35 +123,81 @@ func (c *CoreDNS) ShouldCleanup(tcp *kamajiv1alpha1.TenantControlPlane) bool {
 	return tcp.Spec.Addons.CoreDNS == nil
 }
 
-func (c *CoreDNS) CleanUp(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) (bool, error) {
+func (c *CoreDNS) CleanUp(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) (resources.CleanUpResult, error) {
 	logger := log.FromContext(ctx, "resource", "kubeadm_addons", "addon", c.GetName())
 
+	if utilities.IsPaused(tcp) {
+		logger.Info("Tenant Control Plane is paused, skipping clean-up")
+
+		return resources.CleanUpResultNone, nil
+	}
+
+	if isTearingDown(tcp) {
+		logger.V(1).Info("Tenant Control Plane is marked for deletion, skipping clean-up")
+
+		return resources.CleanUpResultNone, nil
+	}
+
 	tenantClient, err := utilities.GetTenantClient(ctx, c.Client, tcp)
 	if err != nil {
 		logger.Error(err, "cannot generate Tenant client")
 
-		return false, err
+		return resources.CleanUpResultNone, err
 	}
 
-	var deleted bool
+	result := resources.CleanUpResultNone
+
+	objects := append([]client.Object{c.clusterRoleBinding, c.clusterRole, c.service, c.configMap, c.deployment, c.podDisruptionBudget}, c.autoscaler.objects()...)
+	// The ServiceAccount provided by the tenant cluster administrators is left in place.
+	managed, err := c.isServiceAccountManaged(ctx, tenantClient)
+	if err != nil {
+		return resources.CleanUpResultNone, err
+	}
 
-	for _, obj := range []client.Object{c.serviceAccount, c.clusterRoleBinding, c.clusterRole, c.service, c.configMap, c.deployment} {
+	if managed {
+		objects = append([]client.Object{c.serviceAccount}, objects...)
+	}
+
+	for _, obj := range objects {
 		if err = tenantClient.Delete(ctx, obj); err != nil {
 			if k8serrors.IsNotFound(err) {
 				continue
 			}
 
-			return false, err
+			return resources.CleanUpResultNone, err
 		}
-		deleted = deleted || err == nil
+
+		result = resources.CleanUpResultCompleted
 	}
 
-	return deleted, nil
+	return result, nil
 }
 
-func (c *CoreDNS) CreateOrUpdate(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error) {
+func (c *CoreDNS) CreateOrUpdate(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) (_ controllerutil.OperationResult, err error) {
 	logger := log.FromContext(ctx, "addon", c.GetName())
 
+	defer func() {
+		err = forbiddenError(c.GetName(), err)
+	}()
+
+	if utilities.IsPaused(tcp) {
+		logger.Info("Tenant Control Plane is paused, skipping reconciliation")
+
+		return controllerutil.OperationResultNone, nil
+	}
+
+	if isTearingDown(tcp) {
+		logger.V(1).Info("Tenant Control Plane is marked for deletion, skipping reconciliation")
+
+		return controllerutil.OperationResultNone, nil
+	}
+
+	if !isDataStoreReady(tcp) {
+		logger.V(1).Info("datastore setup not completed yet, enqueuing back")
+
+		return resources.OperationResultEnqueueBack, nil
+	}
+
 	tenantClient, err := utilities.GetTenantClient(ctx, c.Client, tcp)
 	if err != nil {
 		logger.Error(err, "cannot generate Tenant client")
@@ -119,6 +210,14 @@ func (c *CoreDNS) CreateOrUpdate(ctx context.Context, tcp *kamajiv1alpha1.Tenant
 
 		return controllerutil.OperationResultNone, err
 	}
+	// Re-applying the resources in place, overwriting the fields changed by other managers:
+	// the resync is idempotent, thus it's repeated until the annotation is cleared.
+	forceResync := shouldForceResync(tcp, c.GetName())
+	if forceResync {
+		logger.Info("forcing the addon resync")
+
+		ctx = utilities.WithForceOwnership(ctx)
+	}
 
 	var operationResult controllerutil.OperationResult
 
@@ -171,6 +270,38 @@ func (c *CoreDNS) CreateOrUpdate(ctx context.Context, tcp *kamajiv1alpha1.Tenant
 		return controllerutil.OperationResultNone, err
 	}
 	reconciliationResult = utils.UpdateOperationResult(reconciliationResult, operationResult)
+	// Previous ServiceAccounts, upon the name changes
+	operationResult, err = c.pruneServiceAccounts(ctx, tenantClient)
+	if err != nil {
+		logger.Error(err, "ServiceAccount pruning failed")
+
+		return controllerutil.OperationResultNone, err
+	}
+	reconciliationResult = utils.UpdateOperationResult(reconciliationResult, operationResult)
+	// PodDisruptionBudget
+	operationResult, err = c.mutatePodDisruptionBudget(ctx, tenantClient, tcp.Spec.Addons.CoreDNS.PodDisruptionBudget)
+	if err != nil {
+		logger.Error(err, "PodDisruptionBudget reconciliation failed")
+
+		return controllerutil.OperationResultNone, err
+	}
+	reconciliationResult = utils.UpdateOperationResult(reconciliationResult, operationResult)
+	// Autoscaler
+	operationResult, err = c.mutateAutoscaler(ctx, tenantClient, tcp.Spec.Addons.CoreDNS.Autoscaler)
+	if err != nil {
+		logger.Error(err, "autoscaler reconciliation failed")
+
+		return controllerutil.OperationResultNone, err
+	}
+	reconciliationResult = utils.UpdateOperationResult(reconciliationResult, operationResult)
+
+	if forceResync {
+		if err = clearForceResync(ctx, c.Client, tcp, c.GetName()); err != nil {
+			logger.Error(err, "cannot clear the addon resync annotation")
+
+			return controllerutil.OperationResultNone, err
+		}
+	}
 
 	return reconciliationResult, nil
 }
@@ -179,6 +310,16 @@ func (c *CoreDNS) GetName() string {
 	return "coredns"
 }
 
+// Validate executes the validations registered for the addon against the tenant cluster, once applied:
+// it must succeed before updating the Tenant Control Plane status.
+func (c *CoreDNS) Validate(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) error {
+	return validate(ctx, c.GetName(), tcp, func() (clientset.Interface, error) {
+		tcpClient, _, err := c.kubeadmDeps(ctx, tcp)
+
+		return tcpClient, err
+	})
+}
+
 func (c *CoreDNS) ShouldStatusBeUpdated(_ context.Context, tcp *kamajiv1alpha1.TenantControlPlane) bool {
 	return tcp.Spec.Addons.CoreDNS != nil && !tcp.Status.Addons.CoreDNS.Enabled
 }
@@ -190,10 +331,18 @@ func (c *CoreDNS) UpdateTenantControlPlaneStatus(_ context.Context, tcp *kamajiv
 	return nil
 }
 
-func (c *CoreDNS) decodeManifests(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) error {
+// KubeadmConfiguration returns the kubeadm configuration used to render the CoreDNS manifests
+// for the given Tenant Control Plane, allowing to inspect it with no changes against the tenant cluster.
+func (c *CoreDNS) KubeadmConfiguration(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) (*kubeadm.Configuration, error) {
+	_, config, err := c.kubeadmDeps(ctx, tcp)
+
+	return config, err
+}
+
+func (c *CoreDNS) kubeadmDeps(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) (*clientset.Clientset, *kubeadm.Configuration, error) {
 	tcpClient, config, err := resources.GetKubeadmManifestDeps(ctx, c.Client, tcp)
 	if err != nil {
-		return errors.Wrap(err, "unable to create manifests dependencies")
+		return nil, nil, errors.Wrap(err, "unable to create manifests dependencies")
 	}
 
 	// If CoreDNS addon is enabled and with an override, adding these to the kubeadm init configuration
@@ -203,10 +352,21 @@ func (c *CoreDNS) decodeManifests(ctx context.Context, tcp *kamajiv1alpha1.Tenan
 		config.Parameters.CoreDNSOptions.Repository = tcp.Spec.Addons.CoreDNS.ImageRepository
 	}
 
-	if len(tcp.Spec.Addons.CoreDNS.ImageRepository) > 0 {
+	if len(tcp.Spec.Addons.CoreDNS.ImageTag) > 0 {
 		config.Parameters.CoreDNSOptions.Tag = tcp.Spec.Addons.CoreDNS.ImageTag
 	}
 
+	return tcpClient, config, nil
+}
+
+func (c *CoreDNS) decodeManifests(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) error {
+	tcpClient, config, err := c.kubeadmDeps(ctx, tcp)
+	if err != nil {
+		return err
+	}
+
+	c.patches = tcp.Spec.Addons.CoreDNS.Patches
+
 	manifests, err := kubeadm.AddCoreDNS(tcpClient, config)
 	if err != nil {
 		return errors.Wrap(err, "unable to generate manifests")
@@ -222,6 +382,10 @@ func (c *CoreDNS) decodeManifests(ctx context.Context, tcp *kamajiv1alpha1.Tenan
 		return errors.Wrap(err, "unable to decode ConfigMap manifest")
 	}
 
+	c.configMap.Data[coreDNSCorefileKey] = corefileCache(c.configMap.Data[coreDNSCorefileKey], tcp.Spec.Addons.CoreDNS.Cache)
+	c.configMap.Data[coreDNSCorefileKey] = corefilePlugins(c.configMap.Data[coreDNSCorefileKey], tcp.Spec.Addons.CoreDNS)
+	utilities.SetObjectChecksum(c.configMap, versionedChecksumData(c.configMap.Data, tcp.Spec.Kubernetes.Version))
+
 	if err = utilities.DecodeFromYAML(string(parts[3]), c.service); err != nil {
 		return errors.Wrap(err, "unable to decode Service manifest")
 	}
@@ -238,9 +402,137 @@ func (c *CoreDNS) decodeManifests(ctx context.Context, tcp *kamajiv1alpha1.Tenan
 		return errors.Wrap(err, "unable to decode ServiceAccount manifest")
 	}
 
+	c.setPlugins(tcp.Spec.Addons.CoreDNS)
+	c.setServiceAccount(tcp.Spec.Addons.CoreDNS)
+
+	if pdb := tcp.Spec.Addons.CoreDNS.PodDisruptionBudget; pdb != nil {
+		minAvailable := intstr.FromInt(1)
+		if pdb.MinAvailable != nil {
+			minAvailable = *pdb.MinAvailable
+		}
+
+		c.podDisruptionBudget.SetLabels(c.deployment.GetLabels())
+		c.podDisruptionBudget.Spec = policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector:     c.deployment.Spec.Selector,
+		}
+		utilities.SetObjectChecksum(c.podDisruptionBudget, c.podDisruptionBudget.Spec)
+	}
+
+	if autoscaler := tcp.Spec.Addons.CoreDNS.Autoscaler; autoscaler != nil {
+		if err = c.decodeAutoscaler(autoscaler); err != nil {
+			return err
+		}
+
+		c.autoscaling = true
+	}
+
 	return nil
 }
 
+// setPlugins aligns the Deployment probes, and the Service metrics port, to the configured plugins.
+func (c *CoreDNS) setPlugins(spec *kamajiv1alpha1.CoreDNSAddonSpec) {
+	container := &c.deployment.Spec.Template.Spec.Containers[0]
+
+	if spec.Health != nil {
+		switch {
+		case !spec.Health.IsEnabled():
+			container.LivenessProbe = nil
+		case container.LivenessProbe != nil && container.LivenessProbe.HTTPGet != nil:
+			container.LivenessProbe.HTTPGet.Port = intstr.FromInt(int(spec.Health.GetPort(kamajiv1alpha1.CoreDNSHealthDefaultPort)))
+		}
+	}
+
+	if spec.Ready != nil {
+		switch {
+		case !spec.Ready.IsEnabled():
+			container.ReadinessProbe = nil
+		case container.ReadinessProbe != nil && container.ReadinessProbe.HTTPGet != nil:
+			container.ReadinessProbe.HTTPGet.Port = intstr.FromInt(int(spec.Ready.GetPort(kamajiv1alpha1.CoreDNSReadyDefaultPort)))
+		}
+	}
+
+	if spec.Metrics == nil {
+		return
+	}
+
+	port := spec.Metrics.GetPort(kamajiv1alpha1.CoreDNSMetricsDefaultPort)
+
+	containerPorts := make([]corev1.ContainerPort, 0, len(container.Ports))
+
+	for _, containerPort := range container.Ports {
+		if containerPort.Name == coreDNSMetricsPortName {
+			if !spec.Metrics.IsEnabled() {
+				continue
+			}
+
+			containerPort.ContainerPort = port
+		}
+
+		containerPorts = append(containerPorts, containerPort)
+	}
+
+	container.Ports = containerPorts
+
+	servicePorts := make([]corev1.ServicePort, 0, len(c.service.Spec.Ports))
+
+	for _, servicePort := range c.service.Spec.Ports {
+		if servicePort.Name == coreDNSMetricsPortName {
+			if !spec.Metrics.IsEnabled() {
+				continue
+			}
+
+			servicePort.Port, servicePort.TargetPort = port, intstr.FromInt(int(port))
+		}
+
+		servicePorts = append(servicePorts, servicePort)
+	}
+
+	c.service.Spec.Ports = servicePorts
+
+	annotations := map[string]string{coreDNSPrometheusAnnotation: "false"}
+	if spec.Metrics.IsEnabled() {
+		annotations = map[string]string{coreDNSPrometheusAnnotation: "true", coreDNSPrometheusPortAnnotation: fmt.Sprintf("%d", port)}
+	}
+
+	c.service.SetAnnotations(utilities.MergeMaps(c.service.GetAnnotations(), annotations))
+}
+
+// setServiceAccount renames the ServiceAccount, along with its references by the Deployment and the ClusterRoleBinding,
+// and adds the configured annotations: the Pod template checksum covers the ServiceAccount too, since the workload
+// identity webhooks are mutating the Pods upon their creation only, according to the ServiceAccount annotations.
+func (c *CoreDNS) setServiceAccount(spec *kamajiv1alpha1.CoreDNSAddonSpec) {
+	checksum := utilities.GetObjectChecksum(c.configMap)
+
+	defer func() {
+		c.deployment.Spec.Template.SetAnnotations(map[string]string{constants.Checksum: checksum})
+	}()
+
+	if spec.ServiceAccount == nil {
+		return
+	}
+
+	name := spec.GetServiceAccountName(kubeadm.CoreDNSName)
+
+	c.serviceAccount.SetName(name)
+	c.serviceAccount.SetAnnotations(utilities.MergeMaps(c.serviceAccount.GetAnnotations(), spec.ServiceAccount.Annotations))
+
+	c.deployment.Spec.Template.Spec.ServiceAccountName = name
+
+	for i, subject := range c.clusterRoleBinding.Subjects {
+		if subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace == kubeadm.KubeSystemNamespace && subject.Name == kubeadm.CoreDNSName {
+			c.clusterRoleBinding.Subjects[i].Name = name
+		}
+	}
+
+	data := map[string]string{"corefile": checksum, "serviceAccount": name}
+	for key, value := range spec.ServiceAccount.Annotations {
+		data["annotation/"+key] = value
+	}
+
+	checksum = utilities.CalculateMapChecksum(data)
+}
+
 func (c *CoreDNS) mutateClusterRoleBinding(ctx context.Context, tenantClient client.Client) (controllerutil.OperationResult, error) {
 	crb := &rbacv1.ClusterRoleBinding{}
 	crb.SetName(c.clusterRoleBinding.GetName())
@@ -249,7 +541,7 @@ func (c *CoreDNS) mutateClusterRoleBinding(ctx context.Context, tenantClient cli
 		c.clusterRoleBinding.SetUID(crb.GetUID())
 	}()
 
-	return utilities.CreateOrUpdateWithConflict(ctx, tenantClient, crb, func() error {
+	return utilities.ServerSideApply(ctx, tenantClient, crb, func() error {
 		crb.SetLabels(c.clusterRoleBinding.GetLabels())
 		crb.SetAnnotations(c.clusterRoleBinding.GetAnnotations())
 		crb.Subjects = c.clusterRoleBinding.Subjects
@@ -264,12 +556,20 @@ func (c *CoreDNS) mutateDeployment(ctx context.Context, tenantClient client.Clie
 	d.SetName(c.deployment.GetName())
 	d.SetNamespace(c.deployment.GetNamespace())
 
-	return utilities.CreateOrUpdateWithConflict(ctx, tenantClient, d, func() error {
+	return utilities.ServerSideApply(ctx, tenantClient, d, func() error {
 		d.SetLabels(c.deployment.GetLabels())
-		d.SetAnnotations(utilities.MergeMaps(d.GetAnnotations(), c.deployment.GetAnnotations()))
-		d.Spec.Replicas = c.deployment.Spec.Replicas
+		d.SetAnnotations(c.deployment.GetAnnotations())
+		// The replicas are not declared when autoscaled, not to conflict with the autoscaler.
+		if !c.autoscaling {
+			d.Spec.Replicas = c.deployment.Spec.Replicas
+		}
 		d.Spec.Selector = c.deployment.Spec.Selector
 		d.Spec.Template.ObjectMeta.SetLabels(c.deployment.Spec.Template.ObjectMeta.GetLabels())
+		// Rolling out the CoreDNS Pods upon the Corefile changes, such as the query logging toggle,
+		// and upon the ServiceAccount ones.
+		d.Spec.Template.ObjectMeta.SetAnnotations(map[string]string{
+			constants.Checksum: c.deployment.Spec.Template.GetAnnotations()[constants.Checksum],
+		})
 		if len(d.Spec.Template.Spec.Volumes) != 1 {
 			d.Spec.Template.Spec.Volumes = make([]corev1.Volume, 1)
 		}
@@ -289,18 +589,14 @@ func (c *CoreDNS) mutateDeployment(ctx context.Context, tenantClient client.Clie
 		d.Spec.Template.Spec.Containers[0].Name = c.deployment.Spec.Template.Spec.Containers[0].Name
 		d.Spec.Template.Spec.Containers[0].Image = c.deployment.Spec.Template.Spec.Containers[0].Image
 		d.Spec.Template.Spec.Containers[0].Args = c.deployment.Spec.Template.Spec.Containers[0].Args
-		if len(d.Spec.Template.Spec.Containers[0].Ports) != 3 {
-			d.Spec.Template.Spec.Containers[0].Ports = make([]corev1.ContainerPort, 3)
+		if len(d.Spec.Template.Spec.Containers[0].Ports) != len(c.deployment.Spec.Template.Spec.Containers[0].Ports) {
+			d.Spec.Template.Spec.Containers[0].Ports = make([]corev1.ContainerPort, len(c.deployment.Spec.Template.Spec.Containers[0].Ports))
+		}
+		for i, port := range c.deployment.Spec.Template.Spec.Containers[0].Ports {
+			d.Spec.Template.Spec.Containers[0].Ports[i].Name = port.Name
+			d.Spec.Template.Spec.Containers[0].Ports[i].ContainerPort = port.ContainerPort
+			d.Spec.Template.Spec.Containers[0].Ports[i].Protocol = port.Protocol
 		}
-		d.Spec.Template.Spec.Containers[0].Ports[0].Name = c.deployment.Spec.Template.Spec.Containers[0].Ports[0].Name
-		d.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort = c.deployment.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort
-		d.Spec.Template.Spec.Containers[0].Ports[0].Protocol = c.deployment.Spec.Template.Spec.Containers[0].Ports[0].Protocol
-		d.Spec.Template.Spec.Containers[0].Ports[1].Name = c.deployment.Spec.Template.Spec.Containers[0].Ports[1].Name
-		d.Spec.Template.Spec.Containers[0].Ports[1].ContainerPort = c.deployment.Spec.Template.Spec.Containers[0].Ports[1].ContainerPort
-		d.Spec.Template.Spec.Containers[0].Ports[1].Protocol = c.deployment.Spec.Template.Spec.Containers[0].Ports[1].Protocol
-		d.Spec.Template.Spec.Containers[0].Ports[2].Name = c.deployment.Spec.Template.Spec.Containers[0].Ports[2].Name
-		d.Spec.Template.Spec.Containers[0].Ports[2].ContainerPort = c.deployment.Spec.Template.Spec.Containers[0].Ports[2].ContainerPort
-		d.Spec.Template.Spec.Containers[0].Ports[2].Protocol = c.deployment.Spec.Template.Spec.Containers[0].Ports[2].Protocol
 		d.Spec.Template.Spec.Containers[0].Resources = c.deployment.Spec.Template.Spec.Containers[0].Resources
 		if len(d.Spec.Template.Spec.Containers[0].VolumeMounts) == 0 {
 			d.Spec.Template.Spec.Containers[0].VolumeMounts = make([]corev1.VolumeMount, 1)
@@ -308,18 +604,26 @@ func (c *CoreDNS) mutateDeployment(ctx context.Context, tenantClient client.Clie
 		d.Spec.Template.Spec.Containers[0].VolumeMounts[0].Name = c.deployment.Spec.Template.Spec.Containers[0].VolumeMounts[0].Name
 		d.Spec.Template.Spec.Containers[0].VolumeMounts[0].ReadOnly = c.deployment.Spec.Template.Spec.Containers[0].VolumeMounts[0].ReadOnly
 		d.Spec.Template.Spec.Containers[0].VolumeMounts[0].MountPath = c.deployment.Spec.Template.Spec.Containers[0].VolumeMounts[0].MountPath
-		if d.Spec.Template.Spec.Containers[0].LivenessProbe == nil {
-			d.Spec.Template.Spec.Containers[0].LivenessProbe = &corev1.Probe{}
+		if c.deployment.Spec.Template.Spec.Containers[0].LivenessProbe == nil {
+			d.Spec.Template.Spec.Containers[0].LivenessProbe = nil
+		} else {
+			if d.Spec.Template.Spec.Containers[0].LivenessProbe == nil {
+				d.Spec.Template.Spec.Containers[0].LivenessProbe = &corev1.Probe{}
+			}
+			d.Spec.Template.Spec.Containers[0].LivenessProbe.HTTPGet = c.deployment.Spec.Template.Spec.Containers[0].LivenessProbe.HTTPGet
+			d.Spec.Template.Spec.Containers[0].LivenessProbe.InitialDelaySeconds = c.deployment.Spec.Template.Spec.Containers[0].LivenessProbe.InitialDelaySeconds
+			d.Spec.Template.Spec.Containers[0].LivenessProbe.TimeoutSeconds = c.deployment.Spec.Template.Spec.Containers[0].LivenessProbe.TimeoutSeconds
+			d.Spec.Template.Spec.Containers[0].LivenessProbe.SuccessThreshold = c.deployment.Spec.Template.Spec.Containers[0].LivenessProbe.SuccessThreshold
+			d.Spec.Template.Spec.Containers[0].LivenessProbe.FailureThreshold = c.deployment.Spec.Template.Spec.Containers[0].LivenessProbe.FailureThreshold
 		}
-		d.Spec.Template.Spec.Containers[0].LivenessProbe.HTTPGet = c.deployment.Spec.Template.Spec.Containers[0].LivenessProbe.HTTPGet
-		d.Spec.Template.Spec.Containers[0].LivenessProbe.InitialDelaySeconds = c.deployment.Spec.Template.Spec.Containers[0].LivenessProbe.InitialDelaySeconds
-		d.Spec.Template.Spec.Containers[0].LivenessProbe.TimeoutSeconds = c.deployment.Spec.Template.Spec.Containers[0].LivenessProbe.TimeoutSeconds
-		d.Spec.Template.Spec.Containers[0].LivenessProbe.SuccessThreshold = c.deployment.Spec.Template.Spec.Containers[0].LivenessProbe.SuccessThreshold
-		d.Spec.Template.Spec.Containers[0].LivenessProbe.FailureThreshold = c.deployment.Spec.Template.Spec.Containers[0].LivenessProbe.FailureThreshold
-		if d.Spec.Template.Spec.Containers[0].ReadinessProbe == nil {
-			d.Spec.Template.Spec.Containers[0].ReadinessProbe = &corev1.Probe{}
+		if c.deployment.Spec.Template.Spec.Containers[0].ReadinessProbe == nil {
+			d.Spec.Template.Spec.Containers[0].ReadinessProbe = nil
+		} else {
+			if d.Spec.Template.Spec.Containers[0].ReadinessProbe == nil {
+				d.Spec.Template.Spec.Containers[0].ReadinessProbe = &corev1.Probe{}
+			}
+			d.Spec.Template.Spec.Containers[0].ReadinessProbe.HTTPGet = c.deployment.Spec.Template.Spec.Containers[0].ReadinessProbe.HTTPGet
 		}
-		d.Spec.Template.Spec.Containers[0].ReadinessProbe.HTTPGet = c.deployment.Spec.Template.Spec.Containers[0].ReadinessProbe.HTTPGet
 		if d.Spec.Template.Spec.Containers[0].SecurityContext == nil {
 			d.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{}
 		}
@@ -329,16 +633,17 @@ func (c *CoreDNS) mutateDeployment(ctx context.Context, tenantClient client.Clie
 		d.Spec.Template.Spec.DNSPolicy = c.deployment.Spec.Template.Spec.DNSPolicy
 		d.Spec.Template.Spec.NodeSelector = c.deployment.Spec.Template.Spec.NodeSelector
 		d.Spec.Template.Spec.ServiceAccountName = c.deployment.Spec.Template.Spec.ServiceAccountName
-		if d.Spec.Template.Spec.Affinity == nil {
-			d.Spec.Template.Spec.Affinity = &corev1.Affinity{
-				PodAntiAffinity: &corev1.PodAntiAffinity{},
-			}
+		d.Spec.Template.Spec.Affinity = &corev1.Affinity{
+			PodAffinity: c.deployment.Spec.Template.Spec.Affinity.PodAffinity,
 		}
-		d.Spec.Template.Spec.Affinity.PodAffinity = c.deployment.Spec.Template.Spec.Affinity.PodAffinity
 		d.Spec.Template.Spec.Tolerations = c.deployment.Spec.Template.Spec.Tolerations
 		d.Spec.Template.Spec.PriorityClassName = c.deployment.Spec.Template.Spec.PriorityClassName
 		d.Spec.Strategy.Type = c.deployment.Spec.Strategy.Type
 
+		if err := applyPatches(d, c.patches); err != nil {
+			return errors.Wrap(err, "cannot apply the Deployment patches")
+		}
+
 		return controllerutil.SetControllerReference(c.clusterRoleBinding, d, tenantClient.Scheme())
 	})
 }
@@ -348,9 +653,9 @@ func (c *CoreDNS) mutateConfigMap(ctx context.Context, tenantClient client.Clien
 	cm.SetName(c.configMap.GetName())
 	cm.SetNamespace(c.configMap.GetNamespace())
 
-	return utilities.CreateOrUpdateWithConflict(ctx, tenantClient, cm, func() error {
+	return utilities.ServerSideApply(ctx, tenantClient, cm, func() error {
 		cm.SetLabels(c.configMap.GetLabels())
-		cm.SetAnnotations(utilities.MergeMaps(cm.GetAnnotations(), c.configMap.GetAnnotations()))
+		cm.SetAnnotations(c.configMap.GetAnnotations())
 		cm.Data = c.configMap.Data
 
 		return controllerutil.SetControllerReference(c.clusterRoleBinding, cm, tenantClient.Scheme())
@@ -362,9 +667,9 @@ func (c *CoreDNS) mutateService(ctx context.Context, tenantClient client.Client)
 	svc.SetName(c.service.GetName())
 	svc.SetNamespace(c.service.GetNamespace())
 
-	return utilities.CreateOrUpdateWithConflict(ctx, tenantClient, svc, func() error {
+	return utilities.ServerSideApply(ctx, tenantClient, svc, func() error {
 		svc.SetLabels(c.service.GetLabels())
-		svc.SetAnnotations(utilities.MergeMaps(svc.GetAnnotations(), c.service.GetAnnotations()))
+		svc.SetAnnotations(c.service.GetAnnotations())
 
 		svc.Spec.Ports = c.service.Spec.Ports
 		svc.Spec.Selector = c.service.Spec.Selector
@@ -379,9 +684,9 @@ func (c *CoreDNS) mutateClusterRole(ctx context.Context, tenantClient client.Cli
 	cr.SetName(c.clusterRole.GetName())
 	cr.SetNamespace(c.clusterRole.GetNamespace())
 
-	return utilities.CreateOrUpdateWithConflict(ctx, tenantClient, cr, func() error {
+	return utilities.ServerSideApply(ctx, tenantClient, cr, func() error {
 		cr.SetLabels(c.clusterRole.GetLabels())
-		cr.SetAnnotations(utilities.MergeMaps(cr.GetAnnotations(), c.clusterRole.GetAnnotations()))
+		cr.SetAnnotations(c.clusterRole.GetAnnotations())
 		cr.Rules = c.clusterRole.Rules
 
 		return controllerutil.SetControllerReference(c.clusterRoleBinding, cr, tenantClient.Scheme())
@@ -389,14 +694,165 @@ func (c *CoreDNS) mutateClusterRole(ctx context.Context, tenantClient client.Cli
 }
 
 func (c *CoreDNS) mutateServiceAccount(ctx context.Context, tenantClient client.Client) (controllerutil.OperationResult, error) {
+	managed, err := c.isServiceAccountManaged(ctx, tenantClient)
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
 	sa := &corev1.ServiceAccount{}
 	sa.SetName(c.serviceAccount.GetName())
 	sa.SetNamespace(c.serviceAccount.GetNamespace())
 
-	return utilities.CreateOrUpdateWithConflict(ctx, tenantClient, sa, func() error {
+	return utilities.ServerSideApply(ctx, tenantClient, sa, func() error {
 		sa.SetLabels(c.serviceAccount.GetLabels())
-		sa.SetAnnotations(utilities.MergeMaps(sa.GetAnnotations(), c.serviceAccount.GetAnnotations()))
+		sa.SetAnnotations(c.serviceAccount.GetAnnotations())
+		// The ServiceAccount provided by the tenant cluster administrators is annotated only,
+		// not to be garbage collected along with the CoreDNS resources.
+		if !managed {
+			return nil
+		}
 
 		return controllerutil.SetControllerReference(c.clusterRoleBinding, sa, tenantClient.Scheme())
 	})
 }
+
+// isServiceAccountManaged returns true if the CoreDNS ServiceAccount has been created by Kamaji, or is going to be:
+// the default one is always managed, as it's part of the kubeadm manifests.
+func (c *CoreDNS) isServiceAccountManaged(ctx context.Context, tenantClient client.Client) (bool, error) {
+	if c.serviceAccount.GetName() == kubeadm.CoreDNSName {
+		return true, nil
+	}
+
+	sa := &corev1.ServiceAccount{}
+	if err := tenantClient.Get(ctx, client.ObjectKeyFromObject(c.serviceAccount), sa); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	return isControlledByCoreDNS(sa), nil
+}
+
+// pruneServiceAccounts deletes the ServiceAccounts previously created by Kamaji for CoreDNS, upon the name changes.
+func (c *CoreDNS) pruneServiceAccounts(ctx context.Context, tenantClient client.Client) (controllerutil.OperationResult, error) {
+	serviceAccounts := &corev1.ServiceAccountList{}
+	if err := tenantClient.List(ctx, serviceAccounts, client.InNamespace(kubeadm.KubeSystemNamespace)); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	result := controllerutil.OperationResultNone
+
+	for i := range serviceAccounts.Items {
+		sa := &serviceAccounts.Items[i]
+
+		if sa.GetName() == c.serviceAccount.GetName() || sa.GetName() == c.autoscaler.serviceAccount.GetName() || !isControlledByCoreDNS(sa) {
+			continue
+		}
+
+		if err := tenantClient.Delete(ctx, sa); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+
+			return controllerutil.OperationResultNone, err
+		}
+
+		result = controllerutil.OperationResultUpdated
+	}
+
+	return result, nil
+}
+
+// isControlledByCoreDNS returns true if the object is controlled by the CoreDNS ClusterRoleBinding,
+// as all the CoreDNS resources created by Kamaji.
+func isControlledByCoreDNS(obj client.Object) bool {
+	owner := metav1.GetControllerOf(obj)
+
+	return owner != nil && owner.Kind == "ClusterRoleBinding" && owner.Name == kubeadm.CoreDNSClusterRoleBindingName
+}
+
+// mutatePodDisruptionBudget creates or updates the CoreDNS PodDisruptionBudget when requested,
+// deleting it otherwise.
+func (c *CoreDNS) mutatePodDisruptionBudget(ctx context.Context, tenantClient client.Client, spec *kamajiv1alpha1.CoreDNSPodDisruptionBudgetSpec) (controllerutil.OperationResult, error) {
+	pdb := &policyv1.PodDisruptionBudget{}
+	pdb.SetName(c.podDisruptionBudget.GetName())
+	pdb.SetNamespace(c.podDisruptionBudget.GetNamespace())
+
+	if spec == nil {
+		if err := tenantClient.Delete(ctx, pdb); err != nil {
+			if k8serrors.IsNotFound(err) {
+				return controllerutil.OperationResultNone, nil
+			}
+
+			return controllerutil.OperationResultNone, err
+		}
+
+		return controllerutil.OperationResultUpdated, nil
+	}
+
+	return utilities.ServerSideApply(ctx, tenantClient, pdb, func() error {
+		pdb.SetLabels(c.podDisruptionBudget.GetLabels())
+		pdb.SetAnnotations(c.podDisruptionBudget.GetAnnotations())
+		pdb.Spec.MinAvailable = c.podDisruptionBudget.Spec.MinAvailable
+		pdb.Spec.Selector = c.podDisruptionBudget.Spec.Selector
+
+		return controllerutil.SetControllerReference(c.clusterRoleBinding, pdb, tenantClient.Scheme())
+	})
+}
+
+// corefileCache configures the cache plugin of the given Corefile, removing it when disabled,
+// or overriding its TTL: the Corefile is returned unchanged with no cache settings.
+func corefileCache(corefile string, cache *kamajiv1alpha1.CoreDNSCacheSpec) string {
+	if cache == nil {
+		return corefile
+	}
+
+	if cache.Enabled != nil && !*cache.Enabled {
+		return corefileCacheRegexp.ReplaceAllString(corefile, "")
+	}
+
+	if cache.TTL != nil {
+		return corefileCacheRegexp.ReplaceAllString(corefile, fmt.Sprintf("${1}cache %d\n", *cache.TTL))
+	}
+
+	return corefile
+}
+
+// corefilePlugins configures the health, ready, prometheus, and log plugins of the given Corefile, removing the disabled ones,
+// or overriding their port: the plugins with no settings are left unchanged.
+func corefilePlugins(corefile string, spec *kamajiv1alpha1.CoreDNSAddonSpec) string {
+	if health := spec.Health; health != nil {
+		if health.IsEnabled() {
+			corefile = corefileHealthHeader.ReplaceAllString(corefile, fmt.Sprintf("${1}health :%d {", health.GetPort(kamajiv1alpha1.CoreDNSHealthDefaultPort)))
+		} else {
+			corefile = corefileHealthRegexp.ReplaceAllString(corefile, "")
+		}
+	}
+
+	if ready := spec.Ready; ready != nil {
+		replacement := ""
+		if ready.IsEnabled() {
+			replacement = fmt.Sprintf("${1}ready :%d\n", ready.GetPort(kamajiv1alpha1.CoreDNSReadyDefaultPort))
+		}
+
+		corefile = corefileReadyRegexp.ReplaceAllString(corefile, replacement)
+	}
+
+	if metrics := spec.Metrics; metrics != nil {
+		replacement := ""
+		if metrics.IsEnabled() {
+			replacement = fmt.Sprintf("${1}prometheus :%d\n", metrics.GetPort(kamajiv1alpha1.CoreDNSMetricsDefaultPort))
+		}
+
+		corefile = corefilePrometheusRegexp.ReplaceAllString(corefile, replacement)
+	}
+
+	replacement := "${1}errors\n"
+	if spec.Log {
+		replacement += "${1}log\n"
+	}
+
+	return corefileErrorsRegexp.ReplaceAllString(corefile, replacement)
+}
@@ -0,0 +1,231 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package addons
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/internal/constants"
+	"github.com/clastix/kamaji/internal/kubeadm"
+	"github.com/clastix/kamaji/internal/utilities"
+)
+
+const (
+	coreDNSAutoscalerName         = "coredns-autoscaler"
+	coreDNSAutoscalerDefaultImage = "registry.k8s.io/cpa/cluster-proportional-autoscaler:v1.8.8"
+	// coreDNSAutoscalerLinearKey is the ConfigMap key the cluster-proportional-autoscaler reads the linear mode parameters from.
+	coreDNSAutoscalerLinearKey = "linear"
+)
+
+// coreDNSAutoscaler contains the resources of the cluster-proportional-autoscaler scaling the CoreDNS Deployment.
+type coreDNSAutoscaler struct {
+	serviceAccount     *corev1.ServiceAccount
+	clusterRole        *rbacv1.ClusterRole
+	clusterRoleBinding *rbacv1.ClusterRoleBinding
+	configMap          *corev1.ConfigMap
+	deployment         *appsv1.Deployment
+}
+
+// coreDNSAutoscalerLinearParams are the parameters of the cluster-proportional-autoscaler linear mode.
+type coreDNSAutoscalerLinearParams struct {
+	CoresPerReplica           int32 `json:"coresPerReplica"`
+	NodesPerReplica           int32 `json:"nodesPerReplica"`
+	Min                       int32 `json:"min"`
+	Max                       int32 `json:"max,omitempty"`
+	PreventSinglePointFailure bool  `json:"preventSinglePointFailure"`
+	IncludeUnschedulableNodes bool  `json:"includeUnschedulableNodes"`
+}
+
+func defineCoreDNSAutoscaler() coreDNSAutoscaler {
+	return coreDNSAutoscaler{
+		serviceAccount: &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      coreDNSAutoscalerName,
+				Namespace: kubeadm.KubeSystemNamespace,
+			},
+		},
+		clusterRole: &rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("system:%s", coreDNSAutoscalerName),
+			},
+		},
+		clusterRoleBinding: &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("system:%s", coreDNSAutoscalerName),
+			},
+		},
+		configMap: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      coreDNSAutoscalerName,
+				Namespace: kubeadm.KubeSystemNamespace,
+			},
+		},
+		deployment: &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      coreDNSAutoscalerName,
+				Namespace: kubeadm.KubeSystemNamespace,
+			},
+		},
+	}
+}
+
+// objects returns the autoscaler resources, in the order they're applied.
+func (a coreDNSAutoscaler) objects() []client.Object {
+	return []client.Object{a.serviceAccount, a.clusterRole, a.clusterRoleBinding, a.configMap, a.deployment}
+}
+
+// decodeAutoscaler renders the autoscaler resources for the given spec, targeting the CoreDNS Deployment:
+// the autoscaler Pods are rolled out upon the changes to its parameters, tracked by the ConfigMap checksum.
+func (c *CoreDNS) decodeAutoscaler(spec *kamajiv1alpha1.CoreDNSAutoscalerSpec) error {
+	labels := map[string]string{"k8s-app": coreDNSAutoscalerName}
+
+	params := coreDNSAutoscalerLinearParams{
+		CoresPerReplica:           spec.CoresPerReplica,
+		NodesPerReplica:           spec.NodesPerReplica,
+		Min:                       spec.MinReplicas,
+		PreventSinglePointFailure: spec.PreventSinglePointFailure == nil || *spec.PreventSinglePointFailure,
+		IncludeUnschedulableNodes: true,
+	}
+	if spec.MaxReplicas != nil {
+		params.Max = *spec.MaxReplicas
+	}
+
+	linear, err := json.Marshal(params)
+	if err != nil {
+		return errors.Wrap(err, "unable to encode the autoscaler parameters")
+	}
+
+	c.autoscaler.serviceAccount.SetLabels(labels)
+
+	c.autoscaler.clusterRole.SetLabels(labels)
+	c.autoscaler.clusterRole.Rules = []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"list", "watch"}},
+		{APIGroups: []string{""}, Resources: []string{"replicationcontrollers/scale"}, Verbs: []string{"get", "update"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments/scale", "replicasets/scale"}, Verbs: []string{"get", "update"}},
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "create"}},
+	}
+
+	c.autoscaler.clusterRoleBinding.SetLabels(labels)
+	c.autoscaler.clusterRoleBinding.Subjects = []rbacv1.Subject{
+		{Kind: rbacv1.ServiceAccountKind, Name: c.autoscaler.serviceAccount.GetName(), Namespace: c.autoscaler.serviceAccount.GetNamespace()},
+	}
+	c.autoscaler.clusterRoleBinding.RoleRef = rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     "ClusterRole",
+		Name:     c.autoscaler.clusterRole.GetName(),
+	}
+
+	c.autoscaler.configMap.SetLabels(labels)
+	c.autoscaler.configMap.Data = map[string]string{coreDNSAutoscalerLinearKey: string(linear)}
+	utilities.SetObjectChecksum(c.autoscaler.configMap, c.autoscaler.configMap.Data)
+
+	image := spec.Image
+	if len(image) == 0 {
+		image = coreDNSAutoscalerDefaultImage
+	}
+
+	c.autoscaler.deployment.SetLabels(labels)
+	c.autoscaler.deployment.Spec = appsv1.DeploymentSpec{
+		Replicas: pointer.Int32(1),
+		Selector: &metav1.LabelSelector{MatchLabels: labels},
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      labels,
+				Annotations: map[string]string{constants.Checksum: utilities.GetObjectChecksum(c.autoscaler.configMap)},
+			},
+			Spec: corev1.PodSpec{
+				ServiceAccountName: c.autoscaler.serviceAccount.GetName(),
+				PriorityClassName:  c.deployment.Spec.Template.Spec.PriorityClassName,
+				NodeSelector:       c.deployment.Spec.Template.Spec.NodeSelector,
+				Tolerations:        c.deployment.Spec.Template.Spec.Tolerations,
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsNonRoot: pointer.Bool(true),
+					RunAsUser:    pointer.Int64(65534),
+				},
+				Containers: []corev1.Container{
+					{
+						Name:  "autoscaler",
+						Image: image,
+						Command: []string{
+							"/cluster-proportional-autoscaler",
+							fmt.Sprintf("--namespace=%s", kubeadm.KubeSystemNamespace),
+							fmt.Sprintf("--configmap=%s", c.autoscaler.configMap.GetName()),
+							fmt.Sprintf("--target=deployment/%s", c.deployment.GetName()),
+							"--logtostderr=true",
+							"--v=2",
+						},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("20m"),
+								corev1.ResourceMemory: resource.MustParse("10Mi"),
+							},
+						},
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: pointer.Bool(false),
+							ReadOnlyRootFilesystem:   pointer.Bool(true),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return nil
+}
+
+// mutateAutoscaler creates or updates the autoscaler resources when requested, deleting them otherwise:
+// all of them are controlled by the CoreDNS ClusterRoleBinding, as the other CoreDNS resources.
+func (c *CoreDNS) mutateAutoscaler(ctx context.Context, tenantClient client.Client, spec *kamajiv1alpha1.CoreDNSAutoscalerSpec) (controllerutil.OperationResult, error) {
+	if spec == nil {
+		result := controllerutil.OperationResultNone
+
+		for _, obj := range c.autoscaler.objects() {
+			if err := tenantClient.Delete(ctx, obj); err != nil {
+				if k8serrors.IsNotFound(err) {
+					continue
+				}
+
+				return controllerutil.OperationResultNone, err
+			}
+
+			result = controllerutil.OperationResultUpdated
+		}
+
+		return result, nil
+	}
+
+	result := controllerutil.OperationResultNone
+
+	for _, desired := range c.autoscaler.objects() {
+		obj, _ := desired.DeepCopyObject().(client.Object) //nolint:forcetypeassert
+
+		// The desired object is applied as a whole, since it's entirely rendered by Kamaji.
+		operationResult, err := utilities.ServerSideApply(ctx, tenantClient, obj, func() error {
+			return controllerutil.SetControllerReference(c.clusterRoleBinding, obj, tenantClient.Scheme())
+		})
+		if err != nil {
+			return controllerutil.OperationResultNone, errors.Wrapf(err, "cannot apply the autoscaler %s", desired.GetName())
+		}
+
+		if operationResult != controllerutil.OperationResultNone {
+			result = operationResult
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,20 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package addons
+
+import (
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	kamajierrors "github.com/clastix/kamaji/internal/errors"
+)
+
+// forbiddenError reports the addon resources refused by the tenant API server as AddonForbiddenError,
+// distinguishing a misconfigured RBAC from the transient connectivity errors.
+func forbiddenError(addon string, err error) error {
+	if err == nil || !k8serrors.IsForbidden(err) {
+		return err
+	}
+
+	return kamajierrors.AddonForbiddenError{Addon: addon, Err: err}
+}
@@ -6,6 +6,9 @@ package addons
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
@@ -13,18 +16,25 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/internal/constants"
 	"github.com/clastix/kamaji/internal/kubeadm"
 	"github.com/clastix/kamaji/internal/resources"
 	"github.com/clastix/kamaji/internal/resources/utils"
 	"github.com/clastix/kamaji/internal/utilities"
 )
 
+const kubeProxyConfigKey = "config.conf"
+
+// kubeProxyMetricsBindAddressRegexp matches the metricsBindAddress field of the KubeProxyConfiguration rendered by kubeadm.
+var kubeProxyMetricsBindAddressRegexp = regexp.MustCompile(`(?m)^metricsBindAddress:.*$`)
+
 type KubeProxy struct {
 	Client client.Client
 
@@ -34,6 +44,7 @@ type KubeProxy struct {
 	roleBinding        *rbacv1.RoleBinding
 	configMap          *corev1.ConfigMap
 	daemonSet          *appsv1.DaemonSet
+	patches            []kamajiv1alpha1.AddonPatch
 }
 
 func (k *KubeProxy) Define(context.Context, *kamajiv1alpha1.TenantControlPlane) error {
@@ -80,17 +91,29 @@ func (k *KubeProxy) ShouldCleanup(tenantControlPlane *kamajiv1alpha1.TenantContr
 	return tenantControlPlane.Spec.Addons.KubeProxy == nil
 }
 
-func (k *KubeProxy) CleanUp(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) (bool, error) {
+func (k *KubeProxy) CleanUp(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) (resources.CleanUpResult, error) {
 	logger := log.FromContext(ctx, "resource", "kubeadm_addons", "addon", k.GetName())
 
+	if utilities.IsPaused(tcp) {
+		logger.Info("Tenant Control Plane is paused, skipping clean-up")
+
+		return resources.CleanUpResultNone, nil
+	}
+
+	if isTearingDown(tcp) {
+		logger.V(1).Info("Tenant Control Plane is marked for deletion, skipping clean-up")
+
+		return resources.CleanUpResultNone, nil
+	}
+
 	tenantClient, err := utilities.GetTenantClient(ctx, k.Client, tcp)
 	if err != nil {
 		logger.Error(err, "cannot generate Tenant client")
 
-		return false, err
+		return resources.CleanUpResultNone, err
 	}
 
-	var deleted bool
+	result := resources.CleanUpResultNone
 
 	for _, obj := range []client.Object{k.serviceAccount, k.clusterRoleBinding, k.role, k.roleBinding, k.configMap, k.daemonSet} {
 		if err = tenantClient.Delete(ctx, obj); err != nil {
@@ -98,17 +121,40 @@ func (k *KubeProxy) CleanUp(ctx context.Context, tcp *kamajiv1alpha1.TenantContr
 				continue
 			}
 
-			return false, err
+			return resources.CleanUpResultNone, err
 		}
-		deleted = deleted || err == nil
+
+		result = resources.CleanUpResultCompleted
 	}
 
-	return deleted, nil
+	return result, nil
 }
 
-func (k *KubeProxy) CreateOrUpdate(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error) {
+func (k *KubeProxy) CreateOrUpdate(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) (_ controllerutil.OperationResult, err error) {
 	logger := log.FromContext(ctx, "addon", k.GetName())
 
+	defer func() {
+		err = forbiddenError(k.GetName(), err)
+	}()
+
+	if utilities.IsPaused(tcp) {
+		logger.Info("Tenant Control Plane is paused, skipping reconciliation")
+
+		return controllerutil.OperationResultNone, nil
+	}
+
+	if isTearingDown(tcp) {
+		logger.V(1).Info("Tenant Control Plane is marked for deletion, skipping reconciliation")
+
+		return controllerutil.OperationResultNone, nil
+	}
+
+	if !isDataStoreReady(tcp) {
+		logger.V(1).Info("datastore setup not completed yet, enqueuing back")
+
+		return resources.OperationResultEnqueueBack, nil
+	}
+
 	tenantClient, err := utilities.GetTenantClient(ctx, k.Client, tcp)
 	if err != nil {
 		logger.Error(err, "cannot generate Tenant client")
@@ -121,6 +167,14 @@ func (k *KubeProxy) CreateOrUpdate(ctx context.Context, tcp *kamajiv1alpha1.Tena
 
 		return controllerutil.OperationResultNone, err
 	}
+	// Re-applying the resources in place, overwriting the fields changed by other managers:
+	// the resync is idempotent, thus it's repeated until the annotation is cleared.
+	forceResync := shouldForceResync(tcp, k.GetName())
+	if forceResync {
+		logger.Info("forcing the addon resync")
+
+		ctx = utilities.WithForceOwnership(ctx)
+	}
 
 	var operationResult controllerutil.OperationResult
 
@@ -174,6 +228,14 @@ func (k *KubeProxy) CreateOrUpdate(ctx context.Context, tcp *kamajiv1alpha1.Tena
 	}
 	reconciliationResult = utils.UpdateOperationResult(reconciliationResult, operationResult)
 
+	if forceResync {
+		if err = clearForceResync(ctx, k.Client, tcp, k.GetName()); err != nil {
+			logger.Error(err, "cannot clear the addon resync annotation")
+
+			return controllerutil.OperationResultNone, err
+		}
+	}
+
 	return reconciliationResult, nil
 }
 
@@ -181,6 +243,16 @@ func (k *KubeProxy) GetName() string {
 	return "kube-proxy"
 }
 
+// Validate executes the validations registered for the addon against the tenant cluster, once applied:
+// it must succeed before updating the Tenant Control Plane status.
+func (k *KubeProxy) Validate(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) error {
+	return validate(ctx, k.GetName(), tcp, func() (clientset.Interface, error) {
+		tcpClient, _, err := k.kubeadmDeps(ctx, tcp)
+
+		return tcpClient, err
+	})
+}
+
 func (k *KubeProxy) ShouldStatusBeUpdated(_ context.Context, tcp *kamajiv1alpha1.TenantControlPlane) bool {
 	return tcp.Spec.Addons.KubeProxy != nil && !tcp.Status.Addons.KubeProxy.Enabled
 }
@@ -200,7 +272,7 @@ func (k *KubeProxy) mutateClusterRoleBinding(ctx context.Context, tenantClient c
 		k.clusterRoleBinding.SetUID(crb.GetUID())
 	}()
 
-	return utilities.CreateOrUpdateWithConflict(ctx, tenantClient, crb, func() error {
+	return utilities.ServerSideApply(ctx, tenantClient, crb, func() error {
 		crb.SetLabels(k.clusterRoleBinding.GetLabels())
 		crb.SetAnnotations(k.clusterRoleBinding.GetAnnotations())
 		crb.Subjects = k.clusterRoleBinding.Subjects
@@ -215,7 +287,7 @@ func (k *KubeProxy) mutateServiceAccount(ctx context.Context, tenantClient clien
 	sa.SetName(k.serviceAccount.GetName())
 	sa.SetNamespace(k.serviceAccount.GetNamespace())
 
-	return utilities.CreateOrUpdateWithConflict(ctx, tenantClient, sa, func() error {
+	return utilities.ServerSideApply(ctx, tenantClient, sa, func() error {
 		sa.SetLabels(k.serviceAccount.GetLabels())
 		sa.SetAnnotations(k.serviceAccount.GetAnnotations())
 
@@ -228,7 +300,7 @@ func (k *KubeProxy) mutateRole(ctx context.Context, tenantClient client.Client)
 	r.SetName(k.role.GetName())
 	r.SetNamespace(k.role.GetNamespace())
 
-	return utilities.CreateOrUpdateWithConflict(ctx, tenantClient, r, func() error {
+	return utilities.ServerSideApply(ctx, tenantClient, r, func() error {
 		r.SetLabels(k.role.GetLabels())
 		r.SetAnnotations(k.role.GetAnnotations())
 		r.Rules = k.role.Rules
@@ -242,7 +314,7 @@ func (k *KubeProxy) mutateRoleBinding(ctx context.Context, tenantClient client.C
 	rb.SetName(k.roleBinding.GetName())
 	rb.SetNamespace(k.roleBinding.GetNamespace())
 
-	return utilities.CreateOrUpdateWithConflict(ctx, tenantClient, rb, func() error {
+	return utilities.ServerSideApply(ctx, tenantClient, rb, func() error {
 		rb.SetLabels(k.roleBinding.GetLabels())
 		rb.SetAnnotations(k.roleBinding.GetAnnotations())
 		if len(rb.Subjects) == 0 {
@@ -262,7 +334,7 @@ func (k *KubeProxy) mutateConfigMap(ctx context.Context, tenantClient client.Cli
 	cm.SetName(k.configMap.GetName())
 	cm.SetNamespace(k.configMap.GetNamespace())
 
-	return utilities.CreateOrUpdateWithConflict(ctx, tenantClient, cm, func() error {
+	return utilities.ServerSideApply(ctx, tenantClient, cm, func() error {
 		cm.SetLabels(k.configMap.GetLabels())
 		cm.SetAnnotations(k.configMap.GetAnnotations())
 		cm.Data = k.configMap.Data
@@ -276,14 +348,18 @@ func (k *KubeProxy) mutateDaemonSet(ctx context.Context, tenantClient client.Cli
 	ds.SetName(k.daemonSet.GetName())
 	ds.SetNamespace(k.daemonSet.GetNamespace())
 
-	return utilities.CreateOrUpdateWithConflict(ctx, tenantClient, ds, func() error {
+	return utilities.ServerSideApply(ctx, tenantClient, ds, func() error {
 		ds.SetLabels(k.daemonSet.GetLabels())
-		ds.SetAnnotations(utilities.MergeMaps(ds.GetAnnotations(), k.daemonSet.GetAnnotations()))
+		ds.SetAnnotations(k.daemonSet.GetAnnotations())
 		ds.Spec.Selector = k.daemonSet.Spec.Selector
 		if len(ds.Spec.Template.Spec.Volumes) != 3 {
 			ds.Spec.Template.Spec.Volumes = make([]corev1.Volume, 3)
 		}
 		ds.Spec.Template.ObjectMeta.SetLabels(k.daemonSet.Spec.Template.GetLabels())
+		// Rolling out the kube-proxy Pods upon configuration changes, since it's read only at startup.
+		ds.Spec.Template.ObjectMeta.SetAnnotations(map[string]string{
+			constants.Checksum: utilities.GetObjectChecksum(k.configMap),
+		})
 		ds.Spec.Template.Spec.Volumes[0].Name = k.daemonSet.Spec.Template.Spec.Volumes[0].Name
 		ds.Spec.Template.Spec.Volumes[0].VolumeSource.ConfigMap = &corev1.ConfigMapVolumeSource{
 			LocalObjectReference: corev1.LocalObjectReference{Name: k.daemonSet.Spec.Template.Spec.Volumes[0].VolumeSource.ConfigMap.Name},
@@ -345,14 +421,26 @@ func (k *KubeProxy) mutateDaemonSet(ctx context.Context, tenantClient client.Cli
 		ds.Spec.Template.Spec.PriorityClassName = k.daemonSet.Spec.Template.Spec.PriorityClassName
 		ds.Spec.UpdateStrategy.Type = k.daemonSet.Spec.UpdateStrategy.Type
 
+		if err := applyPatches(ds, k.patches); err != nil {
+			return errors.Wrap(err, "cannot apply the DaemonSet patches")
+		}
+
 		return controllerutil.SetControllerReference(k.clusterRoleBinding, ds, tenantClient.Scheme())
 	})
 }
 
-func (k *KubeProxy) decodeManifests(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) error {
+// KubeadmConfiguration returns the kubeadm configuration used to render the kube-proxy manifests
+// for the given Tenant Control Plane, allowing to inspect it with no changes against the tenant cluster.
+func (k *KubeProxy) KubeadmConfiguration(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) (*kubeadm.Configuration, error) {
+	_, config, err := k.kubeadmDeps(ctx, tcp)
+
+	return config, err
+}
+
+func (k *KubeProxy) kubeadmDeps(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) (*clientset.Clientset, *kubeadm.Configuration, error) {
 	tcpClient, config, err := resources.GetKubeadmManifestDeps(ctx, k.Client, tcp)
 	if err != nil {
-		return errors.Wrap(err, "unable to create manifests dependencies")
+		return nil, nil, errors.Wrap(err, "unable to create manifests dependencies")
 	}
 	// If the kube-proxy addon has overrides, adding it to the kubeadm parameters
 	config.Parameters.KubeProxyOptions = &kubeadm.AddonOptions{}
@@ -369,6 +457,25 @@ func (k *KubeProxy) decodeManifests(ctx context.Context, tcp *kamajiv1alpha1.Ten
 		config.Parameters.KubeProxyOptions.Tag = tcp.Spec.Kubernetes.Version
 	}
 
+	if conntrack := tcp.Spec.Addons.KubeProxy.Conntrack; conntrack != nil {
+		config.Parameters.KubeProxyConntrackOptions = &kubeadm.KubeProxyConntrackOptions{
+			MaxPerCore:            conntrack.MaxPerCore,
+			Min:                   conntrack.Min,
+			TCPEstablishedTimeout: conntrack.TCPEstablishedTimeout,
+		}
+	}
+
+	return tcpClient, config, nil
+}
+
+func (k *KubeProxy) decodeManifests(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) error {
+	tcpClient, config, err := k.kubeadmDeps(ctx, tcp)
+	if err != nil {
+		return err
+	}
+
+	k.patches = tcp.Spec.Addons.KubeProxy.Patches
+
 	manifests, err := kubeadm.AddKubeProxy(tcpClient, config)
 	if err != nil {
 		return errors.Wrap(err, "unable to generate manifests")
@@ -396,9 +503,28 @@ func (k *KubeProxy) decodeManifests(ctx context.Context, tcp *kamajiv1alpha1.Ten
 		return errors.Wrap(err, "unable to decode ConfigMap manifest")
 	}
 
+	k.configMap.Data[kubeProxyConfigKey] = kubeProxyMetricsBindAddress(k.configMap.Data[kubeProxyConfigKey], tcp.Spec.Addons.KubeProxy.MetricsBindAddress)
+	utilities.SetObjectChecksum(k.configMap, versionedChecksumData(k.configMap.Data, tcp.Spec.Kubernetes.Version))
+
 	if err = utilities.DecodeFromYAML(string(parts[6]), k.daemonSet); err != nil {
 		return errors.Wrap(err, "unable to decode DaemonSet manifest")
 	}
 
 	return nil
 }
+
+// kubeProxyMetricsBindAddress overrides the metrics bind address of the given kube-proxy configuration,
+// returning it unchanged when no address is provided.
+func kubeProxyMetricsBindAddress(config, address string) string {
+	if len(address) == 0 {
+		return config
+	}
+
+	value := fmt.Sprintf("metricsBindAddress: %q", address)
+
+	if !kubeProxyMetricsBindAddressRegexp.MatchString(config) {
+		return strings.TrimSuffix(config, "\n") + "\n" + value + "\n"
+	}
+
+	return kubeProxyMetricsBindAddressRegexp.ReplaceAllLiteralString(config, value)
+}
@@ -0,0 +1,71 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package addons
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/internal/constants"
+	"github.com/clastix/kamaji/internal/utilities"
+)
+
+// applyPatches applies in order the user-provided patches to the given object,
+// recording their checksum in the object annotations.
+func applyPatches(obj client.Object, patches []kamajiv1alpha1.AddonPatch) error {
+	if len(patches) == 0 {
+		return nil
+	}
+
+	current, err := json.Marshal(obj)
+	if err != nil {
+		return errors.Wrap(err, "cannot marshal the object to patch")
+	}
+
+	for i, patch := range patches {
+		content, err := yaml.YAMLToJSON([]byte(patch.Patch))
+		if err != nil {
+			return errors.Wrapf(err, "cannot decode the patch #%d", i)
+		}
+
+		switch patch.Type {
+		case kamajiv1alpha1.JSONAddonPatchType:
+			decoded, decodeErr := jsonpatch.DecodePatch(content)
+			if decodeErr != nil {
+				return errors.Wrapf(decodeErr, "cannot decode the JSON patch #%d", i)
+			}
+
+			current, err = decoded.Apply(current)
+		case kamajiv1alpha1.StrategicMergeAddonPatchType, "":
+			current, err = strategicpatch.StrategicMergePatch(current, content, obj)
+		default:
+			return fmt.Errorf("unsupported type %s of the patch #%d", patch.Type, i)
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "cannot apply the patch #%d", i)
+		}
+	}
+	// Resetting the object, since the patches could have removed some fields.
+	reflect.ValueOf(obj).Elem().Set(reflect.Zero(reflect.TypeOf(obj).Elem()))
+
+	if err = json.Unmarshal(current, obj); err != nil {
+		return errors.Wrap(err, "cannot unmarshal the patched object")
+	}
+
+	checksum, _ := json.Marshal(patches)
+	obj.SetAnnotations(utilities.MergeMaps(obj.GetAnnotations(), map[string]string{
+		constants.Checksum: utilities.CalculateMapChecksum(map[string]string{"patches": string(checksum)}),
+	}))
+
+	return nil
+}
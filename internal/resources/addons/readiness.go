@@ -0,0 +1,23 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package addons
+
+import (
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+)
+
+// isDataStoreReady returns true once the datastore setup has been completed for the current storage configuration:
+// the addons are applied afterwards, since the tenant API Server is not able to serve them in the meanwhile,
+// such as upon the initial provisioning.
+func isDataStoreReady(tcp *kamajiv1alpha1.TenantControlPlane) bool {
+	storage := tcp.Status.Storage
+
+	return len(storage.Config.Checksum) > 0 && storage.Setup.Checksum == storage.Config.Checksum
+}
+
+// isTearingDown returns true if the Tenant Control Plane is marked for deletion: the addons are neither applied,
+// nor removed, since the whole tenant cluster is torn down along with its API Server.
+func isTearingDown(tcp *kamajiv1alpha1.TenantControlPlane) bool {
+	return tcp.GetDeletionTimestamp() != nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package addons
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/internal/constants"
+)
+
+// forceResyncAddons returns the addons listed in the force resync annotation of the Tenant Control Plane.
+func forceResyncAddons(tcp *kamajiv1alpha1.TenantControlPlane) []string {
+	value, ok := tcp.GetAnnotations()[constants.ForceAddonsResync]
+	if !ok {
+		return nil
+	}
+
+	var addons []string
+
+	for _, addon := range strings.Split(value, ",") {
+		if addon = strings.TrimSpace(addon); len(addon) > 0 {
+			addons = append(addons, addon)
+		}
+	}
+
+	return addons
+}
+
+// shouldForceResync returns true if the given addon is listed in the force resync annotation of the Tenant Control Plane.
+func shouldForceResync(tcp *kamajiv1alpha1.TenantControlPlane, addon string) bool {
+	for _, name := range forceResyncAddons(tcp) {
+		if name == addon {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clearForceResync removes the given addon from the force resync annotation of the Tenant Control Plane,
+// removing the annotation once no more addons are listed.
+func clearForceResync(ctx context.Context, c client.Client, tenantControlPlane *kamajiv1alpha1.TenantControlPlane, addon string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		tcp := &kamajiv1alpha1.TenantControlPlane{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: tenantControlPlane.GetNamespace(), Name: tenantControlPlane.GetName()}, tcp); err != nil {
+			return err
+		}
+
+		var pending []string
+
+		for _, name := range forceResyncAddons(tcp) {
+			if name != addon {
+				pending = append(pending, name)
+			}
+		}
+
+		annotations := tcp.GetAnnotations()
+		if len(pending) == 0 {
+			delete(annotations, constants.ForceAddonsResync)
+		} else {
+			annotations[constants.ForceAddonsResync] = strings.Join(pending, ",")
+		}
+
+		tcp.SetAnnotations(annotations)
+
+		return c.Update(ctx, tcp)
+	})
+}
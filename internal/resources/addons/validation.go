@@ -0,0 +1,63 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package addons
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	clientset "k8s.io/client-go/kubernetes"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+)
+
+// Validation is a check performed against the tenant cluster once an addon has been applied, such as a smoke test:
+// upon failure, the addon is not reported as enabled in the Tenant Control Plane status, and it's validated again
+// by the next reconciliation.
+type Validation func(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane, tenantClient clientset.Interface) error
+
+var (
+	validationsMu sync.RWMutex
+	validations   = map[string][]Validation{}
+)
+
+// RegisterValidation registers the given Validation for the addon with the given name, such as coredns or kube-proxy:
+// the validations are executed in the order of registration, and it's meant to be called during the start-up.
+func RegisterValidation(addon string, fn Validation) {
+	validationsMu.Lock()
+	defer validationsMu.Unlock()
+
+	validations[addon] = append(validations[addon], fn)
+}
+
+// registeredValidations returns a copy of the validations registered for the given addon.
+func registeredValidations(addon string) []Validation {
+	validationsMu.RLock()
+	defer validationsMu.RUnlock()
+
+	return append([]Validation(nil), validations[addon]...)
+}
+
+// validate executes the validations registered for the given addon, retrieving the tenant clientset
+// with the provided function only when at least one has been registered.
+func validate(ctx context.Context, addon string, tcp *kamajiv1alpha1.TenantControlPlane, tenantClientFn func() (clientset.Interface, error)) error {
+	fns := registeredValidations(addon)
+	if len(fns) == 0 {
+		return nil
+	}
+
+	tenantClient, err := tenantClientFn()
+	if err != nil {
+		return errors.Wrap(err, "cannot create the tenant clientset")
+	}
+
+	for _, fn := range fns {
+		if err = fn(ctx, tcp, tenantClient); err != nil {
+			return errors.Wrapf(err, "%s addon validation failed", addon)
+		}
+	}
+
+	return nil
+}
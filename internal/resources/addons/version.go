@@ -0,0 +1,18 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package addons
+
+// versionedChecksumData folds the Tenant Control Plane Kubernetes version into the data used for the checksum,
+// since the addon manifests are rendered according to it: a version upgrade triggers the addon update,
+// as well as the roll-out of the workloads referring to the checksum.
+func versionedChecksumData(data map[string]string, version string) map[string]string {
+	out := make(map[string]string, len(data)+1)
+	for k, v := range data {
+		out[k] = v
+	}
+
+	out["kubernetesVersion"] = version
+
+	return out
+}
@@ -38,8 +38,8 @@ func (r *APIServerCertificate) ShouldCleanup(_ *kamajiv1alpha1.TenantControlPlan
 	return false
 }
 
-func (r *APIServerCertificate) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (bool, error) {
-	return false, nil
+func (r *APIServerCertificate) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (CleanUpResult, error) {
+	return CleanUpResultNone, nil
 }
 
 func (r *APIServerCertificate) Define(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
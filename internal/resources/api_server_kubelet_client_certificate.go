@@ -38,8 +38,8 @@ func (r *APIServerKubeletClientCertificate) ShouldCleanup(*kamajiv1alpha1.Tenant
 	return false
 }
 
-func (r *APIServerKubeletClientCertificate) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (bool, error) {
-	return false, nil
+func (r *APIServerKubeletClientCertificate) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (CleanUpResult, error) {
+	return CleanUpResultNone, nil
 }
 
 func (r *APIServerKubeletClientCertificate) Define(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
@@ -39,8 +39,8 @@ func (r *CACertificate) ShouldCleanup(*kamajiv1alpha1.TenantControlPlane) bool {
 	return false
 }
 
-func (r *CACertificate) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (bool, error) {
-	return false, nil
+func (r *CACertificate) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (CleanUpResult, error) {
+	return CleanUpResultNone, nil
 }
 
 func (r *CACertificate) Define(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
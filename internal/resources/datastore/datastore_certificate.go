@@ -6,7 +6,9 @@ package datastore
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,6 +20,7 @@ import (
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
 	"github.com/clastix/kamaji/internal/constants"
 	"github.com/clastix/kamaji/internal/crypto"
+	"github.com/clastix/kamaji/internal/resources"
 	"github.com/clastix/kamaji/internal/utilities"
 )
 
@@ -26,6 +29,8 @@ type Certificate struct {
 	Client    client.Client
 	Name      string
 	DataStore kamajiv1alpha1.DataStore
+	// RenewalWindow is the amount of time before the expiration the etcd client certificate is reissued.
+	RenewalWindow time.Duration
 }
 
 func (r *Certificate) ShouldStatusBeUpdated(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) bool {
@@ -36,8 +41,8 @@ func (r *Certificate) ShouldCleanup(*kamajiv1alpha1.TenantControlPlane) bool {
 	return false
 }
 
-func (r *Certificate) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (bool, error) {
-	return false, nil
+func (r *Certificate) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (resources.CleanUpResult, error) {
+	return resources.CleanUpResultNone, nil
 }
 
 func (r *Certificate) Define(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
@@ -72,6 +77,13 @@ func (r *Certificate) UpdateTenantControlPlaneStatus(_ context.Context, tenantCo
 	tenantControlPlane.Status.Storage.Certificate.SecretName = r.resource.GetName()
 	tenantControlPlane.Status.Storage.Certificate.Checksum = utilities.GetObjectChecksum(r.resource)
 	tenantControlPlane.Status.Storage.Certificate.LastUpdate = metav1.Now()
+	tenantControlPlane.Status.Storage.Certificate.NotAfter = nil
+
+	if r.DataStore.Spec.Driver == kamajiv1alpha1.EtcdDriver {
+		if certificate, err := crypto.ParseCertificateBytes(r.resource.Data["server.crt"]); err == nil {
+			tenantControlPlane.Status.Storage.Certificate.NotAfter = &metav1.Time{Time: certificate.NotAfter}
+		}
+	}
 
 	return nil
 }
@@ -87,7 +99,20 @@ func (r *Certificate) mutate(ctx context.Context, tenantControlPlane *kamajiv1al
 			return err
 		}
 
-		r.resource.Data["ca.crt"] = ca
+		r.resource.Data[constants.DataStoreCAKey] = ca
+		// The tenant components are verifying the data store certificate against the same bundle used by Kamaji.
+		delete(r.resource.Data, constants.DataStoreServerCAKey)
+
+		if bundle := r.DataStore.Spec.TLSConfig.ServerCABundle; bundle != nil {
+			serverCA, bundleErr := bundle.GetContent(ctx, r.Client)
+			if bundleErr != nil {
+				logger.Error(bundleErr, "cannot retrieve the server CA bundle content")
+
+				return bundleErr
+			}
+
+			r.resource.Data[constants.DataStoreServerCAKey] = serverCA
+		}
 
 		r.resource.SetLabels(utilities.MergeMaps(
 			utilities.KamajiLabels(tenantControlPlane.GetName(), r.GetName()),
@@ -104,7 +129,7 @@ func (r *Certificate) mutate(ctx context.Context, tenantControlPlane *kamajiv1al
 
 		if utilities.GetObjectChecksum(r.resource) == utilities.CalculateMapChecksum(r.resource.Data) {
 			if r.DataStore.Spec.Driver == kamajiv1alpha1.EtcdDriver {
-				if isValid, _ := crypto.IsValidCertificateKeyPairBytes(r.resource.Data["server.crt"], r.resource.Data["server.key"]); isValid {
+				if r.isValidEtcdCertificate(ca, tenantControlPlane.Status.Storage.Setup.User) {
 					return nil
 				}
 			}
@@ -158,3 +183,30 @@ func (r *Certificate) mutate(ctx context.Context, tenantControlPlane *kamajiv1al
 		return nil
 	}
 }
+
+// isValidEtcdCertificate checks if the tenant client certificate is still valid, signed by the etcd CA,
+// and bound to the tenant etcd user: etcd maps the certificate Common Name to the user, thus deleting the latter
+// is revoking the access to the data of the given tenant.
+// A certificate expiring within the renewal window is not considered valid, in order to be reissued in advance.
+func (r *Certificate) isValidEtcdCertificate(ca []byte, user string) bool {
+	crt, key := r.resource.Data["server.crt"], r.resource.Data["server.key"]
+
+	if isValid, _ := crypto.IsValidCertificateKeyPairBytes(crt, key); !isValid {
+		return false
+	}
+
+	if isVerified, _ := crypto.VerifyCertificate(crt, ca, x509.ExtKeyUsageClientAuth); !isVerified {
+		return false
+	}
+
+	certificate, err := crypto.ParseCertificateBytes(crt)
+	if err != nil {
+		return false
+	}
+
+	if !certificate.NotAfter.After(time.Now().Add(r.RenewalWindow)) {
+		return false
+	}
+
+	return certificate.Subject.CommonName == user
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/internal/resources"
+	"github.com/clastix/kamaji/pkg/datastore"
+)
+
+// DefaultHealthCheckInterval is the interval after which the health of the etcd DataStore members is probed again.
+const DefaultHealthCheckInterval = time.Minute
+
+// Health reports in the Tenant Control Plane status the health of each member of its DataStore,
+// allowing to detect a partial degradation before impacting the tenant: it's supported by the etcd driver only.
+type Health struct {
+	Connection datastore.Connection
+	DataStore  kamajiv1alpha1.DataStore
+	// Interval is the interval after which the members are probed again, disabling the probes when zero.
+	Interval time.Duration
+	// health is the outcome of the latest probe, nil when not reported.
+	health *kamajiv1alpha1.DataStoreHealthStatus
+	// probed is set when the members have been probed by the current reconciliation.
+	probed bool
+}
+
+// IsSupported returns true if the health of the DataStore members is reported.
+func (r *Health) IsSupported() bool {
+	return r.Connection.Capabilities().EndpointsHealth && r.Interval > 0
+}
+
+func (r *Health) Define(context.Context, *kamajiv1alpha1.TenantControlPlane) error {
+	return nil
+}
+
+func (r *Health) ShouldCleanup(*kamajiv1alpha1.TenantControlPlane) bool {
+	return false
+}
+
+func (r *Health) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (resources.CleanUpResult, error) {
+	return resources.CleanUpResultNone, nil
+}
+
+func (r *Health) CreateOrUpdate(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error) {
+	if !r.IsSupported() {
+		return controllerutil.OperationResultNone, nil
+	}
+
+	if current := tenantControlPlane.Status.Storage.Health; current != nil && time.Since(current.LastProbeTime.Time) < r.Interval {
+		return controllerutil.OperationResultNone, nil
+	}
+
+	members, err := r.Connection.EndpointsHealth(ctx)
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	r.health = &kamajiv1alpha1.DataStoreHealthStatus{LastProbeTime: metav1.Now()}
+
+	for _, member := range members {
+		if !member.Healthy {
+			log.FromContext(ctx, "resource", r.GetName()).Info("DataStore member is unhealthy", "datastore", r.DataStore.GetName(), "endpoint", member.Endpoint, "message", member.Message)
+		}
+
+		r.health.Endpoints = append(r.health.Endpoints, kamajiv1alpha1.DataStoreEndpointHealth{
+			Endpoint: member.Endpoint,
+			Healthy:  member.Healthy,
+			Message:  member.Message,
+		})
+	}
+
+	r.probed = true
+
+	return controllerutil.OperationResultNone, nil
+}
+
+func (r *Health) GetName() string {
+	return "datastore-health"
+}
+
+func (r *Health) ShouldStatusBeUpdated(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) bool {
+	return r.probed || !r.IsSupported() && tenantControlPlane.Status.Storage.Health != nil
+}
+
+func (r *Health) UpdateTenantControlPlaneStatus(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
+	tenantControlPlane.Status.Storage.Health = r.health
+
+	return nil
+}
@@ -16,9 +16,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/internal/constants"
 	kamajierrors "github.com/clastix/kamaji/internal/errors"
 	"github.com/clastix/kamaji/internal/resources"
 	"github.com/clastix/kamaji/internal/utilities"
+	"github.com/clastix/kamaji/pkg/datastore"
 )
 
 type Migrate struct {
@@ -34,6 +36,8 @@ type Migrate struct {
 	job              *batchv1.Job
 
 	inProgress bool
+	// phase is the migration phase to report in the Tenant Control Plane storage status.
+	phase kamajiv1alpha1.DataStoreMigrationPhase
 }
 
 func (d *Migrate) Define(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
@@ -75,17 +79,64 @@ func (d *Migrate) ShouldCleanup(tcp *kamajiv1alpha1.TenantControlPlane) bool {
 	return d.ShouldCleanUp && *tcp.Status.Kubernetes.Version.Status == kamajiv1alpha1.VersionMigrating
 }
 
-func (d *Migrate) CleanUp(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) (bool, error) {
+func (d *Migrate) CleanUp(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) (resources.CleanUpResult, error) {
 	err := d.Client.Get(ctx, types.NamespacedName{Name: d.job.GetName(), Namespace: d.job.GetNamespace()}, d.job)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			return false, nil
+			return resources.CleanUpResultNone, nil
 		}
 
-		return false, err
+		return resources.CleanUpResultNone, err
+	}
+	// The Job is still being deleted, such as waiting for its Pods termination.
+	if d.job.GetDeletionTimestamp() != nil {
+		return resources.CleanUpResultPending, nil
+	}
+	// Purging the source data before deleting the Job, in order to retry it upon failures.
+	if _, ok := tcp.GetAnnotations()[constants.CleanUpDataStoreMigration]; ok {
+		if err = d.purgeSource(ctx, tcp); err != nil {
+			return resources.CleanUpResultNone, err
+		}
+	}
+
+	if err = d.Client.Delete(ctx, d.job); err != nil {
+		return resources.CleanUpResultNone, err
+	}
+
+	d.phase = kamajiv1alpha1.DataStoreMigrationCompleted
+
+	return resources.CleanUpResultCompleted, nil
+}
+
+// purgeSource removes the Tenant Control Plane data from the DataStore it has been migrated from,
+// according to its retention policy.
+func (d *Migrate) purgeSource(ctx context.Context, tcp *kamajiv1alpha1.TenantControlPlane) error {
+	migration := tcp.Status.Storage.Migration
+	if migration == nil || len(migration.Source) == 0 || migration.Source == tcp.Status.Storage.DataStoreName {
+		return nil
+	}
+
+	source := &kamajiv1alpha1.DataStore{}
+	if err := d.Client.Get(ctx, types.NamespacedName{Name: migration.Source}, source); err != nil {
+		return fmt.Errorf("unable to retrieve the source DataStore: %w", err)
+	}
+
+	connection, err := datastore.NewStorageConnection(ctx, d.Client, *source)
+	if err != nil {
+		return fmt.Errorf("unable to connect to the source DataStore: %w", err)
+	}
+	defer connection.Close()
+
+	setup := &Setup{Client: d.Client, Connection: connection, DataStore: *source}
+	if err = setup.Define(ctx, tcp); err != nil {
+		return err
+	}
+
+	if err = setup.Purge(ctx, tcp); err != nil {
+		return fmt.Errorf("unable to clean up the source DataStore: %w", err)
 	}
 
-	return false, d.Client.Delete(ctx, d.job)
+	return nil
 }
 
 func (d *Migrate) CreateOrUpdate(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error) {
@@ -97,6 +148,17 @@ func (d *Migrate) CreateOrUpdate(ctx context.Context, tenantControlPlane *kamaji
 		return controllerutil.OperationResultNone, nil
 	}
 
+	jobExists := len(d.job.GetUID()) > 0
+	// Holding the migration before copying the data, or before switching to the target DataStore:
+	// the data copy performed by a running Job can't be paused.
+	if _, ok := tenantControlPlane.GetAnnotations()[constants.PauseDataStoreMigration]; ok && (!jobExists || isJobCompleted(d.job)) {
+		d.phase = kamajiv1alpha1.DataStoreMigrationPaused
+		// Once copied, the data must not change until the switch.
+		d.inProgress = jobExists
+
+		return resources.OperationResultEnqueueBack, nil
+	}
+
 	res, err := utilities.CreateOrUpdateWithConflict(ctx, d.Client, d.job, func() error {
 		d.job.SetLabels(map[string]string{
 			"tcp.kamaji.clastix.io/name":      tenantControlPlane.GetName(),
@@ -128,11 +190,13 @@ func (d *Migrate) CreateOrUpdate(ctx context.Context, tenantControlPlane *kamaji
 	switch res {
 	case controllerutil.OperationResultCreated, controllerutil.OperationResultUpdated:
 		d.inProgress = true
+		d.phase = kamajiv1alpha1.DataStoreMigrationCopying
 
 		return resources.OperationResultEnqueueBack, nil
 	case controllerutil.OperationResultNone:
+		if isJobCompleted(d.job) {
+			d.phase = kamajiv1alpha1.DataStoreMigrationSwitching
 
-		if len(d.job.Status.Conditions) > 0 && d.job.Status.Conditions[0].Type == batchv1.JobComplete && d.job.Status.Conditions[0].Status == corev1.ConditionTrue {
 			return controllerutil.OperationResultNone, nil
 		}
 
@@ -148,8 +212,10 @@ func (d *Migrate) GetName() string {
 	return "migrate"
 }
 
-func (d *Migrate) ShouldStatusBeUpdated(context.Context, *kamajiv1alpha1.TenantControlPlane) bool {
-	return d.inProgress
+func (d *Migrate) ShouldStatusBeUpdated(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) bool {
+	migration := tenantControlPlane.Status.Storage.Migration
+
+	return d.inProgress || len(d.phase) > 0 && (migration == nil || migration.Phase != d.phase)
 }
 
 func (d *Migrate) UpdateTenantControlPlaneStatus(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
@@ -157,5 +223,34 @@ func (d *Migrate) UpdateTenantControlPlaneStatus(_ context.Context, tenantContro
 		tenantControlPlane.Status.Kubernetes.Version.Status = &kamajiv1alpha1.VersionMigrating
 	}
 
+	if len(d.phase) == 0 {
+		return nil
+	}
+
+	migration := tenantControlPlane.Status.Storage.Migration
+	// Tracking a new migration upon the change of the target DataStore.
+	if d.desiredDatastore != nil && (migration == nil || migration.Target != d.desiredDatastore.GetName()) {
+		migration = &kamajiv1alpha1.DataStoreMigrationStatus{
+			Source: d.actualDatastore.GetName(),
+			Target: d.desiredDatastore.GetName(),
+		}
+	}
+
+	if migration == nil {
+		migration = &kamajiv1alpha1.DataStoreMigrationStatus{Target: tenantControlPlane.Status.Storage.DataStoreName}
+	}
+
+	if migration.Phase != d.phase {
+		migration.Phase = d.phase
+		migration.LastTransitionTime = metav1.Now()
+	}
+
+	tenantControlPlane.Status.Storage.Migration = migration
+
 	return nil
 }
+
+// isJobCompleted returns true if the given migration Job has successfully completed the data copy.
+func isJobCompleted(job *batchv1.Job) bool {
+	return len(job.Status.Conditions) > 0 && job.Status.Conditions[0].Type == batchv1.JobComplete && job.Status.Conditions[0].Status == corev1.ConditionTrue
+}
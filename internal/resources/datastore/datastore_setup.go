@@ -5,10 +5,18 @@ package datastore
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -17,8 +25,35 @@ import (
 
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
 	"github.com/clastix/kamaji/controllers/finalizers"
-	"github.com/clastix/kamaji/internal/datastore"
+	"github.com/clastix/kamaji/internal/constants"
+	kamajierrors "github.com/clastix/kamaji/internal/errors"
+	"github.com/clastix/kamaji/internal/resources"
 	"github.com/clastix/kamaji/internal/resources/utils"
+	"github.com/clastix/kamaji/internal/utilities"
+	"github.com/clastix/kamaji/pkg/datastore"
+	datastoreerrors "github.com/clastix/kamaji/pkg/datastore/errors"
+)
+
+// DefaultDriftCheckInterval is the interval after which the existence checks against the DataStore are performed
+// even though the setup is up-to-date, catching any external drift such as the removal of the tenant user.
+const DefaultDriftCheckInterval = 10 * time.Minute
+
+// DefaultReadyTimeout is the time waited for the DataStore to accept connections, such as a just provisioned instance,
+// before enqueuing back the reconciliation.
+const DefaultReadyTimeout = 10 * time.Second
+
+// currentUsers tracks the user Kamaji is authenticated as for each DataStore,
+// logging it only upon the first connection, or when it changes.
+var currentUsers sync.Map
+
+// ExistingUserPolicy defines the handling of the DataStore users created out of band.
+type ExistingUserPolicy string
+
+const (
+	// AdoptExistingUserPolicy takes over the existing user, setting the managed password.
+	AdoptExistingUserPolicy ExistingUserPolicy = "Adopt"
+	// FailExistingUserPolicy refuses to use the existing user, failing the reconciliation.
+	FailExistingUserPolicy ExistingUserPolicy = "Fail"
 )
 
 type SetupResource struct {
@@ -32,25 +67,85 @@ type Setup struct {
 	Client     client.Client
 	Connection datastore.Connection
 	DataStore  kamajiv1alpha1.DataStore
+	// OperatorIdentity is the identity of the Kamaji operator instance, recorded upon the changes against the DataStore
+	// to correlate them with the leader transitions.
+	OperatorIdentity string
+	// ExistingUserPolicy defines how the users already existing in the DataStore,
+	// although not provisioned by Kamaji, are handled.
+	ExistingUserPolicy ExistingUserPolicy
+	// DriftCheckInterval is the interval after which the existence checks against the DataStore are performed
+	// by the next reconciliation, although the setup is up-to-date: DefaultDriftCheckInterval is used when not set.
+	DriftCheckInterval time.Duration
+	// ReadyTimeout is the time waited for the DataStore to accept connections before enqueuing back
+	// the reconciliation: DefaultReadyTimeout is used when not set.
+	ReadyTimeout time.Duration
+	// KineImage is the kine image connecting the Tenant Control Planes to the SQL DataStores:
+	// the privileges and the extensions are ensured again upon its change, such as a kine upgrade.
+	KineImage string
+	// verified is set when the existence checks against the DataStore have been performed,
+	// requiring the status update to keep track of the last verification.
+	verified bool
+	// provisioned is set when any change has been performed against the DataStore.
+	provisioned bool
+	// changes is the breakdown of the changes performed against the DataStore.
+	changes kamajiv1alpha1.DataStoreSetupChanges
+	// disabled is set when the user has been cut off from the DataStore by means of annotation.
+	disabled bool
+	// origin reports if the schema has been created, or adopted since already existing.
+	origin kamajiv1alpha1.DataStoreSetupOrigin
+	// passwordExpiryRefreshed is set when the password expiry has been set, refreshed, or removed.
+	passwordExpiryRefreshed bool
+	// grantedPrivileges are the privileges held by the user, retrieved upon the verification.
+	grantedPrivileges []string
+	// templateDatabase is the template database the schema has been cloned from upon its creation.
+	templateDatabase string
+	// passwordExpiresAt is the time the refreshed password expires, nil when it never expires.
+	passwordExpiresAt *metav1.Time
+	// encodingChecked is set when the encoding of the schema has been compared against the expected one.
+	encodingChecked bool
+	// encodingMismatch describes the difference between the encoding of the schema and the expected one, if any.
+	encodingMismatch string
 }
 
 func (r *Setup) ShouldStatusBeUpdated(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) bool {
-	return tenantControlPlane.Status.Storage.Driver != string(r.DataStore.Spec.Driver) ||
+	return r.verified ||
+		r.disabled && !tenantControlPlane.Status.Storage.Setup.Disabled ||
+		tenantControlPlane.Status.Storage.Driver != string(r.DataStore.Spec.Driver) ||
 		tenantControlPlane.Status.Storage.Setup.Checksum != tenantControlPlane.Status.Storage.Config.Checksum ||
 		tenantControlPlane.Status.Storage.Setup.User != r.resource.user ||
-		tenantControlPlane.Status.Storage.Setup.Schema != r.resource.schema
+		tenantControlPlane.Status.Storage.Setup.Schema != r.resource.schema ||
+		tenantControlPlane.Status.Storage.Setup.GrantOption != r.grantOptions(tenantControlPlane).WithGrantOption ||
+		tenantControlPlane.Status.Storage.Setup.ExtensionsChecksum != r.extensionsChecksum() ||
+		tenantControlPlane.Status.Storage.Setup.SearchPathChecksum != r.searchPathChecksum() ||
+		tenantControlPlane.Status.Storage.Setup.Tablespace != r.tablespace(tenantControlPlane) ||
+		tenantControlPlane.Status.Storage.Setup.SharedUser != r.isSharedUser(tenantControlPlane) ||
+		tenantControlPlane.Status.Storage.Setup.KineImage != r.kineImage() ||
+		r.passwordExpiryRefreshed
 }
 
 func (r *Setup) ShouldCleanup(_ *kamajiv1alpha1.TenantControlPlane) bool {
 	return false
 }
 
-func (r *Setup) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (bool, error) {
-	return false, nil
+func (r *Setup) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (resources.CleanUpResult, error) {
+	return resources.CleanUpResultNone, nil
 }
 
 func (r *Setup) Define(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
 	logger := log.FromContext(ctx, "resource", r.GetName())
+	// The clean-up is performed with the DataStore credentials, and it doesn't require the tenant user password:
+	// relying on the schema and user tracked in the status, since the secret could have been already garbage
+	// collected, or the tenant credentials revoked, such as by emptying its password.
+	if setup := tenantControlPlane.Status.Storage.Setup; tenantControlPlane.GetDeletionTimestamp() != nil && len(setup.Schema) > 0 && len(setup.User) > 0 {
+		logger.V(1).Info("using the DataStore setup status values for the clean-up")
+
+		r.resource = &SetupResource{
+			schema: setup.Schema,
+			user:   setup.User,
+		}
+
+		return nil
+	}
 
 	secret := &corev1.Secret{}
 	namespacedName := types.NamespacedName{
@@ -64,9 +159,25 @@ func (r *Setup) Define(ctx context.Context, tenantControlPlane *kamajiv1alpha1.T
 	}
 
 	r.resource = &SetupResource{
-		schema:   string(secret.Data["DB_SCHEMA"]),
-		user:     string(secret.Data["DB_USER"]),
-		password: string(secret.Data["DB_PASSWORD"]),
+		schema:   strings.TrimSpace(string(secret.Data["DB_SCHEMA"])),
+		user:     strings.TrimSpace(string(secret.Data["DB_USER"])),
+		password: strings.TrimSpace(string(secret.Data["DB_PASSWORD"])),
+	}
+	// Rejecting empty values rather than silently creating a broken user.
+	for key, value := range map[string]string{"DB_SCHEMA": r.resource.schema, "DB_USER": r.resource.user, "DB_PASSWORD": r.resource.password} {
+		if len(value) == 0 {
+			err := fmt.Errorf("the %s key of the DataStore Configuration secret %s is empty or contains only whitespaces", key, namespacedName.String())
+			logger.Error(err, "invalid DataStore Configuration secret")
+
+			return err
+		}
+	}
+	// The schema and user must match the resolved ones, the same validated by the admission webhooks.
+	if schema, user := tenantControlPlane.DataStoreSchemaAndSharedUser(r.DataStore); r.resource.schema != schema || r.resource.user != user {
+		err := fmt.Errorf("the DataStore Configuration secret %s schema and user are diverging from the expected ones (%s, %s)", namespacedName.String(), schema, user)
+		logger.Error(err, "invalid DataStore Configuration secret")
+
+		return err
 	}
 
 	return nil
@@ -79,6 +190,12 @@ func (r *Setup) GetClient() client.Client {
 func (r *Setup) CreateOrUpdate(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (reconciliationResult controllerutil.OperationResult, err error) {
 	logger := log.FromContext(ctx, "resource", r.GetName())
 
+	if utilities.IsPaused(tenantControlPlane) {
+		logger.Info("Tenant Control Plane is paused, skipping reconciliation")
+
+		return controllerutil.OperationResultNone, nil
+	}
+
 	defer func() {
 		if err != nil || controllerutil.ContainsFinalizer(tenantControlPlane, finalizers.DatastoreFinalizer) {
 			return
@@ -101,23 +218,114 @@ func (r *Setup) CreateOrUpdate(ctx context.Context, tenantControlPlane *kamajiv1
 	}()
 
 	reconciliationResult = controllerutil.OperationResultNone
-	var operationResult controllerutil.OperationResult
+	// Cutting the user off during an incident, rather than provisioning it.
+	if _, ok := tenantControlPlane.GetAnnotations()[constants.DisableDataStoreUser]; ok {
+		if err = r.disableUser(ctx, tenantControlPlane); err != nil {
+			logger.Error(err, "unable to disable the DataStore user")
+
+			return reconciliationResult, err
+		}
+
+		if !tenantControlPlane.Status.Storage.Setup.Disabled {
+			logger.Info("DataStore user has been disabled", "user", r.resource.user)
+		}
+
+		r.disabled = true
+
+		return reconciliationResult, nil
+	}
+	// Avoiding redundant queries against the DataStore when nothing changed since the last verification.
+	if r.isUpToDate(tenantControlPlane) && !r.isPasswordExpiryDue(tenantControlPlane) {
+		return reconciliationResult, nil
+	}
+
+	// Waiting for the DataStore to accept connections, such as a just provisioned instance, rather than failing:
+	// the reconciliation is enqueued back upon the timeout.
+	if err = datastore.WaitReady(ctx, r.Connection, r.readyTimeout()); err != nil {
+		if errors.As(err, &datastoreerrors.NotReadyError{}) {
+			logger.Info("waiting for the DataStore to accept connections", "reason", err.Error())
+		} else {
+			logger.Error(err, "unable to connect to the DataStore")
+		}
+
+		return reconciliationResult, err
+	}
 
-	operationResult, err = r.createDB(ctx, tenantControlPlane)
+	// Serializing the setups of the same schema performed concurrently, such as by two operator instances
+	// during a leader transition, which would perform conflicting statements otherwise.
+	unlock, err := r.Connection.Lock(ctx, r.resource.schema)
 	if err != nil {
-		logger.Error(err, "unable to create the DataStore data")
+		if errors.As(err, &datastoreerrors.LockedError{}) {
+			logger.Info("a concurrent setup of the DataStore schema is in progress, enqueuing back", "schema", r.resource.schema)
+		} else {
+			logger.Error(err, "unable to lock the DataStore schema setup")
+		}
 
 		return reconciliationResult, err
 	}
-	reconciliationResult = utils.UpdateOperationResult(reconciliationResult, operationResult)
+	defer func() {
+		if unlockErr := unlock(ctx); unlockErr != nil {
+			logger.Error(unlockErr, "unable to unlock the DataStore schema setup")
+		}
+	}()
+
+	r.verified = true
+
+	r.logCurrentUser(ctx)
 
-	operationResult, err = r.createUser(ctx, tenantControlPlane)
+	if err = r.checkCollision(ctx, tenantControlPlane); err != nil {
+		logger.Error(err, "unable to provision the DataStore data")
+
+		return reconciliationResult, err
+	}
+
+	renameResult, err := r.renameDB(ctx, tenantControlPlane)
 	if err != nil {
-		logger.Error(err, "unable to create the DataStore user")
+		logger.Error(err, "unable to rename the DataStore schema")
 
 		return reconciliationResult, err
 	}
-	reconciliationResult = utils.UpdateOperationResult(reconciliationResult, operationResult)
+
+	var operationResult, dbResult, userResult controllerutil.OperationResult
+	// The database creation and the user checks are performed concurrently, reducing the provisioning latency:
+	// the user creation is waiting for the database one, being notified of its outcome.
+	var dbErr, userErr error
+
+	dbCreated := make(chan error, 1)
+
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		dbResult, dbErr = r.createDB(ctx, tenantControlPlane)
+		dbCreated <- dbErr
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		userResult, userErr = r.createUser(ctx, tenantControlPlane, dbCreated)
+	}()
+
+	wg.Wait()
+
+	if dbErr != nil {
+		logger.Error(dbErr, "unable to create the DataStore data")
+
+		return reconciliationResult, dbErr
+	}
+	dbResult = utils.UpdateOperationResult(renameResult, dbResult)
+	reconciliationResult = utils.UpdateOperationResult(reconciliationResult, dbResult)
+
+	if userErr != nil {
+		logger.Error(userErr, "unable to create the DataStore user")
+
+		return reconciliationResult, userErr
+	}
+	reconciliationResult = utils.UpdateOperationResult(reconciliationResult, userResult)
 
 	operationResult, err = r.createGrantPrivileges(ctx, tenantControlPlane)
 	if err != nil {
@@ -125,33 +333,200 @@ func (r *Setup) CreateOrUpdate(ctx context.Context, tenantControlPlane *kamajiv1
 
 		return reconciliationResult, err
 	}
+	// The privileges are granted again upon the kine upgrades, covering the schema objects they could rely on.
+	if operationResult == controllerutil.OperationResultNone && r.isKineUpgraded(tenantControlPlane) {
+		if err = r.Connection.GrantPrivilegesWithOptions(ctx, r.resource.user, r.resource.schema, r.grantOptions(tenantControlPlane)); err != nil {
+			logger.Error(err, "unable to grant the DataStore user privileges upon the kine upgrade")
+
+			return reconciliationResult, err
+		}
+
+		logger.Info("DataStore user privileges have been ensured for the kine upgrade", "previous", tenantControlPlane.Status.Storage.Setup.KineImage, "kineImage", r.kineImage())
+
+		operationResult = controllerutil.OperationResultUpdated
+	}
 	reconciliationResult = utils.UpdateOperationResult(reconciliationResult, operationResult)
+	// The privileges are granted along with the user creation.
+	if userResult == controllerutil.OperationResultCreated {
+		operationResult = controllerutil.OperationResultCreated
+	}
+
+	// The schemas created out of band could have a different encoding, which is reported rather than re-creating them.
+	if r.Connection.Capabilities().Encoding {
+		if err = r.checkEncoding(ctx); err != nil {
+			logger.Error(err, "unable to check the DataStore schema encoding")
+
+			return reconciliationResult, err
+		}
+	}
+
+	r.changes = kamajiv1alpha1.DataStoreSetupChanges{
+		Schema:     string(dbResult),
+		User:       string(userResult),
+		Privileges: string(operationResult),
+	}
+
+	if err = r.Connection.Annotate(ctx, r.resource.user, r.resource.schema, fmt.Sprintf("%s/%s", tenantControlPlane.GetNamespace(), tenantControlPlane.GetName())); err != nil {
+		logger.Error(err, "unable to annotate the DataStore data with the tenant ownership")
+
+		return reconciliationResult, err
+	}
+
+	// The extensions are installed again along with the database, or upon the changes to the list.
+	if r.Connection.Capabilities().Extensions && (dbResult == controllerutil.OperationResultCreated || tenantControlPlane.Status.Storage.Setup.ExtensionsChecksum != r.extensionsChecksum() || r.isKineUpgraded(tenantControlPlane)) {
+		if err = r.Connection.EnsureExtensions(ctx, r.resource.schema, r.extensions()); err != nil {
+			logger.Error(err, "unable to install the DataStore extensions")
+
+			return reconciliationResult, err
+		}
+	}
+
+	// The search_path is set along with the user creation, or upon its changes.
+	if r.Connection.Capabilities().SearchPath && (userResult != controllerutil.OperationResultNone || tenantControlPlane.Status.Storage.Setup.SearchPathChecksum != r.searchPathChecksum()) {
+		if err = r.Connection.SetUserSearchPath(ctx, r.resource.user, r.resource.schema, r.searchPath()); err != nil {
+			logger.Error(err, "unable to set the DataStore user search path")
+
+			return reconciliationResult, err
+		}
+	}
+
+	// The password expiry is set along with the user creation, or adoption, and refreshed before lapsing.
+	if r.Connection.Capabilities().Passwords && !r.isSharedUser(tenantControlPlane) && ((userResult != controllerutil.OperationResultNone && r.passwordExpiry() > 0) || r.isPasswordExpiryDue(tenantControlPlane)) {
+		if err = r.refreshPasswordExpiry(ctx); err != nil {
+			logger.Error(err, "unable to refresh the DataStore user password expiry")
+
+			return reconciliationResult, err
+		}
+	}
+
+	// Reporting the privileges actually held by the user, such as for the compliance reviews, rather than the expected ones.
+	if r.grantedPrivileges, err = r.Connection.GrantedPrivileges(ctx, r.resource.user, r.resource.schema); err != nil {
+		logger.Error(err, "unable to retrieve the DataStore user granted privileges")
+
+		return reconciliationResult, err
+	}
+
+	r.provisioned = reconciliationResult != controllerutil.OperationResultNone
+	if r.provisioned {
+		logger.Info("DataStore has been provisioned", "schema", r.changes.Schema, "user", r.changes.User, "privileges", r.changes.Privileges)
+	}
 
 	return reconciliationResult, nil
 }
 
-func (r *Setup) GetName() string {
-	return "datastore-setup"
+// logCurrentUser logs at debug level the user Kamaji is authenticated as against the DataStore,
+// helping to troubleshoot the permission issues due to wrong credentials or proxies rewriting them.
+func (r *Setup) logCurrentUser(ctx context.Context) {
+	logger := log.FromContext(ctx, "resource", r.GetName())
+
+	user, err := r.Connection.CurrentUser(ctx)
+	if err != nil {
+		logger.V(1).Info("unable to retrieve the DataStore current user", "datastore", r.DataStore.GetName(), "error", err.Error())
+
+		return
+	}
+
+	if previous, loaded := currentUsers.Load(r.DataStore.GetName()); loaded && previous == user {
+		return
+	}
+
+	currentUsers.Store(r.DataStore.GetName(), user)
+
+	logger.V(1).Info("authenticated against the DataStore", "datastore", r.DataStore.GetName(), "user", user)
 }
 
-func (r *Setup) Delete(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
+// waitDeletionGracePeriod disables the user upon the first deletion request, recording its time in the status:
+// a DeletionGracePeriodError is returned until the grace period has elapsed, keeping the data recoverable.
+func (r *Setup) waitDeletionGracePeriod(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane, gracePeriod time.Duration) error {
 	logger := log.FromContext(ctx, "resource", r.GetName())
 
-	if err := r.revokeGrantPrivileges(ctx, tenantControlPlane); err != nil {
-		logger.Error(err, "unable to revoke privileges")
+	requestedAt := tenantControlPlane.Status.Storage.Setup.DeletionRequestedAt
+	if requestedAt == nil {
+		if err := r.disableUser(ctx, tenantControlPlane); err != nil {
+			logger.Error(err, "unable to disable the DataStore user")
 
-		return err
+			return err
+		}
+
+		now := metav1.Now()
+		requestedAt = &now
+
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			tcp := &kamajiv1alpha1.TenantControlPlane{}
+			if err := r.Client.Get(ctx, types.NamespacedName{Name: tenantControlPlane.GetName(), Namespace: tenantControlPlane.GetNamespace()}, tcp); err != nil {
+				return err
+			}
+
+			tcp.Status.Storage.Setup.DeletionRequestedAt = requestedAt
+
+			return r.Client.Status().Update(ctx, tcp)
+		})
+		if err != nil {
+			logger.Error(err, "unable to record the DataStore data deletion request")
+
+			return err
+		}
+
+		tenantControlPlane.Status.Storage.Setup.DeletionRequestedAt = requestedAt
+
+		logger.Info("DataStore user has been disabled, waiting for the deletion grace period", "user", r.resource.user, "gracePeriod", gracePeriod.String())
 	}
 
-	if err := r.deleteDB(ctx, tenantControlPlane); err != nil {
-		logger.Error(err, "unable to delete datastore data")
+	if remaining := gracePeriod - time.Since(requestedAt.Time); remaining > 0 {
+		return kamajierrors.DeletionGracePeriodError{RequeueAfter: remaining}
+	}
 
-		return err
+	return nil
+}
+
+// isUpToDate returns true if the driver, the configuration, and the resulting user and schema didn't change
+// since the last setup, and the last verification against the DataStore is not older than the drift check interval.
+func (r *Setup) isUpToDate(tenantControlPlane *kamajiv1alpha1.TenantControlPlane) bool {
+	storage := tenantControlPlane.Status.Storage
+
+	return storage.Driver == string(r.DataStore.Spec.Driver) &&
+		storage.Setup.Checksum == storage.Config.Checksum &&
+		storage.Setup.User == r.resource.user &&
+		storage.Setup.Schema == r.resource.schema &&
+		storage.Setup.GrantOption == r.grantOptions(tenantControlPlane).WithGrantOption &&
+		storage.Setup.ExtensionsChecksum == r.extensionsChecksum() &&
+		storage.Setup.SearchPathChecksum == r.searchPathChecksum() &&
+		storage.Setup.Tablespace == r.tablespace(tenantControlPlane) &&
+		storage.Setup.SharedUser == r.isSharedUser(tenantControlPlane) &&
+		storage.Setup.KineImage == r.kineImage() &&
+		time.Since(storage.Setup.LastUpdate.Time) < r.driftCheckInterval()
+}
+
+func (r *Setup) driftCheckInterval() time.Duration {
+	if r.DriftCheckInterval > 0 {
+		return r.DriftCheckInterval
 	}
 
-	if err := r.deleteUser(ctx, tenantControlPlane); err != nil {
-		logger.Error(err, "unable to delete user")
+	return DefaultDriftCheckInterval
+}
+
+func (r *Setup) readyTimeout() time.Duration {
+	if r.ReadyTimeout > 0 {
+		return r.ReadyTimeout
+	}
+
+	return DefaultReadyTimeout
+}
 
+func (r *Setup) GetName() string {
+	return "datastore-setup"
+}
+
+func (r *Setup) Delete(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
+	logger := log.FromContext(ctx, "resource", r.GetName())
+
+	if gracePeriod := r.DataStore.Spec.DeletionGracePeriod; gracePeriod != nil && gracePeriod.Duration > 0 {
+		if err := r.waitDeletionGracePeriod(ctx, tenantControlPlane, gracePeriod.Duration); err != nil {
+			return err
+		}
+	}
+
+	if err := r.Purge(ctx, tenantControlPlane); err != nil {
 		return err
 	}
 
@@ -172,33 +547,294 @@ func (r *Setup) Delete(ctx context.Context, tenantControlPlane *kamajiv1alpha1.T
 	return nil
 }
 
+// Purge removes the Tenant Control Plane data from the DataStore, revoking the privileges and deleting the user,
+// as well as the schema according to the DataStore retention policy: when the schema is retained, the user is
+// disabled rather than deleted, since it still owns the retained objects, such as the PostgreSQL database.
+func (r *Setup) Purge(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
+	logger := log.FromContext(ctx, "resource", r.GetName())
+
+	if err := r.revokeGrantPrivileges(ctx, tenantControlPlane); err != nil {
+		logger.Error(err, "unable to revoke privileges")
+
+		return err
+	}
+
+	if r.DataStore.Spec.RetentionPolicy == kamajiv1alpha1.RetainRetentionPolicy {
+		if err := r.retainDB(ctx, tenantControlPlane); err != nil {
+			logger.Error(err, "unable to track the retained datastore data")
+
+			return err
+		}
+
+		if r.isSharedUser(tenantControlPlane) {
+			return nil
+		}
+		// The disabled user is meant to be deleted by the DataStore administrators along with the retained schema.
+		exists, err := r.Connection.UserExists(ctx, r.resource.user)
+		if err != nil {
+			logger.Error(err, "unable to check if user exists")
+
+			return err
+		}
+
+		if !exists {
+			return nil
+		}
+
+		if err = r.disableUser(ctx, tenantControlPlane); err != nil {
+			logger.Error(err, "unable to disable user")
+
+			return err
+		}
+
+		return nil
+	}
+
+	if err := r.deleteDB(ctx, tenantControlPlane); err != nil {
+		logger.Error(err, "unable to delete datastore data")
+
+		return err
+	}
+
+	// The shared user is used by other tenants, thus only its privileges on the schema are revoked.
+	if r.isSharedUser(tenantControlPlane) {
+		logger.Info("preserving the shared DataStore user", "user", r.resource.user)
+
+		return nil
+	}
+
+	if err := r.deleteUser(ctx, tenantControlPlane); err != nil {
+		logger.Error(err, "unable to delete user")
+
+		return err
+	}
+
+	return nil
+}
+
 func (r *Setup) UpdateTenantControlPlaneStatus(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
 	tenantControlPlane.Status.Storage.Setup.Schema = r.resource.schema
 	tenantControlPlane.Status.Storage.Setup.User = r.resource.user
 	tenantControlPlane.Status.Storage.Setup.LastUpdate = metav1.Now()
 	tenantControlPlane.Status.Storage.Setup.Checksum = tenantControlPlane.Status.Storage.Config.Checksum
+	tenantControlPlane.Status.Storage.Setup.GrantOption = r.grantOptions(tenantControlPlane).WithGrantOption
+	tenantControlPlane.Status.Storage.Setup.ExtensionsChecksum = r.extensionsChecksum()
+	tenantControlPlane.Status.Storage.Setup.SearchPathChecksum = r.searchPathChecksum()
+	tenantControlPlane.Status.Storage.Setup.Tablespace = r.tablespace(tenantControlPlane)
+	tenantControlPlane.Status.Storage.Setup.SharedUser = r.isSharedUser(tenantControlPlane)
+	tenantControlPlane.Status.Storage.Setup.KineImage = r.kineImage()
+
+	if r.disabled {
+		tenantControlPlane.Status.Storage.Setup.Disabled = true
+	}
+
+	if r.passwordExpiryRefreshed {
+		tenantControlPlane.Status.Storage.Setup.PasswordExpiresAt = r.passwordExpiresAt
+	}
+
+	if r.verified {
+		tenantControlPlane.Status.Storage.Setup.GrantedPrivileges = r.grantedPrivileges
+	}
+
+	if r.encodingChecked {
+		condition := metav1.Condition{
+			Type:               kamajiv1alpha1.DataStoreEncodingCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: tenantControlPlane.GetGeneration(),
+			Reason:             kamajiv1alpha1.DataStoreEncodingMatchReason,
+		}
+
+		if len(r.encodingMismatch) > 0 {
+			condition.Status, condition.Reason, condition.Message = metav1.ConditionFalse, kamajiv1alpha1.DataStoreEncodingMismatchReason, r.encodingMismatch
+		}
+
+		meta.SetStatusCondition(&tenantControlPlane.Status.Conditions, condition)
+	}
+
+	if len(r.templateDatabase) > 0 {
+		tenantControlPlane.Status.Storage.Setup.TemplateDatabase = r.templateDatabase
+	}
+
+	if len(r.origin) > 0 && len(tenantControlPlane.Status.Storage.Setup.Origin) == 0 {
+		tenantControlPlane.Status.Storage.Setup.Origin = r.origin
+	}
+
+	if r.provisioned {
+		tenantControlPlane.Status.Storage.Setup.ProvisionedBy = r.OperatorIdentity
+		tenantControlPlane.Status.Storage.Setup.ProvisionedAt = tenantControlPlane.Status.Storage.Setup.LastUpdate
+		tenantControlPlane.Status.Storage.Setup.LastChanges = r.changes.DeepCopy()
+	}
 
 	return nil
 }
 
-func (r *Setup) createDB(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error) {
+// renameDB renames in place the schema tracked in the status when it diverges from the expected one, such as upon
+// the kamaji.clastix.io/rename-datastore-schema annotation, rather than creating a new empty schema:
+// the privileges are preserved, and they're ensured anyway by the next steps.
+func (r *Setup) renameDB(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error) {
+	previous := tenantControlPlane.Status.Storage.Setup.Schema
+	if len(previous) == 0 || previous == r.resource.schema {
+		return controllerutil.OperationResultNone, nil
+	}
+
+	previousExists, err := r.Connection.DBExists(ctx, previous)
+	if err != nil {
+		return controllerutil.OperationResultNone, errors.Wrap(err, "unable to check if the previous datastore exists")
+	}
+
+	exists, err := r.Connection.DBExists(ctx, r.resource.schema)
+	if err != nil {
+		return controllerutil.OperationResultNone, errors.Wrap(err, "unable to check if datastore exists")
+	}
+
+	switch {
+	case !previousExists:
+		// Nothing to rename, such as when renamed by a previous reconciliation failing to update the status.
+		return controllerutil.OperationResultNone, nil
+	case exists:
+		return controllerutil.OperationResultNone, fmt.Errorf("cannot rename the datastore schema %s to %s, since already existing", previous, r.resource.schema)
+	case !r.Connection.Capabilities().RenameDatabase:
+		return controllerutil.OperationResultNone, fmt.Errorf("cannot rename the datastore schema %s to %s, since not supported by the %s driver", previous, r.resource.schema, r.Connection.Driver())
+	}
+
+	if err = r.Connection.RenameDatabase(ctx, previous, r.resource.schema); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	log.FromContext(ctx, "resource", r.GetName()).Info("DataStore schema has been renamed", "previous", previous, "schema", r.resource.schema)
+
+	return controllerutil.OperationResultUpdated, nil
+}
+
+func (r *Setup) createDB(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error) {
 	exists, err := r.Connection.DBExists(ctx, r.resource.schema)
 	if err != nil {
 		return controllerutil.OperationResultNone, errors.Wrap(err, "unable to check if datastore exists")
 	}
 
 	if exists {
+		// The existing schema is left untouched, preserving its data.
+		if isAdopting(tenantControlPlane) && tenantControlPlane.Status.Storage.Setup.Schema != r.resource.schema {
+			log.FromContext(ctx, "resource", r.GetName()).Info("adopting the existing schema", "schema", r.resource.schema)
+
+			r.origin = kamajiv1alpha1.DataStoreSetupOriginAdopted
+		}
+
 		return controllerutil.OperationResultNone, nil
 	}
 
-	if err := r.Connection.CreateDB(ctx, r.resource.schema); err != nil {
+	if err = r.checkSizeLimit(ctx); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	opts := datastore.CreateDBOptions{Tablespace: r.tablespace(tenantControlPlane)}
+	// Initializing the schema from the template database, when configured, such as a pre-seeded one.
+	if template := r.DataStore.Spec.TemplateDatabase; len(template) > 0 && r.Connection.Capabilities().CloneDatabase {
+		if err := r.Connection.CloneDB(ctx, r.resource.schema, template, opts); err != nil {
+			return controllerutil.OperationResultNone, errors.Wrap(err, "unable to clone the datastore")
+		}
+
+		r.templateDatabase = template
+	} else if err := r.Connection.CreateDBWithOptions(ctx, r.resource.schema, opts); err != nil {
 		return controllerutil.OperationResultNone, errors.Wrap(err, "unable to create the datastore")
 	}
 
+	r.origin = kamajiv1alpha1.DataStoreSetupOriginCreated
+
 	return controllerutil.OperationResultCreated, nil
 }
 
-func (r *Setup) deleteDB(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) error {
+// checkEncoding compares the character set and the collation of the schema against the ones it would have been created
+// with, such as for the schemas created out of band before being managed by Kamaji: the mismatch is reported,
+// rather than re-creating the schema, since its data must be converted by the DataStore administrators.
+func (r *Setup) checkEncoding(ctx context.Context) error {
+	var (
+		expected datastore.Encoding
+		err      error
+	)
+
+	if template := r.DataStore.Spec.TemplateDatabase; len(template) > 0 && r.Connection.Capabilities().CloneDatabase {
+		expected, err = r.Connection.DatabaseEncoding(ctx, template)
+	} else {
+		expected, err = r.Connection.DefaultEncoding(ctx)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve the expected datastore encoding")
+	}
+
+	current, err := r.Connection.DatabaseEncoding(ctx, r.resource.schema)
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve the datastore schema encoding")
+	}
+
+	r.encodingChecked = true
+	r.encodingMismatch = ""
+
+	if current == expected {
+		return nil
+	}
+
+	r.encodingMismatch = fmt.Sprintf("the datastore schema %s has the %s character set and the %s collation, rather than %s and %s: it must be converted manually", r.resource.schema, current.Charset, current.Collation, expected.Charset, expected.Collation)
+
+	log.FromContext(ctx, "resource", r.GetName()).Info("DataStore schema encoding mismatches the expected one, a manual intervention is required", "schema", r.resource.schema, "charset", current.Charset, "collation", current.Collation, "expectedCharset", expected.Charset, "expectedCollation", expected.Collation)
+
+	return nil
+}
+
+// checkCollision ensures the tenant schema, or etcd key prefix, is not already in use by a different tenant:
+// the resolved schema could collide, such as for the default-ns/tenant and default/ns-tenant Tenant Control Planes,
+// leading to the mixing of the tenants data.
+func (r *Setup) checkCollision(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
+	tcpList := &kamajiv1alpha1.TenantControlPlaneList{}
+	if err := r.Client.List(ctx, tcpList, client.MatchingFieldsSelector{
+		Selector: fields.OneTermEqualSelector(kamajiv1alpha1.TenantControlPlaneUsedDataStoreKey, r.DataStore.GetName()),
+	}); err != nil {
+		return errors.Wrap(err, "unable to list the Tenant Control Planes using the datastore")
+	}
+
+	for _, tcp := range tcpList.Items {
+		if tcp.GetUID() == tenantControlPlane.GetUID() {
+			continue
+		}
+
+		if tcp.Status.Storage.Setup.Schema == r.resource.schema {
+			return fmt.Errorf("the datastore schema %s is already in use by the Tenant Control Plane %s/%s", r.resource.schema, tcp.GetNamespace(), tcp.GetName())
+		}
+	}
+
+	overlaps, err := r.Connection.DBOverlaps(ctx, r.resource.schema)
+	if err != nil {
+		return errors.Wrap(err, "unable to check if datastore overlaps")
+	}
+
+	if overlaps {
+		return fmt.Errorf("the datastore schema %s overlaps with the one of a different tenant", r.resource.schema)
+	}
+
+	return nil
+}
+
+// checkSizeLimit refuses the provisioning of a new tenant schema when the DataStore is over its size limit,
+// protecting the shared DataStore from being overfilled.
+func (r *Setup) checkSizeLimit(ctx context.Context) error {
+	if r.DataStore.Spec.SizeLimit == nil {
+		return nil
+	}
+
+	size, err := r.Connection.DatastoreSize(ctx)
+	if err != nil {
+		return errors.Wrap(err, "unable to check the datastore size")
+	}
+
+	if limit := r.DataStore.Spec.SizeLimit.Value(); size > limit {
+		return datastoreerrors.NewQuotaExceededError(size, limit)
+	}
+
+	return nil
+}
+
+func (r *Setup) deleteDB(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
 	exists, err := r.Connection.DBExists(ctx, r.resource.schema)
 	if err != nil {
 		return errors.Wrap(err, "unable to check if datastore exists")
@@ -207,6 +843,17 @@ func (r *Setup) deleteDB(ctx context.Context, _ *kamajiv1alpha1.TenantControlPla
 	if !exists {
 		return nil
 	}
+	// Failing safe when the database doesn't belong to the tenant, rather than dropping a shared one by mistake.
+	tenant := fmt.Sprintf("%s/%s", tenantControlPlane.GetNamespace(), tenantControlPlane.GetName())
+
+	owned, err := r.Connection.DatabaseExistsForUser(ctx, r.resource.user, r.resource.schema, tenant)
+	if err != nil {
+		return errors.Wrap(err, "unable to check the datastore ownership")
+	}
+
+	if !owned {
+		return fmt.Errorf("the datastore %s doesn't belong to the tenant %s, refusing to delete it", r.resource.schema, tenant)
+	}
 
 	if err := r.Connection.DeleteDB(ctx, r.resource.schema); err != nil {
 		return errors.Wrap(err, "unable to delete the datastore")
@@ -215,23 +862,157 @@ func (r *Setup) deleteDB(ctx context.Context, _ *kamajiv1alpha1.TenantControlPla
 	return nil
 }
 
-func (r *Setup) createUser(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error) {
+// retainDB keeps track of the retained schema in the DataStore status, since it's not going to be deleted
+// along with the Tenant Control Plane and requires an explicit clean-up.
+func (r *Setup) retainDB(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ds := &kamajiv1alpha1.DataStore{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: r.DataStore.GetName()}, ds); err != nil {
+			return err
+		}
+
+		for _, schema := range ds.Status.RetainedSchemas {
+			if schema == r.resource.schema {
+				return nil
+			}
+		}
+
+		ds.Status.RetainedSchemas = append(ds.Status.RetainedSchemas, r.resource.schema)
+
+		return r.Client.Status().Update(ctx, ds)
+	})
+}
+
+// createUser creates the user along with its privileges, granted once the database creation outcome is notified
+// by the given channel: the user creation is reverted if the database has not been created, and the existing
+// users are handled only once created, since their checks are performed against the schema.
+func (r *Setup) createUser(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane, dbCreated <-chan error) (controllerutil.OperationResult, error) {
 	exists, err := r.Connection.UserExists(ctx, r.resource.user)
 	if err != nil {
 		return controllerutil.OperationResultNone, errors.Wrap(err, "unable to check if user exists")
 	}
 
+	// Waiting for the database before opening the transaction, rather than holding it open in the meanwhile.
+	select {
+	case err = <-dbCreated:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		return controllerutil.OperationResultNone, errors.Wrap(err, "unable to set up the user, the database has not been created")
+	}
+
 	if exists {
-		return controllerutil.OperationResultNone, nil
+		return r.handleExistingUser(ctx, tenantControlPlane)
 	}
+	// Creating the user along with its database privileges atomically, where supported by the driver,
+	// avoiding a user with no grants upon a partial failure.
+	err = r.Connection.Transaction(ctx, func(ctx context.Context, tx datastore.Connection) error {
+		if err := tx.CreateUser(ctx, r.resource.user, r.resource.password); err != nil {
+			return errors.Wrap(err, "unable to create the user")
+		}
+
+		if err := tx.GrantPrivilegesWithOptions(ctx, r.resource.user, r.resource.schema, r.grantOptions(tenantControlPlane)); err != nil {
+			return errors.Wrap(err, "unable to grant privileges")
+		}
 
-	if err := r.Connection.CreateUser(ctx, r.resource.user, r.resource.password); err != nil {
-		return controllerutil.OperationResultNone, errors.Wrap(err, "unable to create the user")
+		return nil
+	})
+	// The user could have been created in the meanwhile, racing with the existence check.
+	if userErr := (datastoreerrors.UserAlreadyExistsError{}); errors.As(err, &userErr) {
+		return r.handleExistingUser(ctx, tenantControlPlane)
+	}
+
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	// The privileges on the objects of the tenant database, such as the kine table ownership and the default
+	// privileges, can't be part of the server database transaction, thus they're granted once committed.
+	if r.Connection.Capabilities().Transactions {
+		if err = r.Connection.GrantPrivilegesWithOptions(ctx, r.resource.user, r.resource.schema, r.grantOptions(tenantControlPlane)); err != nil {
+			return controllerutil.OperationResultNone, errors.Wrap(err, "unable to grant privileges")
+		}
 	}
 
 	return controllerutil.OperationResultCreated, nil
 }
 
+// handleExistingUser applies the ExistingUserPolicy to the users not provisioned by Kamaji,
+// such as the ones created out of band by the DataStore administrators.
+func (r *Setup) handleExistingUser(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error) {
+	// Adopting the shared user as it is, since its password and login are managed out of band.
+	if r.isSharedUser(tenantControlPlane) {
+		if !tenantControlPlane.Status.Storage.Setup.SharedUser {
+			log.FromContext(ctx, "resource", r.GetName()).Info("adopting the shared user", "user", r.resource.user)
+		}
+
+		return controllerutil.OperationResultNone, nil
+	}
+	if tenantControlPlane.Status.Storage.Setup.User == r.resource.user {
+		return r.ensureUserLogin(ctx, tenantControlPlane)
+	}
+	// Adopting the user regardless of the policy, and of its privileges, since its password is not known.
+	if isAdopting(tenantControlPlane) {
+		return r.adoptUser(ctx)
+	}
+	// A user already granted the privileges on the schema has been provisioned by Kamaji,
+	// although the status has not been updated yet.
+	granted, err := r.Connection.GrantPrivilegesExists(ctx, r.resource.user, r.resource.schema)
+	if err != nil {
+		return controllerutil.OperationResultNone, errors.Wrap(err, "unable to check if privileges exist")
+	}
+
+	if granted {
+		return controllerutil.OperationResultNone, nil
+	}
+
+	switch r.ExistingUserPolicy {
+	case AdoptExistingUserPolicy:
+		return r.adoptUser(ctx)
+	default:
+		return controllerutil.OperationResultNone, fmt.Errorf("the user %s already exists in the DataStore %s although not provisioned by Kamaji, refusing to use it according to the %s policy", r.resource.user, r.DataStore.GetName(), FailExistingUserPolicy)
+	}
+}
+
+// adoptUser takes over the user created out of band by setting the managed password:
+// the privileges are granted by the createGrantPrivileges handler.
+func (r *Setup) adoptUser(ctx context.Context) (controllerutil.OperationResult, error) {
+	log.FromContext(ctx, "resource", r.GetName()).Info("adopting the user created out of band", "user", r.resource.user)
+
+	if err := r.Connection.SetUserPassword(ctx, r.resource.user, r.resource.password); err != nil {
+		return controllerutil.OperationResultNone, errors.Wrap(err, "unable to adopt the user")
+	}
+
+	return controllerutil.OperationResultUpdated, nil
+}
+
+// ensureUserLogin restores the login of the managed user when it has been disabled out of band, such as by the
+// DataStore administrators: the lockout requested by the kamaji.clastix.io/disable-datastore-user annotation
+// is not reverted, since it must be restored manually.
+func (r *Setup) ensureUserLogin(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error) {
+	if tenantControlPlane.Status.Storage.Setup.Disabled || !r.Connection.Capabilities().Passwords {
+		return controllerutil.OperationResultNone, nil
+	}
+
+	hasLogin, err := r.Connection.UserHasLogin(ctx, r.resource.user)
+	if err != nil {
+		return controllerutil.OperationResultNone, errors.Wrap(err, "unable to check if user can log in")
+	}
+
+	if hasLogin {
+		return controllerutil.OperationResultNone, nil
+	}
+
+	log.FromContext(ctx, "resource", r.GetName()).Info("restoring the login of the user disabled out of band", "user", r.resource.user)
+
+	if err = r.Connection.SetUserPassword(ctx, r.resource.user, r.resource.password); err != nil {
+		return controllerutil.OperationResultNone, errors.Wrap(err, "unable to restore the user login")
+	}
+
+	return controllerutil.OperationResultUpdated, nil
+}
+
 func (r *Setup) deleteUser(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) error {
 	exists, err := r.Connection.UserExists(ctx, r.resource.user)
 	if err != nil {
@@ -249,8 +1030,10 @@ func (r *Setup) deleteUser(ctx context.Context, _ *kamajiv1alpha1.TenantControlP
 	return nil
 }
 
-func (r *Setup) createGrantPrivileges(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error) {
-	exists, err := r.Connection.GrantPrivilegesExists(ctx, r.resource.user, r.resource.schema)
+func (r *Setup) createGrantPrivileges(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error) {
+	opts := r.grantOptions(tenantControlPlane)
+
+	exists, err := r.Connection.GrantPrivilegesWithOptionsExists(ctx, r.resource.user, r.resource.schema, opts)
 	if err != nil {
 		return controllerutil.OperationResultNone, errors.Wrap(err, "unable to check if privileges exist")
 	}
@@ -259,13 +1042,178 @@ func (r *Setup) createGrantPrivileges(ctx context.Context, _ *kamajiv1alpha1.Ten
 		return controllerutil.OperationResultNone, nil
 	}
 
-	if err := r.Connection.GrantPrivileges(ctx, r.resource.user, r.resource.schema); err != nil {
+	if err := r.Connection.GrantPrivilegesWithOptions(ctx, r.resource.user, r.resource.schema, opts); err != nil {
 		return controllerutil.OperationResultNone, errors.Wrap(err, "unable to grant privileges")
 	}
 
 	return controllerutil.OperationResultCreated, nil
 }
 
+// kineImage returns the kine image the privileges are ensured for, empty for the etcd driver, which is not relying on it.
+func (r *Setup) kineImage() string {
+	if r.DataStore.Spec.Driver == kamajiv1alpha1.EtcdDriver {
+		return ""
+	}
+
+	return r.KineImage
+}
+
+// isKineUpgraded returns true if the kine image changed since the privileges have been ensured,
+// ignoring the Tenant Control Planes provisioned before its tracking.
+func (r *Setup) isKineUpgraded(tenantControlPlane *kamajiv1alpha1.TenantControlPlane) bool {
+	previous := tenantControlPlane.Status.Storage.Setup.KineImage
+
+	return len(previous) > 0 && previous != r.kineImage()
+}
+
+// extensions returns the extensions to install in the database, as specified by the DataStore.
+func (r *Setup) extensions() []string {
+	extensions := make([]string, 0, len(r.DataStore.Spec.Extensions))
+
+	for _, extension := range r.DataStore.Spec.Extensions {
+		extensions = append(extensions, string(extension))
+	}
+
+	return extensions
+}
+
+// extensionsChecksum returns the checksum of the extensions to install, regardless of their order:
+// it's empty when no extensions are specified.
+func (r *Setup) extensionsChecksum() string {
+	if len(r.DataStore.Spec.Extensions) == 0 {
+		return ""
+	}
+
+	extensions := r.extensions()
+	sort.Strings(extensions)
+
+	return utilities.CalculateMapChecksum(map[string]string{"extensions": strings.Join(extensions, ",")})
+}
+
+// searchPath returns the default search_path of the user, as specified by the DataStore, or the public schema.
+func (r *Setup) searchPath() []string {
+	if len(r.DataStore.Spec.SearchPath) == 0 {
+		return []string{"public"}
+	}
+
+	searchPath := make([]string, 0, len(r.DataStore.Spec.SearchPath))
+
+	for _, schema := range r.DataStore.Spec.SearchPath {
+		searchPath = append(searchPath, string(schema))
+	}
+
+	return searchPath
+}
+
+// searchPathChecksum returns the checksum of the search_path, empty for the drivers not supporting it.
+func (r *Setup) searchPathChecksum() string {
+	if !r.Connection.Capabilities().SearchPath {
+		return ""
+	}
+	// The order is relevant, since the schemas are looked up in sequence.
+	return utilities.CalculateMapChecksum(map[string]string{"searchPath": strings.Join(r.searchPath(), ",")})
+}
+
+// passwordExpiry returns the lifetime of the user password, as specified by the DataStore: zero when it never expires.
+func (r *Setup) passwordExpiry() time.Duration {
+	if r.DataStore.Spec.PasswordExpiry == nil {
+		return 0
+	}
+
+	return r.DataStore.Spec.PasswordExpiry.Duration
+}
+
+// isPasswordExpiryDue reports if the password expiry must be refreshed, since half of its lifetime has elapsed,
+// or it has been changed, as well as removed from the DataStore.
+func (r *Setup) isPasswordExpiryDue(tenantControlPlane *kamajiv1alpha1.TenantControlPlane) bool {
+	if r.isSharedUser(tenantControlPlane) {
+		return false
+	}
+
+	expiresAt, expiry := tenantControlPlane.Status.Storage.Setup.PasswordExpiresAt, r.passwordExpiry()
+
+	if expiry == 0 {
+		return expiresAt != nil
+	}
+
+	if expiresAt == nil {
+		return true
+	}
+
+	remaining := time.Until(expiresAt.Time)
+
+	return remaining < expiry/2 || remaining > expiry
+}
+
+// refreshPasswordExpiry sets again the password, resetting its lifetime where it's counted from the latest change,
+// along with its expiry.
+func (r *Setup) refreshPasswordExpiry(ctx context.Context) error {
+	expiry := r.passwordExpiry()
+
+	if expiry > 0 {
+		if err := r.Connection.SetUserPassword(ctx, r.resource.user, r.resource.password); err != nil {
+			return errors.Wrap(err, "unable to refresh the user password")
+		}
+	}
+
+	if err := r.Connection.SetUserPasswordExpiry(ctx, r.resource.user, expiry); err != nil {
+		return errors.Wrap(err, "unable to set the user password expiry")
+	}
+
+	r.passwordExpiryRefreshed, r.passwordExpiresAt = true, nil
+
+	if expiry > 0 {
+		expiresAt := metav1.NewTime(time.Now().Add(expiry))
+		r.passwordExpiresAt = &expiresAt
+	}
+
+	return nil
+}
+
+// isAdopting reports if the existing datastore data of the Tenant Control Plane must be taken over
+// by means of the kamaji.clastix.io/adopt-datastore annotation.
+func isAdopting(tenantControlPlane *kamajiv1alpha1.TenantControlPlane) bool {
+	_, ok := tenantControlPlane.GetAnnotations()[constants.AdoptDataStore]
+
+	return ok
+}
+
+// isSharedUser reports if the user is shared across the tenant schemas, as configured by the DataStore,
+// or as tracked in the status, such as when the DataStore shared user has been removed afterwards.
+func (r *Setup) isSharedUser(tenantControlPlane *kamajiv1alpha1.TenantControlPlane) bool {
+	if tenantControlPlane.Status.Storage.Setup.SharedUser && tenantControlPlane.Status.Storage.Setup.User == r.resource.user {
+		return true
+	}
+
+	sharedUser := r.DataStore.Spec.SharedUser
+
+	return sharedUser != nil && sharedUser.Name == r.resource.user
+}
+
+// disableUser cuts the user off from the schema: the shared user is used by other tenants,
+// thus its privileges on the schema are revoked, rather than disabling its login.
+func (r *Setup) disableUser(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
+	if r.isSharedUser(tenantControlPlane) {
+		return r.revokeGrantPrivileges(ctx, tenantControlPlane)
+	}
+
+	return r.Connection.RevokeAllAndDisableUser(ctx, r.resource.user, r.resource.schema)
+}
+
+// grantOptions returns the options of the privileges granted to the user, according to the DataStore,
+// unless overridden by the Tenant Control Plane annotation.
+func (r *Setup) grantOptions(tenantControlPlane *kamajiv1alpha1.TenantControlPlane) datastore.GrantOptions {
+	opts := datastore.GrantOptions{WithGrantOption: r.DataStore.Spec.WithGrantOption}
+
+	if value, ok := tenantControlPlane.GetAnnotations()[constants.DataStoreGrantOption]; ok {
+		if withGrantOption, err := strconv.ParseBool(value); err == nil {
+			opts.WithGrantOption = withGrantOption
+		}
+	}
+
+	return opts
+}
+
 func (r *Setup) revokeGrantPrivileges(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) error {
 	exists, err := r.Connection.GrantPrivilegesExists(ctx, r.resource.user, r.resource.schema)
 	if err != nil {
@@ -282,3 +1230,17 @@ func (r *Setup) revokeGrantPrivileges(ctx context.Context, _ *kamajiv1alpha1.Ten
 
 	return nil
 }
+
+// tablespace returns the tablespace the database is created in, overridden by the Tenant Control Plane annotation:
+// it's empty when not supported by the driver, using the default one.
+func (r *Setup) tablespace(tenantControlPlane *kamajiv1alpha1.TenantControlPlane) string {
+	if !r.Connection.Capabilities().Tablespaces {
+		return ""
+	}
+
+	if value, ok := tenantControlPlane.GetAnnotations()[constants.DataStoreTablespace]; ok {
+		return value
+	}
+
+	return r.DataStore.Spec.Tablespace
+}
@@ -0,0 +1,214 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/pkg/datastore/datastoretest"
+	datastoreerrors "github.com/clastix/kamaji/pkg/datastore/errors"
+)
+
+var _ = Describe("DataStore setup", func() {
+	var (
+		ctx        context.Context
+		dataStore  kamajiv1alpha1.DataStore
+		connection *datastoretest.Connection
+		tcp        *kamajiv1alpha1.TenantControlPlane
+		setup      *Setup
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dataStore = kamajiv1alpha1.DataStore{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec:       kamajiv1alpha1.DataStoreSpec{Driver: kamajiv1alpha1.KinePostgreSQLDriver},
+		}
+		connection = datastoretest.NewConnection()
+		tcp = newTestTenantControlPlane(dataStore)
+		setup = newTestSetup(dataStore, connection, tcp, newTestConfigSecret(tcp, dataStore, "secret"))
+	})
+
+	It("should provision the schema, the user, and its privileges", func() {
+		Expect(setup.Define(ctx, tcp)).To(Succeed())
+
+		result, err := setup.CreateOrUpdate(ctx, tcp)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).ToNot(BeEmpty())
+
+		Expect(connection.DBs).To(HaveKey("default_test"))
+		Expect(connection.Users).To(HaveKeyWithValue("default_test", "secret"))
+		Expect(connection.Grants["default_test"]).To(HaveKey("default_test"))
+	})
+
+	It("should grant the privileges reported by the single privilege check", func() {
+		Expect(connection.HasPrivilege(ctx, "default_test", "default_test", "CREATE")).To(BeFalse())
+
+		Expect(setup.Define(ctx, tcp)).To(Succeed())
+		_, err := setup.CreateOrUpdate(ctx, tcp)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(connection.HasPrivilege(ctx, "default_test", "default_test", "CREATE")).To(BeTrue())
+		Expect(connection.HasPrivilege(ctx, "default_test", "another", "CREATE")).To(BeFalse())
+	})
+
+	It("should succeed when two setups race on the same schema and user", func() {
+		// The overlapping reconciliations, such as upon the leader changeover, share the datastore only.
+		setups := []*Setup{
+			setup,
+			newTestSetup(dataStore, connection, tcp.DeepCopy(), newTestConfigSecret(tcp, dataStore, "secret")),
+		}
+
+		errs := make(chan error, len(setups))
+
+		for _, s := range setups {
+			Expect(s.Define(ctx, tcp.DeepCopy())).To(Succeed())
+
+			go func(s *Setup) {
+				defer GinkgoRecover()
+				// The setup losing the lock is enqueued back, as the controller does.
+				for {
+					_, err := s.CreateOrUpdate(ctx, tcp.DeepCopy())
+					if !errors.As(err, &datastoreerrors.LockedError{}) {
+						errs <- err
+
+						return
+					}
+				}
+			}(s)
+		}
+
+		for range setups {
+			Expect(<-errs).ToNot(HaveOccurred())
+		}
+
+		Expect(connection.DBs).To(HaveKey("default_test"))
+		Expect(connection.Users).To(HaveKeyWithValue("default_test", "secret"))
+		Expect(connection.Grants["default_test"]).To(HaveKey("default_test"))
+	})
+
+	It("should disable the user of a retained schema, rather than deleting it", func() {
+		setup.DataStore.Spec.RetentionPolicy = kamajiv1alpha1.RetainRetentionPolicy
+		setup = newTestSetup(setup.DataStore, connection, tcp, newTestConfigSecret(tcp, dataStore, "secret"), &setup.DataStore)
+
+		Expect(setup.Define(ctx, tcp)).To(Succeed())
+		_, err := setup.CreateOrUpdate(ctx, tcp)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(setup.Purge(ctx, tcp)).To(Succeed())
+
+		Expect(connection.DBs).To(HaveKey("default_test"))
+		Expect(connection.Users).To(HaveKey("default_test"))
+		Expect(connection.Disabled).To(HaveKey("default_test"))
+		Expect(connection.Grants["default_test"]).ToNot(HaveKey("default_test"))
+	})
+
+	It("should not create the user when the database creation fails", func() {
+		connection.Errors["CreateDB"] = errors.New("disk full")
+
+		Expect(setup.Define(ctx, tcp)).To(Succeed())
+
+		_, err := setup.CreateOrUpdate(ctx, tcp)
+		Expect(err).To(MatchError(ContainSubstring("disk full")))
+
+		Expect(connection.DBs).ToNot(HaveKey("default_test"))
+		Expect(connection.Users).ToNot(HaveKey("default_test"))
+	})
+
+	Context("when the login of the managed user has been disabled out of band", func() {
+		BeforeEach(func() {
+			Expect(setup.Define(ctx, tcp)).To(Succeed())
+			_, err := setup.CreateOrUpdate(ctx, tcp)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(setup.UpdateTenantControlPlaneStatus(ctx, tcp)).To(Succeed())
+			// The drift is detected once the drift check interval has elapsed since the last verification.
+			tcp.Status.Storage.Setup.LastUpdate = metav1.NewTime(time.Now().Add(-DefaultDriftCheckInterval))
+
+			connection.Disabled["default_test"] = struct{}{}
+		})
+
+		It("should restore the login", func() {
+			Expect(connection.UserHasLogin(ctx, "default_test")).To(BeFalse())
+
+			Expect(setup.Define(ctx, tcp)).To(Succeed())
+			result, err := setup.CreateOrUpdate(ctx, tcp)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(controllerutil.OperationResultUpdated))
+
+			Expect(connection.UserHasLogin(ctx, "default_test")).To(BeTrue())
+			Expect(connection.Users).To(HaveKeyWithValue("default_test", "secret"))
+		})
+
+		It("should not restore the login disabled by the annotation", func() {
+			tcp.Status.Storage.Setup.Disabled = true
+
+			Expect(setup.Define(ctx, tcp)).To(Succeed())
+			_, err := setup.CreateOrUpdate(ctx, tcp)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(connection.UserHasLogin(ctx, "default_test")).To(BeFalse())
+		})
+	})
+
+	Context("when the user already exists", func() {
+		BeforeEach(func() {
+			connection.Users["default_test"] = "out-of-band"
+		})
+
+		It("should adopt it, setting the managed password", func() {
+			Expect(setup.Define(ctx, tcp)).To(Succeed())
+
+			_, err := setup.CreateOrUpdate(ctx, tcp)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(connection.Users).To(HaveKeyWithValue("default_test", "secret"))
+			Expect(connection.Grants["default_test"]).To(HaveKey("default_test"))
+		})
+
+		It("should refuse to use it according to the Fail policy", func() {
+			setup.ExistingUserPolicy = FailExistingUserPolicy
+
+			Expect(setup.Define(ctx, tcp)).To(Succeed())
+
+			_, err := setup.CreateOrUpdate(ctx, tcp)
+			Expect(err).To(MatchError(ContainSubstring("refusing to use it")))
+
+			Expect(connection.Users).To(HaveKeyWithValue("default_test", "out-of-band"))
+			Expect(connection.Grants["default_test"]).ToNot(HaveKey("default_test"))
+		})
+
+		It("should keep using it when already granted the privileges", func() {
+			setup.ExistingUserPolicy = FailExistingUserPolicy
+			connection.DBs["default_test"] = struct{}{}
+			connection.Grants["default_test"] = map[string]struct{}{"default_test": {}}
+
+			Expect(setup.Define(ctx, tcp)).To(Succeed())
+
+			_, err := setup.CreateOrUpdate(ctx, tcp)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(connection.Users).To(HaveKeyWithValue("default_test", "out-of-band"))
+		})
+
+		It("should not adopt it until the database has been created", func() {
+			connection.CreateDBDelay = 100 * time.Millisecond
+			connection.Errors["CreateDB"] = errors.New("disk full")
+
+			Expect(setup.Define(ctx, tcp)).To(Succeed())
+
+			_, err := setup.CreateOrUpdate(ctx, tcp)
+			Expect(err).To(MatchError(ContainSubstring("disk full")))
+
+			Expect(connection.Users).To(HaveKeyWithValue("default_test", "out-of-band"))
+		})
+	})
+})
@@ -4,11 +4,13 @@
 package datastore
 
 import (
+	"bytes"
 	"context"
-	"fmt"
+	"net/url"
+	"reflect"
 	"strings"
 
-	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -16,6 +18,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/internal/resources"
 	"github.com/clastix/kamaji/internal/utilities"
 )
 
@@ -24,19 +27,23 @@ type Config struct {
 	Client     client.Client
 	ConnString string
 	DataStore  kamajiv1alpha1.DataStore
+	// PasswordGenerator generates the DataStore user password when not provided,
+	// defaulting to the random UUIDs when not specified.
+	PasswordGenerator PasswordGenerator
 }
 
 func (r *Config) ShouldStatusBeUpdated(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) bool {
 	return tenantControlPlane.Status.Storage.Config.Checksum != utilities.GetObjectChecksum(r.resource) ||
-		tenantControlPlane.Status.Storage.DataStoreName != r.DataStore.GetName()
+		tenantControlPlane.Status.Storage.DataStoreName != r.DataStore.GetName() ||
+		!reflect.DeepEqual(tenantControlPlane.Status.Storage.Endpoints, r.endpoints())
 }
 
 func (r *Config) ShouldCleanup(*kamajiv1alpha1.TenantControlPlane) bool {
 	return false
 }
 
-func (r *Config) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (bool, error) {
-	return false, nil
+func (r *Config) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (resources.CleanUpResult, error) {
+	return resources.CleanUpResultNone, nil
 }
 
 func (r *Config) Define(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
@@ -69,47 +76,88 @@ func (r *Config) GetName() string {
 func (r *Config) UpdateTenantControlPlaneStatus(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
 	tenantControlPlane.Status.Storage.Driver = string(r.DataStore.Spec.Driver)
 	tenantControlPlane.Status.Storage.DataStoreName = r.DataStore.GetName()
+	tenantControlPlane.Status.Storage.Endpoints = r.endpoints()
 	tenantControlPlane.Status.Storage.Config.SecretName = r.resource.GetName()
 	tenantControlPlane.Status.Storage.Config.Checksum = utilities.GetObjectChecksum(r.resource)
 
 	return nil
 }
 
-func (r *Config) mutate(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) controllerutil.MutateFn {
+// endpoints returns the DataStore endpoints stripped of any user information,
+// ensuring no secret material is leaked in the Tenant Control Plane status.
+func (r *Config) endpoints() []string {
+	endpoints := make([]string, 0, len(r.DataStore.Spec.Endpoints))
+
+	for _, endpoint := range r.DataStore.Spec.Endpoints {
+		if index := strings.LastIndex(endpoint, "@"); index >= 0 {
+			endpoint = endpoint[index+1:]
+		}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints
+}
+
+func (r *Config) passwordGenerator() PasswordGenerator {
+	if r.PasswordGenerator != nil {
+		return r.PasswordGenerator
+	}
+
+	return UUIDPasswordGenerator{}
+}
+
+// isSharedUser reports if the given user is the one shared across the tenant schemas, as configured by the DataStore.
+func (r *Config) isSharedUser(user string) bool {
+	sharedUser := r.DataStore.Spec.SharedUser
+
+	return sharedUser != nil && sharedUser.Name == user
+}
+
+func (r *Config) mutate(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) controllerutil.MutateFn {
 	return func() error {
 		var password []byte
 
+		schema, user := tenantControlPlane.DataStoreSchemaAndSharedUser(r.DataStore)
+
 		hash := utilities.GetObjectChecksum(r.resource)
-		switch {
-		case len(hash) > 0 && hash == utilities.CalculateMapChecksum(r.resource.Data):
-			password = r.resource.Data["DB_PASSWORD"]
+		// Leading and trailing whitespaces, such as the trailing newline added by base64 tooling,
+		// are trimmed to ensure the same password is used by the datastore user and the Tenant Control Plane.
+		switch storedPassword := bytes.TrimSpace(r.resource.Data["DB_PASSWORD"]); {
+		case r.isSharedUser(user):
+			// The shared user password is not managed by Kamaji, following the one provided by the DataStore.
+			sharedPassword, err := r.DataStore.Spec.SharedUser.Password.GetContent(ctx, r.Client)
+			if err != nil {
+				return errors.Wrap(err, "cannot retrieve the DataStore shared user password")
+			}
+
+			password = bytes.TrimSpace(sharedPassword)
+		case len(hash) > 0 && hash == utilities.CalculateMapChecksum(r.resource.Data) && len(storedPassword) > 0:
+			password = storedPassword
 		default:
-			password = []byte(uuid.New().String())
-		}
-		// the coalesce function prioritizes the return value stored in the TenantControlPlane status,
-		// although this is going to be populated by the UpdateTenantControlPlaneStatus handler of the resource datastore-setup:
-		// the default value will be used for fresh new configurations, and preserving a previous one:
-		// this will keep us safe from naming changes cases as occurred with the following commit:
-		// https://github.com/clastix/kamaji/pull/203/commits/09ce38f489cccca72ab728a259bc8fb2cf6e4770
-		coalesceFn := func(fromStatus string) []byte {
-			if len(fromStatus) > 0 {
-				return []byte(fromStatus)
+			generated, err := r.passwordGenerator().Generate()
+			if err != nil {
+				return errors.Wrap(err, "cannot generate the DataStore user password")
 			}
-			// The dash character (-) must be replaced with an underscore, PostgreSQL is complaining about it:
-			// https://github.com/clastix/kamaji/issues/328
-			return []byte(strings.ReplaceAll(fmt.Sprintf("%s_%s", tenantControlPlane.GetNamespace(), tenantControlPlane.GetName()), "-", "_"))
-		}
 
+			password = []byte(generated)
+		}
 		r.resource.Data = map[string][]byte{
 			"DB_CONNECTION_STRING": []byte(r.ConnString),
-			"DB_SCHEMA":            coalesceFn(tenantControlPlane.Status.Storage.Setup.Schema),
-			"DB_USER":              coalesceFn(tenantControlPlane.Status.Storage.Setup.User),
+			"DB_SCHEMA":            []byte(schema),
+			"DB_USER":              []byte(user),
 			"DB_PASSWORD":          password,
+			// The URL-encoded password is used in the connection URLs, since it could contain reserved characters.
+			"DB_PASSWORD_URL_ENCODED": []byte(strings.TrimPrefix(url.UserPassword("", string(password)).String(), ":")),
 		}
 
+		metadata := r.DataStore.Spec.ConfigSecretMetadata
+
+		r.resource.SetAnnotations(utilities.MergeMaps(r.resource.GetAnnotations(), metadata.Annotations))
+
 		utilities.SetObjectChecksum(r.resource, r.resource.Data)
 
-		r.resource.SetLabels(utilities.KamajiLabels(tenantControlPlane.GetName(), r.GetName()))
+		r.resource.SetLabels(utilities.MergeMaps(metadata.Labels, utilities.KamajiLabels(tenantControlPlane.GetName(), r.GetName())))
 
 		return ctrl.SetControllerReference(tenantControlPlane, r.resource, r.Client.Scheme())
 	}
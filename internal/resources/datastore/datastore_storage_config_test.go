@@ -0,0 +1,107 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/pkg/datastore/datastoretest"
+)
+
+// fixedPasswordGenerator always generates the same password.
+type fixedPasswordGenerator string
+
+func (g fixedPasswordGenerator) Generate() (string, error) {
+	return string(g), nil
+}
+
+var _ = Describe("DataStore configuration", func() {
+	DescribeTable("URL-encoding the password",
+		func(password string) {
+			ctx := context.Background()
+			dataStore := kamajiv1alpha1.DataStore{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec:       kamajiv1alpha1.DataStoreSpec{Driver: kamajiv1alpha1.KinePostgreSQLDriver},
+			}
+			tcp := newTestTenantControlPlane(dataStore)
+
+			config := &Config{
+				Client:            newTestClient(),
+				ConnString:        "postgresql.kamaji-system.svc:5432",
+				DataStore:         dataStore,
+				PasswordGenerator: fixedPasswordGenerator(password),
+			}
+
+			Expect(config.Define(ctx, tcp)).To(Succeed())
+			_, err := config.CreateOrUpdate(ctx, tcp)
+			Expect(err).ToNot(HaveOccurred())
+
+			secret := &corev1.Secret{}
+			Expect(config.Client.Get(ctx, client.ObjectKeyFromObject(config.resource), secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("DB_PASSWORD", []byte(password)))
+			// The value is interpolated in the kine endpoint, which must be parsed back to the plain password.
+			endpoint, err := url.Parse(fmt.Sprintf("postgres://%s:%s@%s/%s",
+				secret.Data["DB_USER"], secret.Data["DB_PASSWORD_URL_ENCODED"], secret.Data["DB_CONNECTION_STRING"], secret.Data["DB_SCHEMA"]))
+			Expect(err).ToNot(HaveOccurred())
+
+			parsedPassword, _ := endpoint.User.Password()
+			Expect(parsedPassword).To(Equal(password))
+			Expect(endpoint.Host).To(Equal("postgresql.kamaji-system.svc:5432"))
+			Expect(endpoint.Path).To(Equal("/default_test"))
+		},
+		Entry("with a plain password", "secret"),
+		Entry("with an at sign", "p@ss@word"),
+		Entry("with a colon", "pass:word"),
+		Entry("with a slash", "pass/word"),
+		Entry("with spaces", "pass word"),
+		Entry("with all of them", "p@ss:w/ rd?#%"),
+	)
+
+	DescribeTable("resolving the mixed-case names",
+		func(driver kamajiv1alpha1.Driver, expectedSchema, expectedUser string) {
+			ctx := context.Background()
+			dataStore := kamajiv1alpha1.DataStore{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+				Spec:       kamajiv1alpha1.DataStoreSpec{Driver: driver},
+			}
+			tcp := newTestTenantControlPlane(dataStore)
+			tcp.Status.Storage.Setup.Schema = "Legacy_Schema"
+			tcp.Status.Storage.Setup.User = "Legacy_User"
+
+			config := &Config{
+				Client:            newTestClient(tcp),
+				ConnString:        "datastore.kamaji-system.svc:5432",
+				DataStore:         dataStore,
+				PasswordGenerator: fixedPasswordGenerator("secret"),
+			}
+
+			Expect(config.Define(ctx, tcp)).To(Succeed())
+			_, err := config.CreateOrUpdate(ctx, tcp)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.resource.Data).To(HaveKeyWithValue("DB_SCHEMA", []byte(expectedSchema)))
+			Expect(config.resource.Data).To(HaveKeyWithValue("DB_USER", []byte(expectedUser)))
+			// The setup must accept the names written in the configuration secret.
+			connection := datastoretest.NewConnection()
+			setup := &Setup{Client: config.Client, Connection: connection, DataStore: dataStore, ExistingUserPolicy: AdoptExistingUserPolicy}
+
+			Expect(setup.Define(ctx, tcp)).To(Succeed())
+			_, err = setup.CreateOrUpdate(ctx, tcp)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(connection.DBs).To(HaveKey(expectedSchema))
+			Expect(connection.Users).To(HaveKeyWithValue(expectedUser, "secret"))
+		},
+		Entry("with PostgreSQL, folding them to lowercase", kamajiv1alpha1.KinePostgreSQLDriver, "legacy_schema", "legacy_user"),
+		Entry("with MySQL, preserving them", kamajiv1alpha1.KineMySQLDriver, "Legacy_Schema", "Legacy_User"),
+	)
+})
@@ -0,0 +1,129 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// PasswordClass is a class of characters the generated passwords must contain.
+type PasswordClass string
+
+const (
+	LowerPasswordClass  PasswordClass = "lower"
+	UpperPasswordClass  PasswordClass = "upper"
+	DigitPasswordClass  PasswordClass = "digit"
+	SymbolPasswordClass PasswordClass = "symbol"
+)
+
+// passwordClassCharacters are the characters of each class: the symbols are limited to the ones safe to be used
+// in the SQL statements, and in the environment variables of the Tenant Control Plane, excluding quotes and dollar signs.
+var passwordClassCharacters = map[PasswordClass]string{
+	LowerPasswordClass:  "abcdefghijklmnopqrstuvwxyz",
+	UpperPasswordClass:  "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	DigitPasswordClass:  "0123456789",
+	SymbolPasswordClass: "!#*+-.=?@^_~",
+}
+
+// PasswordGenerator generates the passwords of the Tenant Control Plane DataStore users, when not provided.
+type PasswordGenerator interface {
+	Generate() (string, error)
+}
+
+// NewPasswordGenerator returns the PasswordGenerator enforcing the given policy: with a zero length, the passwords
+// are random UUIDs, as generated by default.
+// With no classes, the passwords are made of lowercase and uppercase letters, and digits.
+func NewPasswordGenerator(length int, classes []string) (PasswordGenerator, error) {
+	if length == 0 {
+		if len(classes) > 0 {
+			return nil, fmt.Errorf("the password classes require a password length")
+		}
+
+		return UUIDPasswordGenerator{}, nil
+	}
+
+	generator := PolicyPasswordGenerator{Length: length}
+
+	for _, class := range classes {
+		if _, ok := passwordClassCharacters[PasswordClass(class)]; !ok {
+			return nil, fmt.Errorf("the password class %s is not supported, must be one of %s, %s, %s, %s", class, LowerPasswordClass, UpperPasswordClass, DigitPasswordClass, SymbolPasswordClass)
+		}
+
+		generator.Classes = append(generator.Classes, PasswordClass(class))
+	}
+
+	if len(generator.Classes) == 0 {
+		generator.Classes = []PasswordClass{LowerPasswordClass, UpperPasswordClass, DigitPasswordClass}
+	}
+
+	if length < len(generator.Classes) {
+		return nil, fmt.Errorf("the password length must be at least %d to contain all the required classes", len(generator.Classes))
+	}
+
+	return generator, nil
+}
+
+// UUIDPasswordGenerator generates the passwords as random UUIDs.
+type UUIDPasswordGenerator struct{}
+
+func (UUIDPasswordGenerator) Generate() (string, error) {
+	return uuid.New().String(), nil
+}
+
+// PolicyPasswordGenerator generates passwords of the given length, containing at least a character of each class.
+type PolicyPasswordGenerator struct {
+	Length  int
+	Classes []PasswordClass
+}
+
+func (p PolicyPasswordGenerator) Generate() (string, error) {
+	password := make([]byte, 0, p.Length)
+
+	var charset strings.Builder
+	// Picking a character of each class first, ensuring all of them are contained.
+	for _, class := range p.Classes {
+		c, err := randomCharacter(passwordClassCharacters[class])
+		if err != nil {
+			return "", err
+		}
+
+		password = append(password, c)
+
+		charset.WriteString(passwordClassCharacters[class])
+	}
+
+	for len(password) < p.Length {
+		c, err := randomCharacter(charset.String())
+		if err != nil {
+			return "", err
+		}
+
+		password = append(password, c)
+	}
+	// Shuffling the password, since the leading characters are following the classes order.
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+
+	return string(password), nil
+}
+
+func randomCharacter(charset string) (byte, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+	if err != nil {
+		return 0, err
+	}
+
+	return charset[i.Int64()], nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/pkg/datastore/datastoretest"
+)
+
+func TestDataStore(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "DataStore Resources Suite")
+}
+
+// newTestTenantControlPlane returns a Tenant Control Plane whose DataStore Configuration secret is
+// the one returned by newTestConfigSecret.
+func newTestTenantControlPlane(dataStore kamajiv1alpha1.DataStore) *kamajiv1alpha1.TenantControlPlane {
+	tcp := &kamajiv1alpha1.TenantControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+	}
+	tcp.Status.Storage.DataStoreName = dataStore.GetName()
+	tcp.Status.Storage.Driver = string(dataStore.Spec.Driver)
+	tcp.Status.Storage.Config.SecretName = "test-datastore-config"
+
+	return tcp
+}
+
+// newTestConfigSecret returns the DataStore Configuration secret of the given Tenant Control Plane,
+// with the expected schema and user, and the given password.
+func newTestConfigSecret(tcp *kamajiv1alpha1.TenantControlPlane, dataStore kamajiv1alpha1.DataStore, password string) *corev1.Secret {
+	schema, user := tcp.DataStoreSchemaAndSharedUser(dataStore)
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tcp.Status.Storage.Config.SecretName,
+			Namespace: tcp.GetNamespace(),
+		},
+		Data: map[string][]byte{
+			"DB_SCHEMA":   []byte(schema),
+			"DB_USER":     []byte(user),
+			"DB_PASSWORD": []byte(password),
+		},
+	}
+}
+
+// newTestClient returns a fake client storing the given objects, with the Tenant Control Plane indexes.
+func newTestClient(objects ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(kamajiv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+	indexer := &kamajiv1alpha1.TenantControlPlaneStatusDataStore{}
+
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithIndex(indexer.Object(), indexer.Field(), indexer.ExtractValue()).
+		Build()
+}
+
+// newTestSetup returns a Setup backed by the in-memory Connection, and by a fake client storing the given objects.
+func newTestSetup(dataStore kamajiv1alpha1.DataStore, connection *datastoretest.Connection, objects ...client.Object) *Setup {
+	return &Setup{
+		Client:             newTestClient(objects...),
+		Connection:         connection,
+		DataStore:          dataStore,
+		ExistingUserPolicy: AdoptExistingUserPolicy,
+	}
+}
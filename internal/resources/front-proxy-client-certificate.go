@@ -38,8 +38,8 @@ func (r *FrontProxyClientCertificate) ShouldCleanup(*kamajiv1alpha1.TenantContro
 	return false
 }
 
-func (r *FrontProxyClientCertificate) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (bool, error) {
-	return false, nil
+func (r *FrontProxyClientCertificate) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (CleanUpResult, error) {
+	return CleanUpResultNone, nil
 }
 
 func (r *FrontProxyClientCertificate) Define(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
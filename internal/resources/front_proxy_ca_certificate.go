@@ -35,8 +35,8 @@ func (r *FrontProxyCACertificate) ShouldCleanup(*kamajiv1alpha1.TenantControlPla
 	return false
 }
 
-func (r *FrontProxyCACertificate) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (bool, error) {
-	return false, nil
+func (r *FrontProxyCACertificate) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (CleanUpResult, error) {
+	return CleanUpResultNone, nil
 }
 
 func (r *FrontProxyCACertificate) Define(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
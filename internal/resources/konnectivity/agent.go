@@ -18,6 +18,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/internal/resources"
 	"github.com/clastix/kamaji/internal/utilities"
 )
 
@@ -35,20 +36,20 @@ func (r *Agent) ShouldCleanup(tenantControlPlane *kamajiv1alpha1.TenantControlPl
 	return tenantControlPlane.Spec.Addons.Konnectivity == nil
 }
 
-func (r *Agent) CleanUp(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) (bool, error) {
+func (r *Agent) CleanUp(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) (resources.CleanUpResult, error) {
 	logger := log.FromContext(ctx, "resource", r.GetName())
 
 	if err := r.tenantClient.Delete(ctx, r.resource); err != nil {
 		if k8serrors.IsNotFound(err) {
-			return false, nil
+			return resources.CleanUpResultNone, nil
 		}
 
 		logger.Error(err, "cannot delete the requested resource")
 
-		return false, err
+		return resources.CleanUpResultNone, err
 	}
 
-	return true, nil
+	return resources.CleanUpResultCompleted, nil
 }
 
 func (r *Agent) Define(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (err error) {
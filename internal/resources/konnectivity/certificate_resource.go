@@ -21,6 +21,7 @@ import (
 	"github.com/clastix/kamaji/internal/constants"
 	"github.com/clastix/kamaji/internal/crypto"
 	"github.com/clastix/kamaji/internal/kubeadm"
+	"github.com/clastix/kamaji/internal/resources"
 	"github.com/clastix/kamaji/internal/utilities"
 )
 
@@ -37,20 +38,20 @@ func (r *CertificateResource) ShouldCleanup(tenantControlPlane *kamajiv1alpha1.T
 	return tenantControlPlane.Spec.Addons.Konnectivity == nil
 }
 
-func (r *CertificateResource) CleanUp(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) (bool, error) {
+func (r *CertificateResource) CleanUp(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) (resources.CleanUpResult, error) {
 	logger := log.FromContext(ctx, "resource", r.GetName())
 
 	if err := r.Client.Delete(ctx, r.resource); err != nil {
 		if !k8serrors.IsNotFound(err) {
 			logger.Error(err, "cannot delete the required resource")
 
-			return false, err
+			return resources.CleanUpResultNone, err
 		}
 
-		return false, nil
+		return resources.CleanUpResultNone, nil
 	}
 
-	return true, nil
+	return resources.CleanUpResultCompleted, nil
 }
 
 func (r *CertificateResource) Define(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
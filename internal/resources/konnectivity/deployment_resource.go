@@ -15,6 +15,7 @@ import (
 
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
 	builder "github.com/clastix/kamaji/internal/builders/controlplane"
+	"github.com/clastix/kamaji/internal/resources"
 	"github.com/clastix/kamaji/internal/utilities"
 )
 
@@ -40,7 +41,7 @@ func (r *KubernetesDeploymentResource) ShouldCleanup(tenantControlPlane *kamajiv
 	return tenantControlPlane.Spec.Addons.Konnectivity == nil && tenantControlPlane.Status.Addons.Konnectivity.Enabled
 }
 
-func (r *KubernetesDeploymentResource) CleanUp(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) (bool, error) {
+func (r *KubernetesDeploymentResource) CleanUp(ctx context.Context, _ *kamajiv1alpha1.TenantControlPlane) (resources.CleanUpResult, error) {
 	logger := log.FromContext(ctx)
 
 	logger.Info("performing clean-up from Deployment of Konnectivity")
@@ -60,8 +61,15 @@ func (r *KubernetesDeploymentResource) CleanUp(ctx context.Context, _ *kamajiv1a
 
 		return nil
 	})
+	if err != nil {
+		return resources.CleanUpResultNone, err
+	}
+
+	if res == controllerutil.OperationResultUpdated {
+		return resources.CleanUpResultCompleted, nil
+	}
 
-	return res == controllerutil.OperationResultUpdated, err
+	return resources.CleanUpResultNone, nil
 }
 
 func (r *KubernetesDeploymentResource) Define(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
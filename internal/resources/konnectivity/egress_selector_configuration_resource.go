@@ -16,6 +16,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/internal/resources"
 	"github.com/clastix/kamaji/internal/utilities"
 )
 
@@ -39,20 +40,20 @@ func (r *EgressSelectorConfigurationResource) ShouldCleanup(tenantControlPlane *
 	return tenantControlPlane.Spec.Addons.Konnectivity == nil
 }
 
-func (r *EgressSelectorConfigurationResource) CleanUp(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (bool, error) {
+func (r *EgressSelectorConfigurationResource) CleanUp(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (resources.CleanUpResult, error) {
 	logger := log.FromContext(ctx, "resource", r.GetName())
 
 	if err := r.Client.Delete(ctx, r.resource); err != nil {
 		if !k8serrors.IsNotFound(err) {
 			logger.Error(err, "cannot delete the requested resource")
 
-			return false, err
+			return resources.CleanUpResultNone, err
 		}
 
-		return false, nil
+		return resources.CleanUpResultNone, nil
 	}
 
-	return true, nil
+	return resources.CleanUpResultCompleted, nil
 }
 
 func (r *EgressSelectorConfigurationResource) CreateOrUpdate(ctx context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error) {
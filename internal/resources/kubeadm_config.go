@@ -34,8 +34,8 @@ func (r *KubeadmConfigResource) ShouldCleanup(*kamajiv1alpha1.TenantControlPlane
 	return false
 }
 
-func (r *KubeadmConfigResource) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (bool, error) {
-	return false, nil
+func (r *KubeadmConfigResource) CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (CleanUpResult, error) {
+	return CleanUpResultNone, nil
 }
 
 func (r *KubeadmConfigResource) Define(_ context.Context, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) error {
@@ -91,17 +91,18 @@ func (r *KubeadmConfigResource) mutate(ctx context.Context, tenantControlPlane *
 		r.resource.SetLabels(utilities.KamajiLabels(tenantControlPlane.GetName(), r.GetName()))
 
 		params := kubeadm.Parameters{
-			TenantControlPlaneAddress:     address,
-			TenantControlPlanePort:        port,
-			TenantControlPlaneName:        tenantControlPlane.GetName(),
-			TenantControlPlaneNamespace:   tenantControlPlane.GetNamespace(),
-			TenantControlPlaneEndpoint:    r.getControlPlaneEndpoint(tenantControlPlane.Spec.ControlPlane.Ingress, address, port),
-			TenantControlPlaneCertSANs:    tenantControlPlane.Spec.NetworkProfile.CertSANs,
-			TenantControlPlanePodCIDR:     tenantControlPlane.Spec.NetworkProfile.PodCIDR,
-			TenantControlPlaneServiceCIDR: tenantControlPlane.Spec.NetworkProfile.ServiceCIDR,
-			TenantControlPlaneVersion:     tenantControlPlane.Spec.Kubernetes.Version,
-			ETCDs:                         r.ETCDs,
-			CertificatesDir:               r.TmpDirectory,
+			TenantControlPlaneAddress:       address,
+			TenantControlPlanePort:          port,
+			TenantControlPlaneName:          tenantControlPlane.GetName(),
+			TenantControlPlaneNamespace:     tenantControlPlane.GetNamespace(),
+			TenantControlPlaneEndpoint:      r.getControlPlaneEndpoint(tenantControlPlane.Spec.ControlPlane.Ingress, address, port),
+			TenantControlPlaneCertSANs:      tenantControlPlane.Spec.NetworkProfile.CertSANs,
+			TenantControlPlanePodCIDR:       tenantControlPlane.Spec.NetworkProfile.PodCIDR,
+			TenantControlPlaneServiceCIDR:   tenantControlPlane.Spec.NetworkProfile.ServiceCIDR,
+			TenantControlPlaneClusterDomain: tenantControlPlane.Spec.NetworkProfile.ClusterDomain,
+			TenantControlPlaneVersion:       tenantControlPlane.Spec.Kubernetes.Version,
+			ETCDs:                           r.ETCDs,
+			CertificatesDir:                 r.TmpDirectory,
 		}
 
 		config, err := kubeadm.CreateKubeadmInitConfiguration(params)
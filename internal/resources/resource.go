@@ -20,10 +20,22 @@ const (
 	OperationResultEnqueueBack controllerutil.OperationResult = "enqueueBack"
 )
 
+// CleanUpResult is the outcome of the clean-up of a resource.
+type CleanUpResult string
+
+const (
+	// CleanUpResultNone is returned when there was nothing to clean up, or the clean-up has been skipped.
+	CleanUpResultNone CleanUpResult = "none"
+	// CleanUpResultCompleted is returned when the resource has been cleaned up.
+	CleanUpResultCompleted CleanUpResult = "completed"
+	// CleanUpResultPending is returned when the clean-up is still in progress, and must be checked again.
+	CleanUpResultPending CleanUpResult = "pending"
+)
+
 type Resource interface {
 	Define(context.Context, *kamajiv1alpha1.TenantControlPlane) error
 	ShouldCleanup(*kamajiv1alpha1.TenantControlPlane) bool
-	CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (bool, error)
+	CleanUp(context.Context, *kamajiv1alpha1.TenantControlPlane) (CleanUpResult, error)
 	CreateOrUpdate(context.Context, *kamajiv1alpha1.TenantControlPlane) (controllerutil.OperationResult, error)
 	GetName() string
 	ShouldStatusBeUpdated(context.Context, *kamajiv1alpha1.TenantControlPlane) bool
@@ -72,11 +84,14 @@ func Handle(ctx context.Context, resource Resource, tenantControlPlane *kamajiv1
 		return controllerutil.OperationResultNone, err
 	}
 
-	if cleanUp {
+	switch cleanUp {
+	case CleanUpResultCompleted:
 		return controllerutil.OperationResultUpdated, nil
+	case CleanUpResultPending:
+		return OperationResultEnqueueBack, nil
+	default:
+		return controllerutil.OperationResultNone, nil
 	}
-
-	return controllerutil.OperationResultNone, err
 }
 
 // HandleDeletion handles the deletion of the given resource.
@@ -0,0 +1,104 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package utilities
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// FieldManager is the field manager of the resources applied by Kamaji by means of server-side apply.
+const FieldManager = "kamaji"
+
+type forceOwnershipKey struct{}
+
+// WithForceOwnership returns a context making ServerSideApply take over the fields declared by Kamaji and managed
+// by a different field manager, rather than reporting them as conflict.
+func WithForceOwnership(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceOwnershipKey{}, true)
+}
+
+// updateFieldManagers are the field managers of the updates performed by Kamaji, named after its binary
+// since no field manager is set by the client: kamaji for the container image, manager for the local builds.
+var updateFieldManagers = sets.New(FieldManager, "manager")
+
+// ServerSideApply applies the object, as declared by the MutateFn, by means of server-side apply with the Kamaji
+// field manager: unlike CreateOrUpdateWithConflict, the MutateFn is invoked against an empty object, rather than the
+// current one, declaring only the fields managed by Kamaji.
+// The fields not declared are left to their managers, such as the replicas scaled by an autoscaler, while the ones
+// declared with a different value than the one set by a different manager are reported as conflict, rather than being
+// overwritten on each reconciliation.
+// The fields previously managed by Kamaji by means of updates are taken over first, avoiding the conflicts with itself.
+// The conflicting fields are overwritten when the context has been returned by WithForceOwnership.
+func ServerSideApply(ctx context.Context, c client.Client, resource client.Object, f controllerutil.MutateFn) (controllerutil.OperationResult, error) {
+	gvk, err := apiutil.GVKForObject(resource, c.Scheme())
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	current, _ := resource.DeepCopyObject().(client.Object) //nolint:forcetypeassert
+
+	exists := true
+
+	if err = c.Get(ctx, k8stypes.NamespacedName{Namespace: resource.GetNamespace(), Name: resource.GetName()}, current); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return controllerutil.OperationResultNone, err
+		}
+
+		exists = false
+	}
+
+	if exists {
+		if err = upgradeManagedFields(ctx, c, current); err != nil {
+			return controllerutil.OperationResultNone, errors.Wrap(err, "cannot take over the fields managed by means of updates")
+		}
+	}
+
+	if err = f(); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	resource.GetObjectKind().SetGroupVersionKind(gvk)
+
+	opts := []client.PatchOption{client.FieldOwner(FieldManager)}
+	if force, _ := ctx.Value(forceOwnershipKey{}).(bool); force {
+		opts = append(opts, client.ForceOwnership)
+	}
+
+	if err = c.Patch(ctx, resource, client.Apply, opts...); err != nil {
+		if k8serrors.IsConflict(err) {
+			return controllerutil.OperationResultNone, errors.Wrapf(err, "the fields applied by %s to the %s %s are managed by a different field manager", FieldManager, gvk.Kind, resource.GetName())
+		}
+
+		return controllerutil.OperationResultNone, err
+	}
+
+	switch {
+	case !exists:
+		return controllerutil.OperationResultCreated, nil
+	case resource.GetResourceVersion() != current.GetResourceVersion():
+		return controllerutil.OperationResultUpdated, nil
+	default:
+		return controllerutil.OperationResultNone, nil
+	}
+}
+
+// upgradeManagedFields moves to the Kamaji apply field manager the fields it set by means of updates,
+// such as before switching to server-side apply.
+func upgradeManagedFields(ctx context.Context, c client.Client, obj client.Object) error {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(obj, updateFieldManagers, FieldManager)
+	if err != nil || patch == nil {
+		return err
+	}
+
+	return c.Patch(ctx, obj, client.RawPatch(k8stypes.JSONPatchType, patch))
+}
@@ -6,6 +6,8 @@ package utilities
 import (
 	"context"
 	"fmt"
+	"net"
+	"strconv"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -19,6 +21,50 @@ import (
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
 )
 
+const (
+	// DefaultTenantClientQPS and DefaultTenantClientBurst are matching the client-go defaults,
+	// avoiding to overload small Tenant Control Plane API servers.
+	DefaultTenantClientQPS   float32 = 5
+	DefaultTenantClientBurst int     = 10
+)
+
+// TenantClientEndpoint is the Tenant Control Plane API server endpoint targeted by the clients
+// interacting with it, such as for the addons reconciliation.
+type TenantClientEndpoint string
+
+const (
+	// ServiceTenantClientEndpoint targets the in-cluster Service of the Tenant Control Plane.
+	ServiceTenantClientEndpoint TenantClientEndpoint = "Service"
+	// AdvertisedTenantClientEndpoint targets the advertised Tenant Control Plane endpoint, such as the Load Balancer one,
+	// for the topologies where the Service is not reachable from the operator.
+	AdvertisedTenantClientEndpoint TenantClientEndpoint = "Advertised"
+)
+
+var (
+	tenantClientQPS      = DefaultTenantClientQPS
+	tenantClientBurst    = DefaultTenantClientBurst
+	tenantClientEndpoint = ServiceTenantClientEndpoint
+)
+
+// SetTenantClientRateLimit configures the client-side rate limiting used by the clients
+// interacting with the Tenant Control Plane API servers: it's expected to be called once at startup.
+func SetTenantClientRateLimit(qps float32, burst int) {
+	tenantClientQPS, tenantClientBurst = qps, burst
+}
+
+// SetTenantClientEndpoint configures the Tenant Control Plane API server endpoint targeted by the clients:
+// it's expected to be called once at startup.
+func SetTenantClientEndpoint(endpoint TenantClientEndpoint) error {
+	switch endpoint {
+	case ServiceTenantClientEndpoint, AdvertisedTenantClientEndpoint:
+		tenantClientEndpoint = endpoint
+
+		return nil
+	default:
+		return fmt.Errorf("unrecognized tenant client endpoint %s, must be one of %s, %s", endpoint, ServiceTenantClientEndpoint, AdvertisedTenantClientEndpoint)
+	}
+}
+
 func GetTenantClient(ctx context.Context, c client.Client, tenantControlPlane *kamajiv1alpha1.TenantControlPlane) (client.Client, error) {
 	options := client.Options{}
 	config, err := GetRESTClientConfig(ctx, c, tenantControlPlane)
@@ -53,14 +99,27 @@ func GetRESTClientConfig(ctx context.Context, client client.Client, tenantContro
 		return nil, err
 	}
 
+	host := fmt.Sprintf("https://%s.%s.svc.cluster.local:%d", tenantControlPlane.GetName(), tenantControlPlane.GetNamespace(), tenantControlPlane.Spec.NetworkProfile.Port)
+
+	if tenantClientEndpoint == AdvertisedTenantClientEndpoint {
+		address, port, err := tenantControlPlane.AssignedControlPlaneAddress()
+		if err != nil {
+			return nil, err
+		}
+
+		host = fmt.Sprintf("https://%s", net.JoinHostPort(address, strconv.Itoa(int(port))))
+	}
+
 	config := &restclient.Config{
-		Host: fmt.Sprintf("https://%s.%s.svc.cluster.local:%d", tenantControlPlane.GetName(), tenantControlPlane.GetNamespace(), tenantControlPlane.Spec.NetworkProfile.Port),
+		Host: host,
 		TLSClientConfig: restclient.TLSClientConfig{
 			CAData:   kubeconfig.Clusters[0].Cluster.CertificateAuthorityData,
 			CertData: kubeconfig.AuthInfos[0].AuthInfo.ClientCertificateData,
 			KeyData:  kubeconfig.AuthInfos[0].AuthInfo.ClientKeyData,
 		},
 		Timeout: 10 * time.Second,
+		QPS:     tenantClientQPS,
+		Burst:   tenantClientBurst,
 	}
 
 	return config, nil
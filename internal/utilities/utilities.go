@@ -42,6 +42,13 @@ func AddTenantPrefix(name string, tenantControlPlane *kamajiv1alpha1.TenantContr
 	return fmt.Sprintf("%s%s%s", tenantControlPlane.GetName(), separator, name)
 }
 
+// IsPaused returns true if the given Tenant Control Plane has the reconciliation paused by means of annotation.
+func IsPaused(tenantControlPlane *kamajiv1alpha1.TenantControlPlane) bool {
+	_, ok := tenantControlPlane.GetAnnotations()[constants.PausedReconciliation]
+
+	return ok
+}
+
 // EncodeToYaml returns the given object in yaml format and the error.
 func EncodeToYaml(o runtime.Object) ([]byte, error) {
 	scheme := runtime.NewScheme()
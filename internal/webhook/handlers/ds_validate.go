@@ -6,6 +6,9 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"gomodules.xyz/jsonpatch/v2"
@@ -14,10 +17,12 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/pkg/datastore"
 )
 
 type DataStoreValidation struct {
@@ -62,12 +67,44 @@ func (d DataStoreValidation) OnUpdate(object runtime.Object, oldObj runtime.Obje
 }
 
 func (d DataStoreValidation) validate(ctx context.Context, ds kamajiv1alpha1.DataStore) error {
+	// The environment variables references are resolved by the operator, thus validating the actual values.
+	ds, err := datastore.ResolveEnv(ds)
+	if err != nil {
+		return err
+	}
+
+	if len(ds.Spec.MaintenanceDatabase) > 0 && ds.Spec.Driver != kamajiv1alpha1.KinePostgreSQLDriver {
+		return fmt.Errorf("the maintenance database is available only for the %s driver", kamajiv1alpha1.KinePostgreSQLDriver)
+	}
+
+	if ds.Spec.PasswordExpiry != nil && ds.Spec.PasswordExpiry.Duration <= 0 {
+		return fmt.Errorf("the password expiry must be greater than zero")
+	}
+
+	if err := d.validateDriverCombinations(ds); err != nil {
+		return err
+	}
+
+	if err := d.validateEndpoints("endpoints", ds.Spec.Endpoints); err != nil {
+		return err
+	}
+
+	if err := d.validateEndpoints("direct endpoints", ds.Spec.DirectEndpoints); err != nil {
+		return err
+	}
+
 	if ds.Spec.BasicAuth != nil {
 		if err := d.validateBasicAuth(ctx, ds); err != nil {
 			return err
 		}
 	}
 
+	if ds.Spec.SharedUser != nil {
+		if err := d.validateContentReference(ctx, ds.Spec.SharedUser.Password); err != nil {
+			return fmt.Errorf("shared user password is not valid, %w", err)
+		}
+	}
+
 	if err := d.validateTLSConfig(ctx, ds); err != nil {
 		return err
 	}
@@ -75,6 +112,87 @@ func (d DataStoreValidation) validate(ctx context.Context, ds kamajiv1alpha1.Dat
 	return nil
 }
 
+// validateDriverCombinations rejects the fields not supported by the selected driver, rather than ignoring them.
+func (d DataStoreValidation) validateDriverCombinations(ds kamajiv1alpha1.DataStore) error {
+	if len(ds.Spec.DirectEndpoints) > 0 && ds.Spec.Driver != kamajiv1alpha1.KinePostgreSQLDriver {
+		return fmt.Errorf("the direct endpoints are available only for the %s driver", kamajiv1alpha1.KinePostgreSQLDriver)
+	}
+
+	if len(ds.Spec.Extensions) > 0 && ds.Spec.Driver != kamajiv1alpha1.KinePostgreSQLDriver {
+		return fmt.Errorf("the extensions are available only for the %s driver", kamajiv1alpha1.KinePostgreSQLDriver)
+	}
+
+	if len(ds.Spec.SearchPath) > 0 && ds.Spec.Driver != kamajiv1alpha1.KinePostgreSQLDriver {
+		return fmt.Errorf("the search path is available only for the %s driver", kamajiv1alpha1.KinePostgreSQLDriver)
+	}
+
+	if len(ds.Spec.Tablespace) > 0 && ds.Spec.Driver != kamajiv1alpha1.KinePostgreSQLDriver {
+		return fmt.Errorf("the tablespace is available only for the %s driver", kamajiv1alpha1.KinePostgreSQLDriver)
+	}
+
+	if ds.Spec.Driver != kamajiv1alpha1.EtcdDriver {
+		return nil
+	}
+
+	if len(ds.Spec.TemplateDatabase) > 0 {
+		return fmt.Errorf("the template database is available only for the %s and %s drivers", kamajiv1alpha1.KineMySQLDriver, kamajiv1alpha1.KinePostgreSQLDriver)
+	}
+
+	if len(ds.Spec.UnixSocket) > 0 {
+		return fmt.Errorf("the Unix socket is available only for the %s and %s drivers", kamajiv1alpha1.KineMySQLDriver, kamajiv1alpha1.KinePostgreSQLDriver)
+	}
+
+	if ds.Spec.SharedUser != nil {
+		return fmt.Errorf("the shared user is available only for the %s and %s drivers", kamajiv1alpha1.KineMySQLDriver, kamajiv1alpha1.KinePostgreSQLDriver)
+	}
+
+	if ds.Spec.BasicAuth != nil {
+		return fmt.Errorf("the basic authentication is not supported by the %s driver, remove it since the client certificate is used to authenticate", kamajiv1alpha1.EtcdDriver)
+	}
+
+	if ds.Spec.Timeouts != nil {
+		return fmt.Errorf("the timeouts are available only for the %s and %s drivers", kamajiv1alpha1.KineMySQLDriver, kamajiv1alpha1.KinePostgreSQLDriver)
+	}
+
+	if ds.Spec.WithGrantOption {
+		return fmt.Errorf("the grant option is available only for the %s and %s drivers", kamajiv1alpha1.KineMySQLDriver, kamajiv1alpha1.KinePostgreSQLDriver)
+	}
+
+	if ds.Spec.PasswordExpiry != nil {
+		return fmt.Errorf("the password expiry is available only for the %s and %s drivers", kamajiv1alpha1.KineMySQLDriver, kamajiv1alpha1.KinePostgreSQLDriver)
+	}
+
+	return nil
+}
+
+// validateEndpoints checks the endpoints are expressed as host and port pairs, with no scheme nor credentials.
+func (d DataStoreValidation) validateEndpoints(field string, endpoints []string) error {
+	for _, endpoint := range endpoints {
+		if strings.Contains(endpoint, "://") {
+			return fmt.Errorf("the %s %s is not valid, remove the scheme and express it as host:port", field, endpoint)
+		}
+
+		if strings.Contains(endpoint, "@") {
+			return fmt.Errorf("the %s %s is not valid, remove the credentials and use the basic authentication instead", field, endpoint)
+		}
+
+		host, stringPort, err := net.SplitHostPort(endpoint)
+		if err != nil {
+			return fmt.Errorf("the %s %s is not valid, express it as host:port: %w", field, endpoint, err)
+		}
+
+		if len(host) == 0 {
+			return fmt.Errorf("the %s %s is not valid, the host is missing", field, endpoint)
+		}
+
+		if port, portErr := strconv.Atoi(stringPort); portErr != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("the %s %s is not valid, the port must be a number between 1 and 65535", field, endpoint)
+		}
+	}
+
+	return nil
+}
+
 func (d DataStoreValidation) validateBasicAuth(ctx context.Context, ds kamajiv1alpha1.DataStore) error {
 	if err := d.validateContentReference(ctx, ds.Spec.BasicAuth.Password); err != nil {
 		return fmt.Errorf("basic-auth password is not valid, %w", err)
@@ -112,6 +230,41 @@ func (d DataStoreValidation) validateTLSConfig(ctx context.Context, ds kamajiv1a
 		return fmt.Errorf("client private key is not valid, %w", err)
 	}
 
+	if serverName := ds.Spec.TLSConfig.ServerName; len(serverName) > 0 {
+		if errs := validation.IsDNS1123Subdomain(serverName); len(errs) > 0 {
+			return fmt.Errorf("TLS server name %s is not valid, %s", serverName, strings.Join(errs, ", "))
+		}
+	}
+
+	if bundle := ds.Spec.TLSConfig.ServerCABundle; bundle != nil {
+		if err := d.validateCABundle(ctx, *bundle); err != nil {
+			return fmt.Errorf("server CA bundle is not valid, %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (d DataStoreValidation) validateCABundle(ctx context.Context, bundle kamajiv1alpha1.CABundle) error {
+	if len(bundle.Content) > 0 || bundle.SecretRef != nil || bundle.ConfigMapRef == nil {
+		return d.validateContentReference(ctx, bundle.ContentRef)
+	}
+
+	switch {
+	case len(bundle.ConfigMapRef.Name) == 0:
+		return fmt.Errorf("the ConfigMap reference name is mandatory")
+	case len(bundle.ConfigMapRef.Namespace) == 0:
+		return fmt.Errorf("the ConfigMap reference namespace is mandatory")
+	}
+
+	if err := d.Client.Get(ctx, types.NamespacedName{Name: bundle.ConfigMapRef.Name, Namespace: bundle.ConfigMapRef.Namespace}, &corev1.ConfigMap{}); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return fmt.Errorf("configmap %s/%s is not found", bundle.ConfigMapRef.Namespace, bundle.ConfigMapRef.Name)
+		}
+
+		return err
+	}
+
 	return nil
 }
 
@@ -0,0 +1,112 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"gomodules.xyz/jsonpatch/v2"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/internal/webhook/utils"
+)
+
+// coreDNSPort is the port CoreDNS is serving the DNS requests on, as rendered by kubeadm.
+const coreDNSPort int32 = 53
+
+type TenantControlPlaneAddons struct{}
+
+func (t TenantControlPlaneAddons) OnCreate(object runtime.Object) AdmissionResponse {
+	return func(context.Context, admission.Request) ([]jsonpatch.JsonPatchOperation, error) {
+		tcp := object.(*kamajiv1alpha1.TenantControlPlane) //nolint:forcetypeassert
+
+		if err := t.validateCoreDNS(tcp.Spec.Addons.CoreDNS); err != nil {
+			return nil, err
+		}
+
+		if err := t.validateCoreDNSServiceAccount(tcp.Spec.Addons.CoreDNS); err != nil {
+			return nil, err
+		}
+
+		return nil, t.validateCoreDNSAutoscaler(tcp.Spec.Addons.CoreDNS)
+	}
+}
+
+func (t TenantControlPlaneAddons) OnDelete(runtime.Object) AdmissionResponse {
+	return utils.NilOp()
+}
+
+func (t TenantControlPlaneAddons) OnUpdate(object runtime.Object, _ runtime.Object) AdmissionResponse {
+	return func(context.Context, admission.Request) ([]jsonpatch.JsonPatchOperation, error) {
+		tcp := object.(*kamajiv1alpha1.TenantControlPlane) //nolint:forcetypeassert
+
+		if err := t.validateCoreDNS(tcp.Spec.Addons.CoreDNS); err != nil {
+			return nil, err
+		}
+
+		if err := t.validateCoreDNSServiceAccount(tcp.Spec.Addons.CoreDNS); err != nil {
+			return nil, err
+		}
+
+		return nil, t.validateCoreDNSAutoscaler(tcp.Spec.Addons.CoreDNS)
+	}
+}
+
+// validateCoreDNS ensures the ports of the enabled CoreDNS plugins don't collide with each other, nor with the DNS one.
+func (t TenantControlPlaneAddons) validateCoreDNS(coreDNS *kamajiv1alpha1.CoreDNSAddonSpec) error {
+	if coreDNS == nil {
+		return nil
+	}
+
+	ports := coreDNS.PluginPorts()
+
+	plugins := make([]string, 0, len(ports))
+	for plugin := range ports {
+		plugins = append(plugins, plugin)
+	}
+	// Sorting the plugins to return a stable error message.
+	sort.Strings(plugins)
+
+	used := map[int32]string{coreDNSPort: "dns"}
+
+	for _, plugin := range plugins {
+		port := ports[plugin]
+
+		if other, ok := used[port]; ok {
+			return fmt.Errorf("the CoreDNS %s plugin port %d collides with the %s one", plugin, port, other)
+		}
+
+		used[port] = plugin
+	}
+
+	return nil
+}
+
+// validateCoreDNSServiceAccount ensures the CoreDNS ServiceAccount annotations are valid, before applying them to the tenant cluster.
+func (t TenantControlPlaneAddons) validateCoreDNSServiceAccount(coreDNS *kamajiv1alpha1.CoreDNSAddonSpec) error {
+	if coreDNS == nil || coreDNS.ServiceAccount == nil {
+		return nil
+	}
+
+	return apivalidation.ValidateAnnotations(coreDNS.ServiceAccount.Annotations, field.NewPath("spec", "addons", "coreDNS", "serviceAccount", "annotations")).ToAggregate()
+}
+
+// validateCoreDNSAutoscaler ensures the CoreDNS replicas bounds of the autoscaler are consistent.
+func (t TenantControlPlaneAddons) validateCoreDNSAutoscaler(coreDNS *kamajiv1alpha1.CoreDNSAddonSpec) error {
+	if coreDNS == nil || coreDNS.Autoscaler == nil || coreDNS.Autoscaler.MaxReplicas == nil {
+		return nil
+	}
+
+	if autoscaler := coreDNS.Autoscaler; *autoscaler.MaxReplicas < autoscaler.MinReplicas {
+		return fmt.Errorf("the CoreDNS autoscaler max replicas %d cannot be lower than the min ones %d", *autoscaler.MaxReplicas, autoscaler.MinReplicas)
+	}
+
+	return nil
+}
@@ -6,6 +6,8 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strconv"
 
 	"gomodules.xyz/jsonpatch/v2"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -15,6 +17,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/internal/constants"
 	"github.com/clastix/kamaji/internal/webhook/utils"
 )
 
@@ -26,6 +29,14 @@ func (t TenantControlPlaneDataStore) OnCreate(object runtime.Object) AdmissionRe
 	return func(ctx context.Context, req admission.Request) ([]jsonpatch.JsonPatchOperation, error) {
 		tcp := object.(*kamajiv1alpha1.TenantControlPlane) //nolint:forcetypeassert
 
+		if err := t.checkGrantOption(tcp); err != nil {
+			return nil, err
+		}
+
+		if err := t.checkSchemaRename(tcp); err != nil {
+			return nil, err
+		}
+
 		return nil, t.check(ctx, tcp.Spec.DataStore)
 	}
 }
@@ -38,6 +49,14 @@ func (t TenantControlPlaneDataStore) OnUpdate(object runtime.Object, _ runtime.O
 	return func(ctx context.Context, req admission.Request) ([]jsonpatch.JsonPatchOperation, error) {
 		tcp := object.(*kamajiv1alpha1.TenantControlPlane) //nolint:forcetypeassert
 
+		if err := t.checkGrantOption(tcp); err != nil {
+			return nil, err
+		}
+
+		if err := t.checkSchemaRename(tcp); err != nil {
+			return nil, err
+		}
+
 		return nil, t.check(ctx, tcp.Spec.DataStore)
 	}
 }
@@ -53,3 +72,36 @@ func (t TenantControlPlaneDataStore) check(ctx context.Context, dataStoreName st
 
 	return nil
 }
+
+// checkGrantOption rejects the values of the grant option annotation which can't be parsed as boolean,
+// rather than silently falling back to the DataStore setting.
+func (t TenantControlPlaneDataStore) checkGrantOption(tcp *kamajiv1alpha1.TenantControlPlane) error {
+	value, ok := tcp.GetAnnotations()[constants.DataStoreGrantOption]
+	if !ok {
+		return nil
+	}
+
+	if _, err := strconv.ParseBool(value); err != nil {
+		return fmt.Errorf("the %s annotation value %s is not valid, it must be true or false", constants.DataStoreGrantOption, value)
+	}
+
+	return nil
+}
+
+// schemaNameRegexp matches the lowercase unquoted identifiers, valid for the supported datastores.
+var schemaNameRegexp = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// checkSchemaRename rejects the schema names which can't be used as unquoted identifiers by the datastores,
+// since the renamed schema must be addressed by kine with no quoting.
+func (t TenantControlPlaneDataStore) checkSchemaRename(tcp *kamajiv1alpha1.TenantControlPlane) error {
+	value, ok := tcp.GetAnnotations()[constants.RenameDataStoreSchema]
+	if !ok {
+		return nil
+	}
+
+	if !schemaNameRegexp.MatchString(value) {
+		return fmt.Errorf("the %s annotation value %s is not valid, it must be a lowercase identifier of up to 63 characters", constants.RenameDataStoreSchema, value)
+	}
+
+	return nil
+}
@@ -78,8 +78,12 @@ func (t TenantControlPlaneDeployment) OnUpdate(newObject runtime.Object, oldObje
 
 		t.DeploymentBuilder.Build(ctx, &deployment, *tcp)
 
-		if tcp.Spec.Addons.Konnectivity != nil {
-			t.KonnectivityBuilder.Build(&deployment, *tcp)
+		// The addons are resolved according to the profile, as done by the reconciliation.
+		resolved := tcp.DeepCopy()
+		resolved.Spec.Addons.ApplyProfile()
+
+		if resolved.Spec.Addons.Konnectivity != nil {
+			t.KonnectivityBuilder.Build(&deployment, *resolved)
 		}
 
 		if k8serrors.IsNotFound(err) {
@@ -9,16 +9,26 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/clastix/kamaji/cmd"
+	"github.com/clastix/kamaji/cmd/exec"
+	"github.com/clastix/kamaji/cmd/grants"
 	"github.com/clastix/kamaji/cmd/manager"
 	"github.com/clastix/kamaji/cmd/migrate"
+	"github.com/clastix/kamaji/cmd/status"
+	"github.com/clastix/kamaji/cmd/truncate"
 )
 
 func main() {
 	scheme := runtime.NewScheme()
 
-	root, mgr, migrator := cmd.NewCmd(scheme), manager.NewCmd(scheme), migrate.NewCmd(scheme)
-	root.AddCommand(mgr)
-	root.AddCommand(migrator)
+	root := cmd.NewCmd(scheme)
+	root.AddCommand(
+		manager.NewCmd(scheme),
+		migrate.NewCmd(scheme),
+		status.NewCmd(scheme),
+		exec.NewCmd(scheme),
+		truncate.NewCmd(scheme),
+		grants.NewCmd(scheme),
+	)
 
 	if err := root.Execute(); err != nil {
 		os.Exit(1)
@@ -0,0 +1,52 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// DefaultApplicationName is the base label of the connections opened by Kamaji against the SQL data stores.
+const DefaultApplicationName = "kamaji"
+
+// applicationNameMaxLength is the PostgreSQL application_name length limit, applied to MySQL as well for consistency.
+const applicationNameMaxLength = 63
+
+// applicationNameRegexp matches the characters not allowed in the label, such as the comma separating the MySQL
+// connection attributes.
+var applicationNameRegexp = regexp.MustCompile(`[^A-Za-z0-9._:/-]`)
+
+var applicationName = DefaultApplicationName
+
+// SetApplicationName configures the label of the connections opened by Kamaji, made of the given base label and the
+// operator identity, such as its Pod name: it's expected to be called once at startup.
+func SetApplicationName(base, identity string) {
+	applicationName = strings.Trim(strings.Join([]string{base, identity}, "/"), "/")
+}
+
+type applicationTenantKey struct{}
+
+// WithApplicationTenant returns a context whose connections are labelled with the given tenant as well,
+// such as the namespaced name of the Tenant Control Plane being provisioned.
+func WithApplicationTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, applicationTenantKey{}, tenant)
+}
+
+// connectionApplicationName returns the label of the connections, letting the datastore monitoring attribute them
+// to Kamaji, and to the tenant they're opened for: the label is sanitized, and truncated to the length limit.
+func connectionApplicationName(ctx context.Context) string {
+	name := applicationName
+	if tenant, _ := ctx.Value(applicationTenantKey{}).(string); len(tenant) > 0 {
+		name += "/" + tenant
+	}
+
+	name = applicationNameRegexp.ReplaceAllString(name, "_")
+	if len(name) > applicationNameMaxLength {
+		name = name[:applicationNameMaxLength]
+	}
+
+	return name
+}
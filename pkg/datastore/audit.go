@@ -0,0 +1,86 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// AuditEntry is the record of a statement performed by Kamaji against the data store.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	Driver    string    `json:"driver"`
+	Statement string    `json:"statement"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// AuditSink receives the DDL statements performed against the SQL data stores, such as the creation of users,
+// databases, and privileges: sensitive values, such as passwords, are redacted before being sent.
+type AuditSink interface {
+	Audit(ctx context.Context, entry AuditEntry)
+}
+
+var auditSink AuditSink
+
+// SetAuditSink configures the sink receiving the statements performed against the data stores:
+// it's expected to be called once at startup, and no statements are recorded if not set.
+func SetAuditSink(sink AuditSink) {
+	auditSink = sink
+}
+
+// auditRedactionRegexp matches the quoted passwords in the CREATE USER and ALTER USER statements.
+var auditRedactionRegexp = regexp.MustCompile(`(?i)((?:IDENTIFIED\s+BY|PASSWORD)\s+)'(?:[^'\\]|\\.|'')*'`)
+
+func redactStatement(statement string) string {
+	return auditRedactionRegexp.ReplaceAllString(statement, "$1'<redacted>'")
+}
+
+func audit(ctx context.Context, driver, statement string, err error) {
+	if auditSink == nil {
+		return
+	}
+
+	entry := AuditEntry{
+		Time:      time.Now(),
+		Driver:    driver,
+		Statement: redactStatement(statement),
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	auditSink.Audit(ctx, entry)
+}
+
+// FileAuditSink appends the audit entries to the given file, one JSON document per line.
+type FileAuditSink struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileAuditSink{encoder: json.NewEncoder(file)}, nil
+}
+
+func (f *FileAuditSink) Audit(ctx context.Context, entry AuditEntry) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.encoder.Encode(entry); err != nil {
+		log.FromContext(ctx).Error(err, "cannot record the datastore audit entry")
+	}
+}
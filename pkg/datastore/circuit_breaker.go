@@ -0,0 +1,105 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitBreaker tracks the consecutive failures against each DataStore: once the threshold is reached,
+// the circuit is opened and the attempts are short-circuited for the cool-down period,
+// after which a single probe is allowed to check for the DataStore recovery.
+type CircuitBreaker struct {
+	// Threshold is the number of consecutive failures opening the circuit: zero disables the circuit breaker.
+	Threshold int
+	// CoolDown is the amount of time the attempts are short-circuited before probing the DataStore.
+	CoolDown time.Duration
+
+	mutex  sync.Mutex
+	states map[string]*circuitState
+}
+
+type circuitState struct {
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// Allow returns if an attempt against the given DataStore is allowed, and if it's the recovery probe:
+// when short-circuited, the remaining cool-down period is returned.
+func (c *CircuitBreaker) Allow(dataStoreName string) (allowed bool, probe bool, remaining time.Duration) {
+	if c == nil || c.Threshold <= 0 {
+		return true, false, 0
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	state, ok := c.states[dataStoreName]
+	if !ok || state.failures < c.Threshold {
+		return true, false, 0
+	}
+
+	if elapsed := time.Since(state.openedAt); elapsed < c.CoolDown {
+		return false, false, c.CoolDown - elapsed
+	}
+
+	if state.probing {
+		return false, false, c.CoolDown
+	}
+
+	state.probing = true
+
+	return true, true, 0
+}
+
+// Success resets the failures for the given DataStore, returning true if the circuit was open.
+func (c *CircuitBreaker) Success(dataStoreName string) (closed bool) {
+	if c == nil || c.Threshold <= 0 {
+		return false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	state, ok := c.states[dataStoreName]
+	if !ok {
+		return false
+	}
+
+	delete(c.states, dataStoreName)
+
+	return state.failures >= c.Threshold
+}
+
+// Failure records a failure for the given DataStore, returning true if the circuit has been opened by it:
+// a failed recovery probe restarts the cool-down period.
+func (c *CircuitBreaker) Failure(dataStoreName string) (opened bool) {
+	if c == nil || c.Threshold <= 0 {
+		return false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.states == nil {
+		c.states = make(map[string]*circuitState)
+	}
+
+	state, ok := c.states[dataStoreName]
+	if !ok {
+		state = &circuitState{}
+		c.states[dataStoreName] = state
+	}
+
+	state.failures++
+	state.probing = false
+
+	if state.failures >= c.Threshold {
+		state.openedAt = time.Now()
+	}
+
+	return state.failures == c.Threshold
+}
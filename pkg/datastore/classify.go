@@ -0,0 +1,101 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+	"database/sql/driver"
+	goerrors "errors"
+	"io"
+	"net"
+	"syscall"
+
+	"github.com/go-sql-driver/mysql"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+
+	"github.com/clastix/kamaji/pkg/datastore/errors"
+)
+
+// ErrorClass is the class of an error returned by the data store, driving how the reconciliation is retried.
+type ErrorClass string
+
+const (
+	// TransientErrorClass is the class of the connectivity errors and timeouts, expected to be solved on their own.
+	TransientErrorClass ErrorClass = "Transient"
+	// PermissionErrorClass is the class of the authentication and authorization errors, which require
+	// the intervention of the data store administrators.
+	PermissionErrorClass ErrorClass = "PermissionDenied"
+	// InvalidConfigErrorClass is the class of the errors caused by an invalid DataStore configuration,
+	// solved only upon its change.
+	InvalidConfigErrorClass ErrorClass = "InvalidConfig"
+	// UnknownErrorClass is the class of any other error.
+	UnknownErrorClass ErrorClass = "Unknown"
+)
+
+var (
+	// mysqlPermissionErrorNumbers are the access denied errors: ER_DBACCESS_DENIED_ERROR, ER_ACCESS_DENIED_ERROR,
+	// ER_TABLEACCESS_DENIED_ERROR, ER_SPECIFIC_ACCESS_DENIED_ERROR, and ER_ACCOUNT_HAS_BEEN_LOCKED.
+	mysqlPermissionErrorNumbers = []uint16{1044, 1045, 1142, 1227, 3118}
+	// postgresqlPermissionErrorCodes are the invalid_authorization_specification, invalid_password,
+	// and insufficient_privilege error codes.
+	postgresqlPermissionErrorCodes = []string{"28000", "28P01", "42501"}
+)
+
+// ClassifyError returns the class of the given error returned by the data store, or upon the connection to it.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return UnknownErrorClass
+	}
+
+	if goerrors.As(err, &errors.InvalidConfigError{}) {
+		return InvalidConfigErrorClass
+	}
+
+	if isPermissionError(err) {
+		return PermissionErrorClass
+	}
+
+	var netErr net.Error
+
+	switch {
+	case goerrors.As(err, &errors.StatementTimeoutError{}),
+		goerrors.As(err, &netErr),
+		goerrors.Is(err, context.DeadlineExceeded),
+		goerrors.Is(err, syscall.ECONNREFUSED),
+		goerrors.Is(err, syscall.ECONNRESET),
+		goerrors.Is(err, syscall.ENOENT),
+		goerrors.Is(err, io.EOF),
+		goerrors.Is(err, io.ErrUnexpectedEOF),
+		goerrors.Is(err, driver.ErrBadConn):
+		return TransientErrorClass
+	default:
+		return UnknownErrorClass
+	}
+}
+
+func isPermissionError(err error) bool {
+	if goerrors.As(err, &errors.MissingPrivilegesError{}) {
+		return true
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if goerrors.As(err, &mysqlErr) {
+		for _, number := range mysqlPermissionErrorNumbers {
+			if mysqlErr.Number == number {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if postgresqlErrorHasCode(err, postgresqlPermissionErrorCodes...) {
+		return true
+	}
+
+	return goerrors.Is(err, rpctypes.ErrPermissionDenied) ||
+		goerrors.Is(err, rpctypes.ErrGRPCPermissionDenied) ||
+		goerrors.Is(err, rpctypes.ErrAuthFailed) ||
+		goerrors.Is(err, rpctypes.ErrGRPCAuthFailed)
+}
@@ -0,0 +1,249 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	dserrors "github.com/clastix/kamaji/pkg/datastore/errors"
+)
+
+// NewStorageConnection returns a Connection to the given DataStore, which must be closed once done:
+// the connections are not shared across the reconciliations, thus the changes to the DataStore, such as
+// its credentials, are picked up by the next one, with no connection outliving the DataStore.
+// The environment variables referenced by the DataStore are resolved upon each connection, and the SQL drivers
+// are connecting to the first reachable endpoint, tried in order.
+func NewStorageConnection(ctx context.Context, client client.Client, ds kamajiv1alpha1.DataStore) (Connection, error) {
+	ds, err := ResolveEnv(ds)
+	if err != nil {
+		return nil, err
+	}
+
+	cc, err := NewConnectionConfig(ctx, client, ds)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create connection config object")
+	}
+
+	cc.ApplicationName = connectionApplicationName(ctx)
+
+	if len(cc.UnixSocket) > 0 {
+		if err = checkUnixSocket(cc.UnixSocket); err != nil {
+			return nil, err
+		}
+	}
+
+	switch ds.Spec.Driver {
+	case kamajiv1alpha1.KineMySQLDriver:
+		cc.Parameters = map[string][]string{
+			"multiStatements": {"true"},
+		}
+
+		if len(cc.UnixSocket) > 0 {
+			return NewMySQLConnection(*cc)
+		}
+
+		return connectWithFailover(ctx, *cc, NewMySQLConnection)
+	case kamajiv1alpha1.KinePostgreSQLDriver:
+		cc.DBName = ds.Spec.MaintenanceDatabase
+
+		if len(cc.UnixSocket) > 0 {
+			return NewPostgreSQLConnection(*cc)
+		}
+
+		return connectWithFailover(ctx, *cc, NewPostgreSQLConnection)
+	case kamajiv1alpha1.EtcdDriver:
+		return NewETCDConnection(*cc)
+	default:
+		return nil, dserrors.NewInvalidConfigError(fmt.Errorf("%s is not a valid driver", ds.Spec.Driver))
+	}
+}
+
+// GrantOptions are tuning the privileges granted to the tenant users.
+type GrantOptions struct {
+	// WithGrantOption allows the user to grant its privileges to other users.
+	WithGrantOption bool
+}
+
+// CreateDBOptions are tuning the creation of the tenant databases.
+type CreateDBOptions struct {
+	// Tablespace is the tablespace the database is created in, which must exist: the default one is used when empty.
+	Tablespace string
+}
+
+// EndpointHealth is the health of a single datastore member, probed by its endpoint.
+type EndpointHealth struct {
+	Endpoint string
+	Healthy  bool
+	// Message is the reason the member is unhealthy, empty otherwise.
+	Message string
+}
+
+// Encoding is the character set, and the collation, of a database.
+type Encoding struct {
+	Charset   string
+	Collation string
+}
+
+// Grant is a privilege held by a user on a schema, such as a database or an etcd key prefix.
+type Grant struct {
+	Schema string `json:"schema"`
+	// Privilege is expressed with the driver naming (e.g.: SELECT, CONNECT, READWRITE).
+	Privilege string `json:"privilege"`
+	// GrantOption is set when the user can grant the privilege to other users.
+	GrantOption bool `json:"grantOption"`
+}
+
+// Capabilities describes the features supported by a driver, allowing to skip, or adapt, the unsupported operations
+// rather than handling the errors of their invocation.
+type Capabilities struct {
+	// Transactions is set when the DDL statements are performed atomically by Transaction.
+	Transactions bool
+	// Passwords is set when the users authenticate with a password, which can expire, and whose login can be disabled.
+	Passwords bool
+	// GrantOption is set when the users can be allowed to grant their privileges to other users.
+	GrantOption bool
+	// Extensions is set when the extensions can be installed in the databases.
+	Extensions bool
+	// SearchPath is set when the default search_path of the users can be set.
+	SearchPath bool
+	// RenameDatabase is set when the databases can be renamed in place.
+	RenameDatabase bool
+	// Tablespaces is set when the databases can be created in a given tablespace.
+	Tablespaces bool
+	// CloneDatabase is set when the databases can be initialized from a template database by CloneDB.
+	CloneDatabase bool
+	// Exec is set when the corrective statements can be performed by Exec.
+	Exec bool
+	// KeyPrefixes is set when the tenants are sharing a single key space, isolated by key prefixes.
+	KeyPrefixes bool
+	// EndpointsHealth is set when the health of each member is reported by EndpointsHealth.
+	EndpointsHealth bool
+	// Encoding is set when the character set and the collation of the databases are reported by DatabaseEncoding.
+	Encoding bool
+}
+
+type Connection interface {
+	// CreateUser creates the given user, returning an UserAlreadyExistsError if it has been already created,
+	// such as concurrently, or out of band.
+	CreateUser(ctx context.Context, user, password string) error
+	// SetUserPassword sets the password of an existing user, enabling its login, such as when adopting the ones
+	// created out of band: it's a no-op for drivers not relying on passwords.
+	SetUserPassword(ctx context.Context, user, password string) error
+	// SetUserPasswordExpiry sets the password of the user to expire once the given interval has elapsed,
+	// or to never expire when zero: it's a no-op for drivers not relying on passwords.
+	SetUserPasswordExpiry(ctx context.Context, user string, expiry time.Duration) error
+	CreateDB(ctx context.Context, dbName string) error
+	// CreateDBWithOptions creates the database as CreateDB, applying the given options where supported by the driver.
+	CreateDBWithOptions(ctx context.Context, dbName string, opts CreateDBOptions) error
+	// CloneDB creates the database as CreateDBWithOptions, initializing it from the given template database:
+	// the data is copied by PostgreSQL, while the table structure only by MySQL.
+	CloneDB(ctx context.Context, dbName, template string, opts CreateDBOptions) error
+	GrantPrivileges(ctx context.Context, user, dbName string) error
+	// GrantPrivilegesWithOptions grants the privileges as GrantPrivileges, applying the given options:
+	// the grant option is revoked when not requested, where supported by the driver.
+	GrantPrivilegesWithOptions(ctx context.Context, user, dbName string, opts GrantOptions) error
+	UserExists(ctx context.Context, user string) (bool, error)
+	// UserHasLogin returns true if the given user is allowed to log in, such as not being locked out
+	// by RevokeAllAndDisableUser, or out of band by the DataStore administrators.
+	UserHasLogin(ctx context.Context, user string) (bool, error)
+	DBExists(ctx context.Context, dbName string) (bool, error)
+	// DBOverlaps returns true if the given database, or key prefix, overlaps with the ones granted to other roles:
+	// it's relevant for the drivers sharing a single key space among the tenants, such as etcd.
+	DBOverlaps(ctx context.Context, dbName string) (bool, error)
+	// GrantPrivilegesExists returns true if the privileges have been granted, regardless of the grant option.
+	GrantPrivilegesExists(ctx context.Context, user, dbName string) (bool, error)
+	// GrantPrivilegesWithOptionsExists returns true if the privileges have been granted matching the given options,
+	// such as the grant option.
+	GrantPrivilegesWithOptionsExists(ctx context.Context, user, dbName string, opts GrantOptions) (bool, error)
+	// DatabaseExistsForUser returns true if the given database exists and belongs to the given tenant, according to
+	// the metadata recorded by Annotate, or to the ownership of the given user for the databases not annotated yet:
+	// it's checked before the deletion, avoiding to drop a database shared by mistake due to a name collision.
+	DatabaseExistsForUser(ctx context.Context, user, dbName, tenant string) (bool, error)
+	// HasPrivilege checks if the given privilege, expressed with the driver naming (e.g.: SELECT, CREATE, READWRITE),
+	// has been granted to the user on the given database.
+	HasPrivilege(ctx context.Context, user, dbName, privilege string) (bool, error)
+	// GrantedPrivileges returns the sorted privileges, expressed with the driver naming, currently held by the user
+	// on the given database: it's empty when the user, or the database, doesn't exist.
+	GrantedPrivileges(ctx context.Context, user, dbName string) ([]string, error)
+	// ListGrants returns the privileges explicitly granted to the user across all the schemas, sorted by schema and
+	// privilege: it's meant for the troubleshooting of the permission issues, and it's empty when the user doesn't exist.
+	ListGrants(ctx context.Context, user string) ([]Grant, error)
+	// Annotate records the owning tenant, such as the Tenant Control Plane namespaced name, on the given user
+	// and database, to correlate the datastore objects back to the tenants: it's a no-op for drivers not supporting it.
+	Annotate(ctx context.Context, user, dbName, tenant string) error
+	// EnsureExtensions installs the given extensions in the database, if not installed yet:
+	// it's a no-op for drivers not supporting them.
+	EnsureExtensions(ctx context.Context, dbName string, extensions []string) error
+	// SetUserSearchPath sets the default search_path of the user in the given database:
+	// it's a no-op for drivers not supporting it.
+	SetUserSearchPath(ctx context.Context, user, dbName string, searchPath []string) error
+	// CurrentUser returns the user the connection is authenticated as, as seen by the datastore,
+	// helping to troubleshoot wrong credentials or proxies rewriting them.
+	CurrentUser(ctx context.Context) (string, error)
+	// EndpointsHealth probes the health of each member of the datastore, such as the etcd ones, allowing to detect
+	// a partial degradation: it returns nil for the drivers not reporting it, such as MySQL and PostgreSQL.
+	EndpointsHealth(ctx context.Context) ([]EndpointHealth, error)
+	// DatabaseEncoding returns the character set and the collation of the given database:
+	// it returns an empty Encoding for the drivers not supporting them, such as etcd.
+	DatabaseEncoding(ctx context.Context, dbName string) (Encoding, error)
+	// DefaultEncoding returns the character set and the collation the databases are created with by CreateDB,
+	// as configured by the DataStore administrators: it returns an empty Encoding for the drivers not supporting them.
+	DefaultEncoding(ctx context.Context) (Encoding, error)
+	// DatastoreSize returns the overall size in bytes of the data stored in the datastore, for all the tenants.
+	DatastoreSize(ctx context.Context) (int64, error)
+	// Transaction executes the given function atomically, rolling back the statements performed with the provided
+	// Connection on failure: for the drivers not supporting transactional DDL statements, such as MySQL and etcd,
+	// the function is executed step-wise, with the same behavior of the standalone operations.
+	Transaction(ctx context.Context, fn func(ctx context.Context, tx Connection) error) error
+	// Lock acquires the advisory lock of the given key, such as the tenant schema, with no waiting: a LockedError
+	// is returned if it's held by a different session, such as a concurrent setup. The lock is held until the
+	// returned function is invoked, or the Connection is closed; it's a no-op for etcd, whose operations are idempotent.
+	Lock(ctx context.Context, key string) (unlock func(ctx context.Context) error, err error)
+	DeleteUser(ctx context.Context, user string) error
+	DeleteDB(ctx context.Context, dbName string) error
+	// RenameDatabase renames the given database in place, preserving its data and the privileges granted on it:
+	// it returns an error for the drivers not supporting it, such as MySQL and etcd.
+	RenameDatabase(ctx context.Context, oldName, newName string) error
+	RevokePrivileges(ctx context.Context, user, dbName string) error
+	// RevokeAllAndDisableUser cuts the user off from the given database, revoking its privileges and disabling its login,
+	// without deleting it: it's meant for the incident response, and it's not reverted by Kamaji.
+	RevokeAllAndDisableUser(ctx context.Context, user, dbName string) error
+	GetConnectionString() string
+	// Close releases the underlying connections, including the ones pooled by the driver:
+	// the Connection must not be used afterwards.
+	Close() error
+	Check(ctx context.Context) error
+	// Healthcheck verifies the Kamaji user holds the privileges required to provision the Tenant Control Planes,
+	// such as creating databases and users, with no changes against the data store: unlike Check, it catches
+	// the misconfigured credentials ahead of the first provisioning.
+	Healthcheck(ctx context.Context) error
+	Driver() string
+	// Capabilities returns the features supported by the driver.
+	Capabilities() Capabilities
+	Migrate(ctx context.Context, tcp kamajiv1alpha1.TenantControlPlane, target Connection) error
+	// Exec performs the given statement against the given database with the Kamaji credentials, recording it to the
+	// audit sink: it's meant for the one-off corrective statements issued by the datastore-exec command, and it must
+	// never be invoked upon the reconciliation. It's not supported by the drivers with no statements, such as etcd.
+	Exec(ctx context.Context, dbName, statement string) error
+	// TruncateData drops all the objects storing the tenant data in the given schema, such as the kine tables or keys,
+	// preserving the schema itself, the user, and its privileges: it's meant for the resets of the non-production
+	// tenants issued by the datastore-truncate command, and it must never be invoked upon the reconciliation.
+	// The dropped objects are recorded to the audit sink.
+	TruncateData(ctx context.Context, schema string) error
+}
+
+// advisoryLockName returns the name of the advisory lock of the given key, hashed to fit the MySQL length limit.
+func advisoryLockName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return "kamaji-" + hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,201 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/proxy"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	dserrors "github.com/clastix/kamaji/pkg/datastore/errors"
+)
+
+type ConnectionEndpoint struct {
+	Host string
+	Port int
+}
+
+func (r ConnectionEndpoint) String() string {
+	return fmt.Sprintf("%s:%d", r.Host, r.Port)
+}
+
+type ConnectionConfig struct {
+	User      string
+	Password  string
+	Endpoints []ConnectionEndpoint
+	// DirectEndpoints are bypassing the connection pooler for the statements not supported by it.
+	DirectEndpoints []ConnectionEndpoint
+	// UnixSocket is the path of the Unix domain socket used in place of the endpoints, empty when not used.
+	UnixSocket   string
+	DBName       string
+	TLSConfig    *tls.Config
+	Parameters   map[string][]string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// ProxyURL is the proxy URL specified in the DataStore, empty when relying on the environment variables.
+	ProxyURL string
+	// Dialer is used to connect to the data store endpoints through the proxy, nil if no proxy is configured.
+	Dialer proxy.ContextDialer
+	// ApplicationName labels the connections, letting the data store monitoring attribute them.
+	ApplicationName string
+}
+
+func NewConnectionConfig(ctx context.Context, client client.Client, ds kamajiv1alpha1.DataStore) (*ConnectionConfig, error) {
+	ca, err := ds.Spec.TLSConfig.CertificateAuthority.Certificate.GetContent(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	crt, err := ds.Spec.TLSConfig.ClientCertificate.Certificate.GetContent(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ds.Spec.TLSConfig.ClientCertificate.PrivateKey.GetContent(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	rootCAs := x509.NewCertPool()
+	if ok := rootCAs.AppendCertsFromPEM(ca); !ok {
+		return nil, dserrors.NewInvalidConfigError(fmt.Errorf("error create root CA for the DB connector"))
+	}
+	// The CA bundle replaces the Certificate Authority in verifying the data store certificate.
+	if bundle := ds.Spec.TLSConfig.ServerCABundle; bundle != nil {
+		pem, err := bundle.GetContent(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		rootCAs = x509.NewCertPool()
+		if ok := rootCAs.AppendCertsFromPEM(pem); !ok {
+			return nil, dserrors.NewInvalidConfigError(fmt.Errorf("the server CA bundle contains no valid PEM encoded certificate"))
+		}
+	}
+
+	certificate, err := tls.X509KeyPair(crt, key)
+	if err != nil {
+		return nil, dserrors.NewInvalidConfigError(errors.Wrap(err, "cannot retrieve x.509 key pair from the Kine Secret"))
+	}
+
+	var user, password string
+	if auth := ds.Spec.BasicAuth; auth != nil {
+		u, err := auth.Username.GetContent(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		user = string(u)
+
+		p, err := auth.Password.GetContent(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		password = string(p)
+	}
+
+	eps, err := parseEndpoints(ds.Spec.Endpoints)
+	if err != nil {
+		return nil, dserrors.NewInvalidConfigError(err)
+	}
+
+	directEps, err := parseEndpoints(ds.Spec.DirectEndpoints)
+	if err != nil {
+		return nil, dserrors.NewInvalidConfigError(err)
+	}
+
+	cc := &ConnectionConfig{
+		User:            user,
+		Password:        password,
+		Endpoints:       eps,
+		DirectEndpoints: directEps,
+		UnixSocket:      ds.Spec.UnixSocket,
+		TLSConfig: &tls.Config{
+			RootCAs:      rootCAs,
+			Certificates: []tls.Certificate{certificate},
+			ServerName:   ds.Spec.TLSConfig.ServerName,
+		},
+	}
+
+	if timeouts := ds.Spec.Timeouts; timeouts != nil {
+		if timeouts.Read != nil {
+			cc.ReadTimeout = timeouts.Read.Duration
+		}
+
+		if timeouts.Write != nil {
+			cc.WriteTimeout = timeouts.Write.Duration
+		}
+	}
+
+	if cc.Dialer, err = newProxyDialer(ds.Spec.Proxy); err != nil {
+		return nil, dserrors.NewInvalidConfigError(err)
+	}
+
+	cc.ProxyURL = ds.Spec.Proxy
+
+	return cc, nil
+}
+
+func parseEndpoints(endpoints []string) ([]ConnectionEndpoint, error) {
+	eps := make([]ConnectionEndpoint, 0, len(endpoints))
+
+	for _, ep := range endpoints {
+		host, stringPort, err := net.SplitHostPort(ep)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot retrieve host-port pair from DataStore endpoints")
+		}
+
+		port, err := strconv.Atoi(stringPort)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot convert port from string for the given DataStore")
+		}
+
+		eps = append(eps, ConnectionEndpoint{
+			Host: host,
+			Port: port,
+		})
+	}
+
+	return eps, nil
+}
+
+// checkUnixSocket verifies the given path is an existing Unix domain socket, reporting a clear error otherwise,
+// rather than the driver dial error.
+func checkUnixSocket(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.Wrapf(err, "the DataStore Unix socket %s is not available", path)
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return dserrors.NewInvalidConfigError(fmt.Errorf("the DataStore Unix socket path %s is not a socket", path))
+	}
+
+	return nil
+}
+
+// checkConnectionError returns the error of the connection check, reporting the failed verifications
+// of the data store certificate as invalid configuration, rather than retrying them.
+func checkConnectionError(err error) error {
+	var (
+		unknownAuthorityErr x509.UnknownAuthorityError
+		hostnameErr         x509.HostnameError
+		invalidErr          x509.CertificateInvalidError
+	)
+
+	if errors.As(err, &unknownAuthorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return dserrors.NewTLSVerificationError(err)
+	}
+
+	return dserrors.NewCheckConnectionError(err)
+}
@@ -0,0 +1,804 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+// Package datastoretest provides an in-memory implementation of the datastore.Connection interface,
+// allowing to exercise the provisioning flows, such as the Setup resource, with no real datastore.
+package datastoretest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/pkg/datastore"
+	"github.com/clastix/kamaji/pkg/datastore/errors"
+)
+
+var _ datastore.Connection = &Connection{}
+
+// Connection is tracking the created databases, users, and privileges in memory.
+// The errors returned by the methods can be injected by name, such as CreateUser, using the Errors map.
+type Connection struct {
+	mu sync.Mutex
+	// Users maps the created users to their password.
+	Users map[string]string
+	// DBs contains the created databases.
+	DBs map[string]struct{}
+	// Grants maps the users to the databases they have been granted the privileges on.
+	Grants map[string]map[string]struct{}
+	// GrantOptions maps the users to the databases they have been granted the privileges on with the grant option.
+	GrantOptions map[string]map[string]struct{}
+	// Disabled contains the users cut off by RevokeAllAndDisableUser.
+	Disabled map[string]struct{}
+	// Annotations maps the databases to the owning tenant.
+	Annotations map[string]string
+	// Extensions maps the databases to the ensured extensions.
+	Extensions map[string][]string
+	// SearchPaths maps the users to the search_path set by SetUserSearchPath.
+	SearchPaths map[string][]string
+	// Tablespaces maps the databases to the tablespace they've been created in, when requested.
+	Tablespaces map[string]string
+	// Locks are the keys of the locks held, acquired by Lock.
+	Locks map[string]struct{}
+	// Templates maps the databases to the template database they've been cloned from by CloneDB.
+	Templates map[string]string
+	// PasswordExpiries maps the users to the password expiry interval, removed when set to never expire.
+	PasswordExpiries map[string]time.Duration
+	// Statements maps the databases to the statements performed by Exec, in order.
+	Statements map[string][]string
+	// Truncated contains the databases whose data has been dropped by TruncateData.
+	Truncated map[string]struct{}
+	// CurrentUserName is the value returned by CurrentUser.
+	CurrentUserName string
+	// Encodings maps the databases to the value returned by DatabaseEncoding, DefaultDBEncoding being returned otherwise.
+	Encodings map[string]datastore.Encoding
+	// DefaultDBEncoding is the value returned by DefaultEncoding.
+	DefaultDBEncoding datastore.Encoding
+	// Health is the value returned by EndpointsHealth.
+	Health []datastore.EndpointHealth
+	// Size is the value returned by DatastoreSize.
+	Size int64
+	// CreateDBDelay is slowing down the database creation, simulating a busy datastore.
+	CreateDBDelay time.Duration
+	// Errors maps the method names to the error returned upon their invocation, with no side effects.
+	Errors map[string]error
+	// DriverName is the value returned by Driver.
+	DriverName string
+	// Supported is the value returned by Capabilities, supporting the SQL features by default.
+	Supported datastore.Capabilities
+	// ConnectionString is the value returned by GetConnectionString.
+	ConnectionString string
+	// Closed is set upon the invocation of Close.
+	Closed bool
+}
+
+func NewConnection() *Connection {
+	return &Connection{
+		Users:            map[string]string{},
+		DBs:              map[string]struct{}{},
+		Grants:           map[string]map[string]struct{}{},
+		GrantOptions:     map[string]map[string]struct{}{},
+		Disabled:         map[string]struct{}{},
+		Annotations:      map[string]string{},
+		Extensions:       map[string][]string{},
+		SearchPaths:      map[string][]string{},
+		Tablespaces:      map[string]string{},
+		Templates:        map[string]string{},
+		Locks:            map[string]struct{}{},
+		PasswordExpiries: map[string]time.Duration{},
+		Statements:       map[string][]string{},
+		Truncated:        map[string]struct{}{},
+		Encodings:        map[string]datastore.Encoding{},
+		Errors:           map[string]error{},
+		Supported: datastore.Capabilities{
+			Transactions:   true,
+			Passwords:      true,
+			GrantOption:    true,
+			Extensions:     true,
+			SearchPath:     true,
+			RenameDatabase: true,
+			Tablespaces:    true,
+			CloneDatabase:  true,
+			Exec:           true,
+			Encoding:       true,
+		},
+	}
+}
+
+func (c *Connection) CreateUser(_ context.Context, user, password string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["CreateUser"]; err != nil {
+		return err
+	}
+
+	if _, ok := c.Users[user]; ok {
+		return errors.NewUserAlreadyExistsError(fmt.Errorf("user %s already exists", user))
+	}
+
+	c.Users[user] = password
+
+	return nil
+}
+
+func (c *Connection) SetUserPassword(_ context.Context, user, password string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["SetUserPassword"]; err != nil {
+		return err
+	}
+
+	if _, ok := c.Users[user]; !ok {
+		return fmt.Errorf("user %s does not exist", user)
+	}
+
+	c.Users[user] = password
+	// Setting the password restores the login, as the actual drivers do.
+	delete(c.Disabled, user)
+
+	return nil
+}
+
+func (c *Connection) SetUserPasswordExpiry(_ context.Context, user string, expiry time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["SetUserPasswordExpiry"]; err != nil {
+		return err
+	}
+
+	if _, ok := c.Users[user]; !ok {
+		return fmt.Errorf("user %s does not exist", user)
+	}
+
+	if expiry == 0 {
+		delete(c.PasswordExpiries, user)
+
+		return nil
+	}
+
+	c.PasswordExpiries[user] = expiry
+
+	return nil
+}
+
+func (c *Connection) CreateDB(ctx context.Context, dbName string) error {
+	return c.CreateDBWithOptions(ctx, dbName, datastore.CreateDBOptions{})
+}
+
+func (c *Connection) CreateDBWithOptions(_ context.Context, dbName string, opts datastore.CreateDBOptions) error {
+	// The delay is elapsing before acquiring the lock, allowing the concurrent invocations in the meanwhile.
+	time.Sleep(c.CreateDBDelay)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["CreateDB"]; err != nil {
+		return err
+	}
+
+	if _, ok := c.DBs[dbName]; !ok && len(opts.Tablespace) > 0 {
+		c.Tablespaces[dbName] = opts.Tablespace
+	}
+
+	c.DBs[dbName] = struct{}{}
+
+	return nil
+}
+
+// CloneDB creates the database as CreateDBWithOptions, copying the extensions of the template database.
+func (c *Connection) CloneDB(ctx context.Context, dbName, template string, opts datastore.CreateDBOptions) error {
+	c.mu.Lock()
+
+	if err := c.Errors["CloneDB"]; err != nil {
+		c.mu.Unlock()
+
+		return err
+	}
+
+	if _, ok := c.DBs[template]; !ok {
+		c.mu.Unlock()
+
+		return fmt.Errorf("template database %s does not exist", template)
+	}
+
+	c.mu.Unlock()
+
+	if err := c.CreateDBWithOptions(ctx, dbName, opts); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Templates[dbName] = template
+
+	if extensions, ok := c.Extensions[template]; ok {
+		c.Extensions[dbName] = append([]string(nil), extensions...)
+	}
+
+	return nil
+}
+
+func (c *Connection) GrantPrivileges(ctx context.Context, user, dbName string) error {
+	return c.GrantPrivilegesWithOptions(ctx, user, dbName, datastore.GrantOptions{})
+}
+
+func (c *Connection) GrantPrivilegesWithOptions(_ context.Context, user, dbName string, opts datastore.GrantOptions) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["GrantPrivileges"]; err != nil {
+		return err
+	}
+
+	if _, ok := c.Grants[user]; !ok {
+		c.Grants[user] = map[string]struct{}{}
+	}
+
+	c.Grants[user][dbName] = struct{}{}
+
+	if !opts.WithGrantOption {
+		delete(c.GrantOptions[user], dbName)
+
+		return nil
+	}
+
+	if _, ok := c.GrantOptions[user]; !ok {
+		c.GrantOptions[user] = map[string]struct{}{}
+	}
+
+	c.GrantOptions[user][dbName] = struct{}{}
+
+	return nil
+}
+
+func (c *Connection) UserExists(_ context.Context, user string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["UserExists"]; err != nil {
+		return false, err
+	}
+
+	_, ok := c.Users[user]
+
+	return ok, nil
+}
+
+func (c *Connection) UserHasLogin(_ context.Context, user string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["UserHasLogin"]; err != nil {
+		return false, err
+	}
+
+	if _, ok := c.Users[user]; !ok {
+		return false, nil
+	}
+
+	_, disabled := c.Disabled[user]
+
+	return !disabled, nil
+}
+
+func (c *Connection) DBExists(_ context.Context, dbName string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["DBExists"]; err != nil {
+		return false, err
+	}
+
+	_, ok := c.DBs[dbName]
+
+	return ok, nil
+}
+
+// DBOverlaps always returns false, unless an error is injected.
+func (c *Connection) DBOverlaps(context.Context, string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return false, c.Errors["DBOverlaps"]
+}
+
+func (c *Connection) GrantPrivilegesExists(_ context.Context, user, dbName string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["GrantPrivilegesExists"]; err != nil {
+		return false, err
+	}
+
+	_, ok := c.Grants[user][dbName]
+
+	return ok, nil
+}
+
+// GrantPrivilegesWithOptionsExists returns true if the privileges have been granted, and the grant option matches.
+func (c *Connection) GrantPrivilegesWithOptionsExists(_ context.Context, user, dbName string, opts datastore.GrantOptions) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["GrantPrivilegesExists"]; err != nil {
+		return false, err
+	}
+
+	_, granted := c.Grants[user][dbName]
+	_, withGrantOption := c.GrantOptions[user][dbName]
+
+	return granted && withGrantOption == opts.WithGrantOption, nil
+}
+
+// DatabaseExistsForUser returns true if the database exists and has been annotated with the given tenant.
+func (c *Connection) DatabaseExistsForUser(_ context.Context, _, dbName, tenant string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["DatabaseExistsForUser"]; err != nil {
+		return false, err
+	}
+
+	_, exists := c.DBs[dbName]
+
+	return exists && c.Annotations[dbName] == tenant, nil
+}
+
+// HasPrivilege returns true for any privilege once the privileges on the given database have been granted.
+func (c *Connection) HasPrivilege(_ context.Context, user, dbName, _ string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["HasPrivilege"]; err != nil {
+		return false, err
+	}
+
+	_, ok := c.Grants[user][dbName]
+
+	return ok, nil
+}
+
+// GrantedPrivileges returns ALL once the privileges on the given database have been granted,
+// along with GRANT OPTION when granted with the grant option.
+func (c *Connection) GrantedPrivileges(_ context.Context, user, dbName string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["GrantedPrivileges"]; err != nil {
+		return nil, err
+	}
+
+	if _, ok := c.Grants[user][dbName]; !ok {
+		return nil, nil
+	}
+
+	if _, ok := c.GrantOptions[user][dbName]; ok {
+		return []string{"ALL", "GRANT OPTION"}, nil
+	}
+
+	return []string{"ALL"}, nil
+}
+
+// ListGrants reports the databases the user has been granted the privileges on, as a single ALL privilege.
+func (c *Connection) ListGrants(_ context.Context, user string) ([]datastore.Grant, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["ListGrants"]; err != nil {
+		return nil, err
+	}
+
+	grants := make([]datastore.Grant, 0, len(c.Grants[user]))
+
+	for dbName := range c.Grants[user] {
+		_, grantOption := c.GrantOptions[user][dbName]
+
+		grants = append(grants, datastore.Grant{Schema: dbName, Privilege: "ALL", GrantOption: grantOption})
+	}
+
+	sort.Slice(grants, func(i, j int) bool {
+		return grants[i].Schema < grants[j].Schema
+	})
+
+	return grants, nil
+}
+
+func (c *Connection) Annotate(_ context.Context, _, dbName, tenant string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["Annotate"]; err != nil {
+		return err
+	}
+
+	c.Annotations[dbName] = tenant
+
+	return nil
+}
+
+func (c *Connection) EnsureExtensions(_ context.Context, dbName string, extensions []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["EnsureExtensions"]; err != nil {
+		return err
+	}
+
+	c.Extensions[dbName] = append([]string(nil), extensions...)
+
+	return nil
+}
+
+func (c *Connection) SetUserSearchPath(_ context.Context, user, _ string, searchPath []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["SetUserSearchPath"]; err != nil {
+		return err
+	}
+
+	c.SearchPaths[user] = append([]string(nil), searchPath...)
+
+	return nil
+}
+
+func (c *Connection) CurrentUser(context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["CurrentUser"]; err != nil {
+		return "", err
+	}
+
+	return c.CurrentUserName, nil
+}
+
+func (c *Connection) DatabaseEncoding(_ context.Context, dbName string) (datastore.Encoding, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["DatabaseEncoding"]; err != nil {
+		return datastore.Encoding{}, err
+	}
+
+	if encoding, ok := c.Encodings[dbName]; ok {
+		return encoding, nil
+	}
+
+	return c.DefaultDBEncoding, nil
+}
+
+func (c *Connection) DefaultEncoding(context.Context) (datastore.Encoding, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["DefaultEncoding"]; err != nil {
+		return datastore.Encoding{}, err
+	}
+
+	return c.DefaultDBEncoding, nil
+}
+
+func (c *Connection) EndpointsHealth(context.Context) ([]datastore.EndpointHealth, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["EndpointsHealth"]; err != nil {
+		return nil, err
+	}
+
+	return c.Health, nil
+}
+
+func (c *Connection) DatastoreSize(context.Context) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["DatastoreSize"]; err != nil {
+		return 0, err
+	}
+
+	return c.Size, nil
+}
+
+func (c *Connection) Exec(_ context.Context, dbName, statement string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["Exec"]; err != nil {
+		return err
+	}
+
+	if _, ok := c.DBs[dbName]; !ok {
+		return fmt.Errorf("database %s does not exist", dbName)
+	}
+
+	c.Statements[dbName] = append(c.Statements[dbName], statement)
+
+	return nil
+}
+
+// TruncateData discards the statements performed against the database, preserving its grants and annotations.
+func (c *Connection) TruncateData(_ context.Context, schema string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["TruncateData"]; err != nil {
+		return err
+	}
+
+	if _, ok := c.DBs[schema]; !ok {
+		return fmt.Errorf("database %s does not exist", schema)
+	}
+
+	delete(c.Statements, schema)
+	c.Truncated[schema] = struct{}{}
+
+	return nil
+}
+
+// Transaction restores the tracked state upon failure, simulating a rollback:
+// the changes performed concurrently out of the transaction are restored as well.
+func (c *Connection) Transaction(ctx context.Context, fn func(ctx context.Context, tx datastore.Connection) error) error {
+	c.mu.Lock()
+	if err := c.Errors["Transaction"]; err != nil {
+		c.mu.Unlock()
+
+		return err
+	}
+
+	users, dbs, grants, grantOptions, disabled, annotations := c.snapshot()
+	c.mu.Unlock()
+
+	if err := fn(ctx, c); err != nil {
+		c.mu.Lock()
+		c.Users, c.DBs, c.Grants, c.GrantOptions, c.Disabled, c.Annotations = users, dbs, grants, grantOptions, disabled, annotations
+		c.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *Connection) DeleteUser(_ context.Context, user string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["DeleteUser"]; err != nil {
+		return err
+	}
+
+	delete(c.Users, user)
+	delete(c.Grants, user)
+	delete(c.GrantOptions, user)
+	delete(c.Disabled, user)
+	delete(c.PasswordExpiries, user)
+
+	return nil
+}
+
+func (c *Connection) DeleteDB(_ context.Context, dbName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["DeleteDB"]; err != nil {
+		return err
+	}
+
+	delete(c.DBs, dbName)
+	delete(c.Annotations, dbName)
+	delete(c.Extensions, dbName)
+	delete(c.Tablespaces, dbName)
+	delete(c.Templates, dbName)
+
+	return nil
+}
+
+// RenameDatabase moves the database along with its grants and annotations.
+func (c *Connection) RenameDatabase(_ context.Context, oldName, newName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["RenameDatabase"]; err != nil {
+		return err
+	}
+
+	if _, ok := c.DBs[oldName]; !ok {
+		return fmt.Errorf("database %s does not exist", oldName)
+	}
+
+	if _, ok := c.DBs[newName]; ok {
+		return fmt.Errorf("database %s already exists", newName)
+	}
+
+	delete(c.DBs, oldName)
+	c.DBs[newName] = struct{}{}
+
+	for _, dbs := range []map[string]map[string]struct{}{c.Grants, c.GrantOptions} {
+		for _, granted := range dbs {
+			if _, ok := granted[oldName]; ok {
+				delete(granted, oldName)
+				granted[newName] = struct{}{}
+			}
+		}
+	}
+
+	if tenant, ok := c.Annotations[oldName]; ok {
+		delete(c.Annotations, oldName)
+		c.Annotations[newName] = tenant
+	}
+
+	if extensions, ok := c.Extensions[oldName]; ok {
+		delete(c.Extensions, oldName)
+		c.Extensions[newName] = extensions
+	}
+
+	if tablespace, ok := c.Tablespaces[oldName]; ok {
+		delete(c.Tablespaces, oldName)
+		c.Tablespaces[newName] = tablespace
+	}
+
+	if template, ok := c.Templates[oldName]; ok {
+		delete(c.Templates, oldName)
+		c.Templates[newName] = template
+	}
+
+	return nil
+}
+
+func (c *Connection) RevokePrivileges(_ context.Context, user, dbName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["RevokePrivileges"]; err != nil {
+		return err
+	}
+
+	delete(c.Grants[user], dbName)
+	delete(c.GrantOptions[user], dbName)
+
+	return nil
+}
+
+func (c *Connection) RevokeAllAndDisableUser(_ context.Context, user, dbName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["RevokeAllAndDisableUser"]; err != nil {
+		return err
+	}
+
+	delete(c.Grants[user], dbName)
+	delete(c.GrantOptions[user], dbName)
+	c.Disabled[user] = struct{}{}
+
+	return nil
+}
+
+func (c *Connection) GetConnectionString() string {
+	return c.ConnectionString
+}
+
+func (c *Connection) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["Close"]; err != nil {
+		return err
+	}
+
+	c.Closed = true
+
+	return nil
+}
+
+func (c *Connection) Check(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.Errors["Check"]
+}
+
+func (c *Connection) Healthcheck(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.Errors["Healthcheck"]
+}
+
+func (c *Connection) Driver() string {
+	return c.DriverName
+}
+
+func (c *Connection) Capabilities() datastore.Capabilities {
+	return c.Supported
+}
+
+// Migrate creates the Tenant Control Plane database in the target, if it's a fake Connection too.
+func (c *Connection) Migrate(ctx context.Context, tcp kamajiv1alpha1.TenantControlPlane, target datastore.Connection) error {
+	c.mu.Lock()
+	err := c.Errors["Migrate"]
+	c.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if fake, ok := target.(*Connection); ok {
+		return fake.CreateDB(ctx, tcp.Status.Storage.Setup.Schema)
+	}
+
+	return nil
+}
+
+// snapshot returns a deep copy of the tracked state: it must be called holding the lock.
+func (c *Connection) snapshot() (users map[string]string, dbs map[string]struct{}, grants, grantOptions map[string]map[string]struct{}, disabled map[string]struct{}, annotations map[string]string) {
+	users = make(map[string]string, len(c.Users))
+	for k, v := range c.Users {
+		users[k] = v
+	}
+
+	dbs = make(map[string]struct{}, len(c.DBs))
+	for k := range c.DBs {
+		dbs[k] = struct{}{}
+	}
+
+	grants = copyGrants(c.Grants)
+	grantOptions = copyGrants(c.GrantOptions)
+
+	disabled = make(map[string]struct{}, len(c.Disabled))
+	for k := range c.Disabled {
+		disabled[k] = struct{}{}
+	}
+
+	annotations = make(map[string]string, len(c.Annotations))
+	for k, v := range c.Annotations {
+		annotations[k] = v
+	}
+
+	return users, dbs, grants, grantOptions, disabled, annotations
+}
+
+func copyGrants(in map[string]map[string]struct{}) map[string]map[string]struct{} {
+	out := make(map[string]map[string]struct{}, len(in))
+	for user, userGrants := range in {
+		out[user] = make(map[string]struct{}, len(userGrants))
+		for dbName := range userGrants {
+			out[user][dbName] = struct{}{}
+		}
+	}
+
+	return out
+}
+
+func (c *Connection) Lock(_ context.Context, key string) (func(ctx context.Context) error, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Errors["Lock"]; err != nil {
+		return nil, err
+	}
+
+	if _, ok := c.Locks[key]; ok {
+		return nil, errors.NewLockedError(key)
+	}
+
+	c.Locks[key] = struct{}{}
+
+	return func(context.Context) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		delete(c.Locks, key)
+
+		return nil
+	}, nil
+}
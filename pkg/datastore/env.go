@@ -0,0 +1,66 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+)
+
+// envReferenceRegexp matches the ${VAR} references to the environment variables.
+var envReferenceRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ResolveEnv returns a copy of the given DataStore, replacing the ${VAR} references in the endpoints, the direct endpoints,
+// the TLS server name, and the maintenance database with the values of the environment variables of the running process:
+// an error is returned if any referenced variable is not set, or empty, rather than connecting to a literal reference.
+func ResolveEnv(ds kamajiv1alpha1.DataStore) (kamajiv1alpha1.DataStore, error) {
+	resolved := *ds.DeepCopy()
+
+	var err error
+
+	for i, endpoint := range resolved.Spec.Endpoints {
+		if resolved.Spec.Endpoints[i], err = expandEnv("endpoints", endpoint); err != nil {
+			return ds, err
+		}
+	}
+
+	for i, endpoint := range resolved.Spec.DirectEndpoints {
+		if resolved.Spec.DirectEndpoints[i], err = expandEnv("directEndpoints", endpoint); err != nil {
+			return ds, err
+		}
+	}
+
+	if resolved.Spec.TLSConfig.ServerName, err = expandEnv("tlsConfig.serverName", resolved.Spec.TLSConfig.ServerName); err != nil {
+		return ds, err
+	}
+
+	if resolved.Spec.MaintenanceDatabase, err = expandEnv("maintenanceDatabase", resolved.Spec.MaintenanceDatabase); err != nil {
+		return ds, err
+	}
+
+	return resolved, nil
+}
+
+func expandEnv(field, value string) (string, error) {
+	var err error
+
+	expanded := envReferenceRegexp.ReplaceAllStringFunc(value, func(reference string) string {
+		name := envReferenceRegexp.FindStringSubmatch(reference)[1]
+
+		env, ok := os.LookupEnv(name)
+		if (!ok || len(env) == 0) && err == nil {
+			err = fmt.Errorf("the environment variable %s referenced by the DataStore %s is not set", name, field)
+		}
+
+		return env
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return expanded, nil
+}
@@ -0,0 +1,264 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package errors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func NewCreateUserError(err error) error {
+	return errors.Wrap(err, "cannot create user")
+}
+
+func NewSetUserPasswordError(err error) error {
+	return errors.Wrap(err, "cannot set user password")
+}
+
+func NewSetUserPasswordExpiryError(err error) error {
+	return errors.Wrap(err, "cannot set user password expiry")
+}
+
+// UserAlreadyExistsError is returned when the user to create has been already created, such as out of band.
+type UserAlreadyExistsError struct {
+	err error
+}
+
+func (u UserAlreadyExistsError) Error() string {
+	return "user already exists: " + u.err.Error()
+}
+
+func (u UserAlreadyExistsError) Unwrap() error {
+	return u.err
+}
+
+func NewUserAlreadyExistsError(err error) error {
+	return UserAlreadyExistsError{err: err}
+}
+
+func NewGrantPrivilegesError(err error) error {
+	return errors.Wrap(err, "cannot grant privileges")
+}
+
+func NewCheckUserExistsError(err error) error {
+	return errors.Wrap(err, "cannot check if user exists")
+}
+
+func NewCheckUserLoginError(err error) error {
+	return errors.Wrap(err, "cannot check if user can log in")
+}
+
+func NewCheckGrantExistsError(err error) error {
+	return errors.Wrap(err, "cannot check if grant exists")
+}
+
+func NewCheckDatabaseOwnershipError(err error) error {
+	return errors.Wrap(err, "cannot check the database ownership")
+}
+
+func NewAnnotateError(err error) error {
+	return errors.Wrap(err, "cannot annotate the tenant ownership")
+}
+
+func NewCheckPrivilegeError(err error) error {
+	return errors.Wrap(err, "cannot check if privilege exists")
+}
+
+func NewEnsureExtensionsError(err error) error {
+	return errors.Wrap(err, "cannot ensure the database extensions")
+}
+
+func NewSetUserSearchPathError(err error) error {
+	return errors.Wrap(err, "cannot set the user search path")
+}
+
+func NewDisableUserError(err error) error {
+	return errors.Wrap(err, "cannot disable user")
+}
+
+func NewDeleteUserError(err error) error {
+	return errors.Wrap(err, "cannot delete user")
+}
+
+func NewExecError(err error) error {
+	return errors.Wrap(err, "cannot execute the statement")
+}
+
+func NewTruncateDataError(err error) error {
+	return errors.Wrap(err, "cannot truncate the data")
+}
+
+func NewRenameDatabaseError(err error) error {
+	return errors.Wrap(err, "cannot rename database")
+}
+
+func NewCannotDeleteDatabaseError(err error) error {
+	return errors.Wrap(err, "cannot delete database")
+}
+
+func NewCheckDatabaseExistError(err error) error {
+	return errors.Wrap(err, "cannot check if database exists")
+}
+
+func NewRevokePrivilegesError(err error) error {
+	return errors.Wrap(err, "cannot revoke privileges")
+}
+
+func NewCloseConnectionError(err error) error {
+	return errors.Wrap(err, "cannot close connection")
+}
+
+func NewCheckConnectionError(err error) error {
+	return errors.Wrap(err, "cannot check connection")
+}
+
+func NewCreateDBError(err error) error {
+	return errors.Wrap(err, "cannot create database")
+}
+
+func NewCloneDBError(err error) error {
+	return errors.Wrap(err, "cannot clone database")
+}
+
+func NewGrantedPrivilegesError(err error) error {
+	return errors.Wrap(err, "cannot retrieve granted privileges")
+}
+
+func NewListGrantsError(err error) error {
+	return errors.Wrap(err, "cannot list the grants")
+}
+
+func NewDatabaseEncodingError(err error) error {
+	return errors.Wrap(err, "cannot retrieve the database encoding")
+}
+
+func NewHealthcheckError(err error) error {
+	return errors.Wrap(err, "cannot check the admin privileges")
+}
+
+func NewCheckDatabaseOverlapsError(err error) error {
+	return errors.Wrap(err, "cannot check if database overlaps")
+}
+
+func NewCurrentUserError(err error) error {
+	return errors.Wrap(err, "cannot retrieve the current user")
+}
+
+func NewDatastoreSizeError(err error) error {
+	return errors.Wrap(err, "cannot retrieve the datastore size")
+}
+
+// QuotaExceededError is returned when the data store size is over the configured limit,
+// refusing the provisioning of new tenants.
+type QuotaExceededError struct {
+	size  int64
+	limit int64
+}
+
+func (q QuotaExceededError) Error() string {
+	return fmt.Sprintf("the data store size of %d bytes exceeds the limit of %d bytes", q.size, q.limit)
+}
+
+func NewQuotaExceededError(size, limit int64) error {
+	return QuotaExceededError{size: size, limit: limit}
+}
+
+// StatementTimeoutError is returned when a statement performed against the data store
+// has been terminated due to the configured timeouts.
+type StatementTimeoutError struct {
+	err error
+}
+
+func (s StatementTimeoutError) Error() string {
+	return "statement has been terminated due to timeout: " + s.err.Error()
+}
+
+func (s StatementTimeoutError) Unwrap() error {
+	return s.err
+}
+
+func NewStatementTimeoutError(err error) error {
+	return StatementTimeoutError{err: err}
+}
+
+// InvalidConfigError is returned when the data store configuration is not valid, such as its endpoints
+// or certificates: it's not going to be fixed by retrying, until the DataStore is amended.
+type InvalidConfigError struct {
+	err error
+}
+
+func (i InvalidConfigError) Error() string {
+	return "invalid data store configuration: " + i.err.Error()
+}
+
+func (i InvalidConfigError) Unwrap() error {
+	return i.err
+}
+
+func NewInvalidConfigError(err error) error {
+	return InvalidConfigError{err: err}
+}
+
+// NewTLSVerificationError is returned when the data store certificate cannot be verified: retrying is pointless
+// until the CA bundle, or the server name, of the DataStore is amended.
+func NewTLSVerificationError(err error) error {
+	return NewInvalidConfigError(errors.Wrap(err, "cannot verify the data store certificate, check the CA bundle and the server name"))
+}
+
+// NotReadyError is returned when the data store is not accepting connections yet, such as a just created instance:
+// the reconciliation is enqueued back, waiting for it.
+type NotReadyError struct {
+	err error
+}
+
+func (n NotReadyError) Error() string {
+	return "the data store is not accepting connections yet: " + n.err.Error()
+}
+
+func (n NotReadyError) Unwrap() error {
+	return n.err
+}
+
+func NewNotReadyError(err error) error {
+	return NotReadyError{err: err}
+}
+
+// LockedError is returned when the advisory lock is held by a different session, such as the setup performed
+// concurrently by a different operator instance during a leader transition: the reconciliation is enqueued back.
+type LockedError struct {
+	key string
+}
+
+func (l LockedError) Error() string {
+	return fmt.Sprintf("the lock for %s is held by a concurrent session", l.key)
+}
+
+func NewLockedError(key string) error {
+	return LockedError{key: key}
+}
+
+func NewLockError(err error) error {
+	return errors.Wrap(err, "cannot acquire the lock")
+}
+
+func NewUnlockError(err error) error {
+	return errors.Wrap(err, "cannot release the lock")
+}
+
+// MissingPrivilegesError is returned by the healthcheck when the Kamaji user is lacking the privileges required
+// to provision the Tenant Control Planes: it requires the intervention of the data store administrators.
+type MissingPrivilegesError struct {
+	user       string
+	privileges []string
+}
+
+func (m MissingPrivilegesError) Error() string {
+	return fmt.Sprintf("the user %s is missing the %s privileges required to provision the tenants", m.user, strings.Join(m.privileges, ", "))
+}
+
+func NewMissingPrivilegesError(user string, privileges []string) error {
+	return MissingPrivilegesError{user: user, privileges: privileges}
+}
@@ -0,0 +1,543 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	goerrors "github.com/pkg/errors"
+	"go.etcd.io/etcd/api/v3/authpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	etcdclient "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/pkg/datastore/errors"
+)
+
+const (
+	// rangeEnd is the key following the last key of the range.
+	// If rangeEnd is ‘\0’, the range is all keys greater than or equal to the key argument
+	// source: https://etcd.io/docs/v3.5/learning/api/
+	rangeEnd = "\\0"
+	// etcdHealthProbeTimeout bounds the probe of a single member, since the unreachable ones are blocking until the deadline.
+	etcdHealthProbeTimeout = 5 * time.Second
+)
+
+func NewETCDConnection(config ConnectionConfig) (Connection, error) {
+	endpoints := make([]string, 0, len(config.Endpoints))
+
+	for _, ep := range config.Endpoints {
+		endpoints = append(endpoints, ep.String())
+	}
+
+	cfg := etcdclient.Config{
+		Endpoints: endpoints,
+		TLS:       config.TLSConfig,
+	}
+
+	if dialer := config.Dialer; dialer != nil {
+		cfg.DialOptions = append(cfg.DialOptions, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", addr)
+		}))
+	}
+
+	client, err := etcdclient.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	etcdClient := &EtcdClient{
+		Client: *client,
+	}
+	// etcd authenticates the clients by means of the client certificate Common Name.
+	if config.TLSConfig != nil && len(config.TLSConfig.Certificates) > 0 && len(config.TLSConfig.Certificates[0].Certificate) > 0 {
+		if crt, crtErr := x509.ParseCertificate(config.TLSConfig.Certificates[0].Certificate[0]); crtErr == nil {
+			etcdClient.user = crt.Subject.CommonName
+		}
+	}
+
+	return etcdClient, nil
+}
+
+type EtcdClient struct {
+	Client etcdclient.Client
+	// user is the Common Name of the client certificate used to authenticate.
+	user string
+}
+
+func (e *EtcdClient) CreateUser(ctx context.Context, user, password string) error {
+	if _, err := e.Client.Auth.UserAddWithOptions(ctx, user, password, &etcdclient.UserAddOptions{NoPassword: true}); err != nil {
+		if goerrors.Is(err, rpctypes.ErrUserAlreadyExist) {
+			return errors.NewUserAlreadyExistsError(err)
+		}
+
+		return errors.NewCreateUserError(err)
+	}
+
+	return nil
+}
+
+// SetUserPassword is a no-op since the etcd users have no password, being authenticated by the client certificate.
+func (e *EtcdClient) SetUserPassword(context.Context, string, string) error {
+	return nil
+}
+
+// SetUserPasswordExpiry is a no-op since the etcd users have no password, being authenticated by the client certificate.
+func (e *EtcdClient) SetUserPasswordExpiry(context.Context, string, time.Duration) error {
+	return nil
+}
+
+func (e *EtcdClient) CreateDB(context.Context, string) error {
+	return nil
+}
+
+func (e *EtcdClient) CreateDBWithOptions(context.Context, string, CreateDBOptions) error {
+	return nil
+}
+
+// CloneDB is not supported, since the etcd tenants are isolated by key prefixes, rather than databases.
+func (e *EtcdClient) CloneDB(context.Context, string, string, CreateDBOptions) error {
+	return errors.NewCloneDBError(fmt.Errorf("the etcd driver does not support cloning a database"))
+}
+
+func (e *EtcdClient) GrantPrivileges(ctx context.Context, user, dbName string) error {
+	if _, err := e.Client.Auth.RoleAdd(ctx, dbName); err != nil {
+		return errors.NewGrantPrivilegesError(err)
+	}
+
+	permission := etcdclient.PermissionType(authpb.READWRITE)
+	key := e.buildKey(dbName)
+	if _, err := e.Client.RoleGrantPermission(ctx, user, key, rangeEnd, permission); err != nil {
+		return errors.NewGrantPrivilegesError(err)
+	}
+
+	if _, err := e.Client.UserGrantRole(ctx, user, dbName); err != nil {
+		return errors.NewGrantPrivilegesError(err)
+	}
+
+	return nil
+}
+
+// GrantPrivilegesWithOptions ignores the grant option, since the etcd permissions can be granted only by the root user.
+func (e *EtcdClient) GrantPrivilegesWithOptions(ctx context.Context, user, dbName string, _ GrantOptions) error {
+	return e.GrantPrivileges(ctx, user, dbName)
+}
+
+func (e *EtcdClient) UserExists(ctx context.Context, user string) (bool, error) {
+	if _, err := e.Client.UserGet(ctx, user); err != nil {
+		if goerrors.As(err, &rpctypes.ErrGRPCUserNotFound) {
+			return false, nil
+		}
+
+		return false, errors.NewCheckUserExistsError(err)
+	}
+
+	return true, nil
+}
+
+// UserHasLogin returns true if the user exists, since etcd has no login to disable.
+func (e *EtcdClient) UserHasLogin(ctx context.Context, user string) (bool, error) {
+	exists, err := e.UserExists(ctx, user)
+	if err != nil {
+		return false, errors.NewCheckUserLoginError(err)
+	}
+
+	return exists, nil
+}
+
+func (e *EtcdClient) DBExists(context.Context, string) (bool, error) {
+	return true, nil
+}
+
+// DBOverlaps checks if the tenant key prefix is nested in, or contains, the key prefix granted to any other role,
+// avoiding the mixing of the tenants data. The permissions on the root prefix, such as the administrative ones,
+// are not referring to a tenant, thus ignored.
+func (e *EtcdClient) DBOverlaps(ctx context.Context, dbName string) (bool, error) {
+	roles, err := e.Client.RoleList(ctx)
+	if err != nil {
+		return false, errors.NewCheckDatabaseOverlapsError(err)
+	}
+
+	key := e.buildKey(dbName)
+
+	for _, role := range roles.Roles {
+		if role == dbName {
+			continue
+		}
+
+		res, err := e.Client.RoleGet(ctx, role)
+		if err != nil {
+			return false, errors.NewCheckDatabaseOverlapsError(err)
+		}
+
+		for _, perm := range res.Perm {
+			permKey := string(perm.Key)
+			if len(permKey) <= 1 {
+				continue
+			}
+
+			if strings.HasPrefix(permKey, key) || strings.HasPrefix(key, permKey) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (e *EtcdClient) GrantPrivilegesExists(ctx context.Context, username, dbName string) (bool, error) {
+	_, err := e.Client.RoleGet(ctx, dbName)
+	if err != nil {
+		if goerrors.As(err, &rpctypes.ErrGRPCRoleNotFound) {
+			return false, nil
+		}
+
+		return false, errors.NewCheckGrantExistsError(err)
+	}
+
+	user, err := e.Client.UserGet(ctx, username)
+	if err != nil {
+		return false, errors.NewCheckGrantExistsError(err)
+	}
+
+	for _, i := range user.Roles {
+		if i == dbName {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// GrantPrivilegesWithOptionsExists ignores the grant option, not supported by etcd.
+func (e *EtcdClient) GrantPrivilegesWithOptionsExists(ctx context.Context, username, dbName string, _ GrantOptions) (bool, error) {
+	return e.GrantPrivilegesExists(ctx, username, dbName)
+}
+
+// GrantedPrivileges returns the permission types of the tenant role on its key prefix, such as READWRITE.
+func (e *EtcdClient) GrantedPrivileges(ctx context.Context, username, dbName string) ([]string, error) {
+	if ok, err := e.GrantPrivilegesExists(ctx, username, dbName); err != nil || !ok {
+		return nil, err
+	}
+
+	role, err := e.Client.RoleGet(ctx, dbName)
+	if err != nil {
+		return nil, errors.NewGrantedPrivilegesError(err)
+	}
+
+	var privileges []string
+
+	for _, perm := range role.Perm {
+		if string(perm.Key) == e.buildKey(dbName) {
+			privileges = append(privileges, authpb.Permission_Type_name[int32(perm.PermType)])
+		}
+	}
+
+	sort.Strings(privileges)
+
+	return privileges, nil
+}
+
+// ListGrants returns the permissions of the user roles, the schema being the key prefix they're granted on:
+// etcd has no grant option.
+func (e *EtcdClient) ListGrants(ctx context.Context, username string) ([]Grant, error) {
+	user, err := e.Client.UserGet(ctx, username)
+	if err != nil {
+		if goerrors.As(err, &rpctypes.ErrGRPCUserNotFound) {
+			return nil, nil
+		}
+
+		return nil, errors.NewListGrantsError(err)
+	}
+
+	var grants []Grant
+
+	for _, name := range user.Roles {
+		role, roleErr := e.Client.RoleGet(ctx, name)
+		if roleErr != nil {
+			return nil, errors.NewListGrantsError(roleErr)
+		}
+
+		for _, perm := range role.Perm {
+			grants = append(grants, Grant{
+				Schema:    strings.Trim(string(perm.Key), "/"),
+				Privilege: authpb.Permission_Type_name[int32(perm.PermType)],
+			})
+		}
+	}
+
+	sort.Slice(grants, func(i, j int) bool {
+		if grants[i].Schema != grants[j].Schema {
+			return grants[i].Schema < grants[j].Schema
+		}
+
+		return grants[i].Privilege < grants[j].Privilege
+	})
+
+	return grants, nil
+}
+
+func (e *EtcdClient) HasPrivilege(ctx context.Context, username, dbName, privilege string) (bool, error) {
+	if ok, err := e.GrantPrivilegesExists(ctx, username, dbName); err != nil || !ok {
+		return false, err
+	}
+
+	role, err := e.Client.RoleGet(ctx, dbName)
+	if err != nil {
+		return false, errors.NewCheckPrivilegeError(err)
+	}
+
+	privilege = strings.ToUpper(privilege)
+
+	for _, perm := range role.Perm {
+		if string(perm.Key) != e.buildKey(dbName) {
+			continue
+		}
+		// The READWRITE permission is implying both READ and WRITE ones.
+		if permType := authpb.Permission_Type_name[int32(perm.PermType)]; permType == privilege || perm.PermType == authpb.READWRITE {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Annotate is a no-op since etcd has no metadata for users and roles,
+// and additional keys in the tenant prefix would be served to the Tenant Control Plane API server.
+// DatabaseExistsForUser returns true if any key exists with the given prefix, since etcd has no metadata
+// to record the tenant ownership: the key prefixes collisions are detected upon the provisioning by DBOverlaps.
+func (e *EtcdClient) DatabaseExistsForUser(ctx context.Context, _, dbName, _ string) (bool, error) {
+	return e.DBExists(ctx, dbName)
+}
+
+func (e *EtcdClient) Annotate(context.Context, string, string, string) error {
+	return nil
+}
+
+func (e *EtcdClient) EnsureExtensions(context.Context, string, []string) error {
+	return nil
+}
+
+func (e *EtcdClient) SetUserSearchPath(context.Context, string, string, []string) error {
+	return nil
+}
+
+// CurrentUser returns the user authenticated by the client certificate, since etcd has no API to retrieve it.
+func (e *EtcdClient) CurrentUser(context.Context) (string, error) {
+	if len(e.user) == 0 {
+		return "", errors.NewCurrentUserError(fmt.Errorf("no client certificate has been provided"))
+	}
+
+	return e.user, nil
+}
+
+// Exec is not supported, since etcd has no statements.
+func (e *EtcdClient) Exec(context.Context, string, string) error {
+	return errors.NewExecError(fmt.Errorf("the etcd driver does not support statements"))
+}
+
+// TruncateData deletes the keys of the given prefix, preserving the role and its permissions:
+// the deletion is recorded to the audit sink, although etcd has no statements.
+func (e *EtcdClient) TruncateData(ctx context.Context, schema string) error {
+	prefix := e.buildKey(schema)
+
+	_, err := e.Client.Delete(ctx, prefix, etcdclient.WithPrefix())
+	audit(ctx, e.Driver(), fmt.Sprintf("DELETE PREFIX %s", prefix), err)
+
+	if err != nil {
+		return errors.NewTruncateDataError(err)
+	}
+
+	return nil
+}
+
+// DatabaseEncoding returns an empty Encoding, since the etcd keys and values are stored as bytes.
+func (e *EtcdClient) DatabaseEncoding(context.Context, string) (Encoding, error) {
+	return Encoding{}, nil
+}
+
+func (e *EtcdClient) DefaultEncoding(context.Context) (Encoding, error) {
+	return Encoding{}, nil
+}
+
+// EndpointsHealth probes the status of each member: it's unhealthy when not reachable, or raising any error,
+// such as the NOSPACE alarm.
+func (e *EtcdClient) EndpointsHealth(ctx context.Context) ([]EndpointHealth, error) {
+	endpoints := e.Client.Endpoints()
+
+	health := make([]EndpointHealth, 0, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		item := EndpointHealth{Endpoint: endpoint}
+
+		probeCtx, cancelFn := context.WithTimeout(ctx, etcdHealthProbeTimeout)
+		status, err := e.Client.Status(probeCtx, endpoint)
+		cancelFn()
+
+		switch {
+		case err != nil:
+			item.Message = err.Error()
+		case len(status.Errors) > 0:
+			item.Message = strings.Join(status.Errors, ", ")
+		default:
+			item.Healthy = true
+		}
+
+		health = append(health, item)
+	}
+
+	return health, nil
+}
+
+// DatastoreSize returns the largest backend database size among the etcd members,
+// since the data is replicated across them.
+func (e *EtcdClient) DatastoreSize(ctx context.Context) (int64, error) {
+	var size int64
+
+	for _, endpoint := range e.Client.Endpoints() {
+		status, err := e.Client.Status(ctx, endpoint)
+		if err != nil {
+			return 0, errors.NewDatastoreSizeError(err)
+		}
+
+		if status.DbSize > size {
+			size = status.DbSize
+		}
+	}
+
+	return size, nil
+}
+
+// Transaction executes the given function step-wise, since the etcd authentication API is not transactional.
+func (e *EtcdClient) Transaction(ctx context.Context, fn func(ctx context.Context, tx Connection) error) error {
+	return fn(ctx, e)
+}
+
+// DeleteUser removes the tenant etcd user: since the per-tenant client certificate Common Name is mapped
+// to the said user, its removal is revoking the access to the etcd cluster for the given certificate.
+func (e *EtcdClient) DeleteUser(ctx context.Context, user string) error {
+	if _, err := e.Client.Auth.UserDelete(ctx, user); err != nil {
+		return errors.NewDeleteUserError(err)
+	}
+
+	return nil
+}
+
+func (e *EtcdClient) DeleteDB(ctx context.Context, dbName string) error {
+	prefix := e.buildKey(dbName)
+	if _, err := e.Client.Delete(ctx, prefix, etcdclient.WithPrefix()); err != nil {
+		return errors.NewCannotDeleteDatabaseError(err)
+	}
+
+	return nil
+}
+
+// RenameDatabase is not supported, since the keys and the role permissions are bound to the prefix.
+func (e *EtcdClient) RenameDatabase(context.Context, string, string) error {
+	return errors.NewRenameDatabaseError(fmt.Errorf("the etcd driver does not support renaming a database"))
+}
+
+func (e *EtcdClient) RevokePrivileges(ctx context.Context, user, dbName string) error {
+	if _, err := e.Client.Auth.RoleDelete(ctx, dbName); err != nil {
+		return errors.NewRevokePrivilegesError(err)
+	}
+
+	return nil
+}
+
+// RevokeAllAndDisableUser revokes the tenant role from the user: etcd has no login to disable,
+// since the tenant client certificate Common Name is mapped to the user, which is left with no permissions.
+func (e *EtcdClient) RevokeAllAndDisableUser(ctx context.Context, user, dbName string) error {
+	if _, err := e.Client.UserRevokeRole(ctx, user, dbName); err != nil && !goerrors.Is(err, rpctypes.ErrRoleNotGranted) && !goerrors.Is(err, rpctypes.ErrUserNotFound) {
+		return errors.NewDisableUserError(err)
+	}
+
+	return nil
+}
+
+func (e *EtcdClient) GetConnectionString() string {
+	// There's no need for connection string in etcd client:
+	// it's not used by Kine
+	return ""
+}
+
+func (e *EtcdClient) Close() error {
+	if err := e.Client.Close(); err != nil {
+		return errors.NewCloseConnectionError(err)
+	}
+
+	return nil
+}
+
+func (e *EtcdClient) Check(ctx context.Context) error {
+	if _, err := e.Client.AuthStatus(ctx); err != nil {
+		return checkConnectionError(err)
+	}
+
+	return nil
+}
+
+// Healthcheck verifies the Kamaji user is allowed to manage the tenant users and roles, listing the roles:
+// it's restricted to the users with the root role.
+func (e *EtcdClient) Healthcheck(ctx context.Context) error {
+	if _, err := e.Client.RoleList(ctx); err != nil {
+		if goerrors.Is(err, rpctypes.ErrPermissionDenied) || goerrors.Is(err, rpctypes.ErrGRPCPermissionDenied) {
+			return errors.NewMissingPrivilegesError(e.Client.Username, []string{"root"})
+		}
+
+		return errors.NewHealthcheckError(err)
+	}
+
+	return nil
+}
+
+func (e *EtcdClient) Driver() string {
+	return string(kamajiv1alpha1.EtcdDriver)
+}
+
+func (e *EtcdClient) Capabilities() Capabilities {
+	return Capabilities{
+		KeyPrefixes:     true,
+		EndpointsHealth: true,
+	}
+}
+
+func (e *EtcdClient) buildKey(key string) string {
+	return fmt.Sprintf("/%s/", key)
+}
+
+func (e *EtcdClient) Migrate(ctx context.Context, tcp kamajiv1alpha1.TenantControlPlane, target Connection) error {
+	targetClient := target.(*EtcdClient) //nolint:forcetypeassert
+
+	if err := target.Check(ctx); err != nil {
+		return err
+	}
+
+	response, err := e.Client.Get(ctx, e.buildKey(fmt.Sprintf("%s_%s", tcp.GetNamespace(), tcp.GetName())), etcdclient.WithRange(rangeEnd))
+	if err != nil {
+		return err
+	}
+
+	for _, kv := range response.Kvs {
+		if _, err = targetClient.Client.Put(ctx, string(kv.Key), string(kv.Value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Lock is a no-op, since the etcd operations performed upon the setup are idempotent.
+func (e *EtcdClient) Lock(context.Context, string) (func(ctx context.Context) error, error) {
+	return func(context.Context) error {
+		return nil
+	}, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// endpointCheckTimeout bounds the connection check against each endpoint, upon the failover.
+const endpointCheckTimeout = 5 * time.Second
+
+// connectWithFailover returns the Connection to the first reachable endpoint of the given configuration,
+// trying them in order: when none is reachable, the Connection to the first endpoint is returned,
+// leaving the errors to be reported by the subsequent statements, as with a single endpoint.
+// Unless specified, the TLS server name is set to the host of the tried endpoint.
+func connectWithFailover(ctx context.Context, cc ConnectionConfig, connectFn func(ConnectionConfig) (Connection, error)) (Connection, error) {
+	explicitServerName := len(cc.TLSConfig.ServerName) > 0
+
+	configFor := func(index int) ConnectionConfig {
+		config := cc
+		// The tried endpoint comes first, since the drivers are connecting to the first one.
+		config.Endpoints = make([]ConnectionEndpoint, 0, len(cc.Endpoints))
+		config.Endpoints = append(config.Endpoints, cc.Endpoints[index])
+
+		for i, endpoint := range cc.Endpoints {
+			if i != index {
+				config.Endpoints = append(config.Endpoints, endpoint)
+			}
+		}
+
+		config.TLSConfig = cc.TLSConfig.Clone()
+
+		if !explicitServerName {
+			config.TLSConfig.ServerName = cc.Endpoints[index].Host
+		}
+
+		return config
+	}
+
+	if len(cc.Endpoints) == 1 {
+		return connectFn(configFor(0))
+	}
+
+	logger := log.FromContext(ctx)
+
+	for index, endpoint := range cc.Endpoints {
+		conn, err := connectFn(configFor(index))
+		if err != nil {
+			return nil, err
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, endpointCheckTimeout)
+		err = conn.Check(checkCtx)
+		cancel()
+
+		if err == nil {
+			// Reporting the failover, while the connections to the first endpoint are the norm.
+			if index > 0 {
+				logger.Info("connected to the DataStore endpoint", "endpoint", endpoint.String())
+			} else {
+				logger.V(1).Info("connected to the DataStore endpoint", "endpoint", endpoint.String())
+			}
+
+			return conn, nil
+		}
+
+		logger.Info("DataStore endpoint is not reachable, trying the next one", "endpoint", endpoint.String(), "error", err.Error())
+
+		if closeErr := conn.Close(); closeErr != nil {
+			logger.Error(closeErr, "cannot close the DataStore connection", "endpoint", endpoint.String())
+		}
+	}
+
+	logger.Info("none of the DataStore endpoints is reachable, falling back to the first one", "endpoint", cc.Endpoints[0].String())
+
+	return connectFn(configFor(0))
+}
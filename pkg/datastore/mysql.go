@@ -0,0 +1,1131 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/JamesStewy/go-mysqldump"
+	"github.com/go-sql-driver/mysql"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/pkg/datastore/errors"
+)
+
+const (
+	defaultProtocol = "tcp"
+	sqlErrorNoRows  = "sql: no rows in result set"
+	// mysqlQueryInterruptedErrorNumber is the ER_QUERY_INTERRUPTED error code.
+	mysqlQueryInterruptedErrorNumber = 1317
+	// mysqlQueryTimeoutErrorNumber is the ER_QUERY_TIMEOUT error code.
+	mysqlQueryTimeoutErrorNumber = 3024
+	// mysqlDBCreateExistsErrorNumber is the ER_DB_CREATE_EXISTS error code.
+	mysqlDBCreateExistsErrorNumber = 1007
+	// mysqlCannotUserErrorNumber is the ER_CANNOT_USER error code, returned when creating an already existing user.
+	mysqlCannotUserErrorNumber = 1396
+	// mysqlNoSuchTableErrorNumber is the ER_NO_SUCH_TABLE error code.
+	mysqlNoSuchTableErrorNumber = 1146
+	// mysqlBadDBErrorNumber is the ER_BAD_DB_ERROR error code, returned when the database doesn't exist.
+	mysqlBadDBErrorNumber = 1049
+	// mysqlNonExistingGrantErrorNumber is the ER_NONEXISTING_GRANT error code, returned when revoking a missing grant.
+	mysqlNonExistingGrantErrorNumber = 1141
+)
+
+const (
+	mysqlFetchUserStatement         = "SELECT User FROM mysql.user WHERE User= ? LIMIT 1"
+	mysqlFetchUserLockStatement     = "SELECT account_locked FROM mysql.user WHERE User= ? LIMIT 1"
+	mysqlFetchDBStatement           = "SELECT SCHEMA_NAME FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME=? LIMIT 1"
+	mysqlShowGrantsStatement        = "SHOW GRANTS FOR `%s`@`%%`"
+	mysqlFetchPrivilegeStatement    = "SELECT PRIVILEGE_TYPE FROM INFORMATION_SCHEMA.SCHEMA_PRIVILEGES WHERE GRANTEE = ? AND TABLE_SCHEMA = ? AND PRIVILEGE_TYPE = ? LIMIT 1"
+	mysqlCreateDBStatement          = "CREATE DATABASE IF NOT EXISTS `%s`"
+	mysqlCreateUserStatement        = "CREATE USER `%s`@`%%` IDENTIFIED BY %s"
+	mysqlGrantPrivilegesStatement   = "GRANT ALL PRIVILEGES ON `%s`.* TO `%s`@`%%`"
+	mysqlGrantOptionClause          = " WITH GRANT OPTION"
+	mysqlRevokeGrantOptionStatement = "REVOKE GRANT OPTION ON `%s`.* FROM `%s`@`%%`"
+	mysqlCreateMetadataStatement    = "CREATE TABLE IF NOT EXISTS `%s`.`kamaji_metadata` (`id` TINYINT NOT NULL PRIMARY KEY, `tenant` VARCHAR(512) NOT NULL, `user` VARCHAR(255) NOT NULL)"
+	mysqlUpsertMetadataStatement    = "REPLACE INTO `%s`.`kamaji_metadata` (`id`, `tenant`, `user`) VALUES (1, ?, ?)"
+	mysqlFetchMetadataStatement     = "SELECT `tenant`, `user` FROM `%s`.`kamaji_metadata` WHERE `id` = 1"
+	mysqlDropDBStatement            = "DROP DATABASE IF EXISTS `%s`"
+	mysqlDropUserStatement          = "DROP USER IF EXISTS `%s`"
+	mysqlLockUserStatement          = "ALTER USER `%s`@`%%` ACCOUNT LOCK"
+	mysqlSetUserPasswordStatement   = "ALTER USER `%s`@`%%` IDENTIFIED BY %s ACCOUNT UNLOCK"
+	mysqlPasswordExpireStatement    = "ALTER USER `%s`@`%%` PASSWORD EXPIRE INTERVAL %d DAY"
+	mysqlPasswordNeverExpireStmt    = "ALTER USER `%s`@`%%` PASSWORD EXPIRE NEVER"
+	mysqlRevokePrivilegesStatement  = "REVOKE ALL PRIVILEGES ON `%s`.* FROM `%s`"
+	mysqlCurrentUserStatement       = "SELECT CURRENT_USER()"
+	mysqlHealthcheckStatement       = "SELECT PRIVILEGE_TYPE FROM INFORMATION_SCHEMA.USER_PRIVILEGES WHERE GRANTEE = ? AND PRIVILEGE_TYPE IN ('CREATE', 'CREATE USER')"
+	mysqlDatabaseEncodingStatement  = "SELECT DEFAULT_CHARACTER_SET_NAME, DEFAULT_COLLATION_NAME FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME = ? LIMIT 1"
+	mysqlDefaultEncodingStatement   = "SELECT @@character_set_server, @@collation_server"
+	mysqlDatastoreSizeStatement     = "SELECT COALESCE(SUM(DATA_LENGTH + INDEX_LENGTH), 0) FROM INFORMATION_SCHEMA.TABLES"
+	mysqlLowerCaseTableNames        = "SELECT @@lower_case_table_names"
+	mysqlNoBackslashEscapes         = "SELECT @@SESSION.sql_mode LIKE '%NO_BACKSLASH_ESCAPES%'"
+	mysqlGetLockStatement           = "SELECT GET_LOCK(?, 0)"
+	mysqlGrantedPrivilegesStatement = "SELECT PRIVILEGE_TYPE FROM INFORMATION_SCHEMA.SCHEMA_PRIVILEGES WHERE GRANTEE = ? AND TABLE_SCHEMA = ? ORDER BY PRIVILEGE_TYPE"
+	mysqlListGrantsStatement        = "SELECT TABLE_SCHEMA, PRIVILEGE_TYPE, IS_GRANTABLE FROM INFORMATION_SCHEMA.SCHEMA_PRIVILEGES WHERE GRANTEE = ? ORDER BY TABLE_SCHEMA, PRIVILEGE_TYPE"
+	mysqlReleaseLockStatement       = "SELECT RELEASE_LOCK(?)"
+	mysqlFetchTablesStatement       = "SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'"
+	mysqlCreateTableLikeStatement   = "CREATE TABLE IF NOT EXISTS `%s`.`%s` LIKE `%s`.`%s`"
+	mysqlDisableForeignKeyChecks    = "SET FOREIGN_KEY_CHECKS = 0"
+	// mysqlProgramNameAttribute is the connection attribute labelling the connections opened by Kamaji.
+	mysqlProgramNameAttribute = "program_name"
+	// mysqlFetchDropStatements returns the statements dropping the tables and views of the given database,
+	// preserving the Kamaji metadata one.
+	mysqlFetchDropStatements = "SELECT CONCAT(IF(TABLE_TYPE = 'VIEW', 'DROP VIEW IF EXISTS ', 'DROP TABLE IF EXISTS '), '`', TABLE_SCHEMA, '`.`', TABLE_NAME, '`') " +
+		"FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME <> 'kamaji_metadata'"
+)
+
+type MySQLConnection struct {
+	db        *sql.DB
+	connector ConnectionEndpoint
+	// lowerCaseTableNames caches the lower_case_table_names server setting, fetched upon its first usage.
+	lowerCaseTableNames *int
+	// noBackslashEscapes caches if the NO_BACKSLASH_ESCAPES SQL mode is set, fetched upon its first usage.
+	noBackslashEscapes *bool
+	mu                 sync.Mutex
+}
+
+func (c *MySQLConnection) Migrate(ctx context.Context, tcp kamajiv1alpha1.TenantControlPlane, target Connection) error {
+	// Ensuring the connection is working as expected
+	if err := target.Check(ctx); err != nil {
+		return err
+	}
+	// Creating the target schema if it doesn't exist
+	if ok, _ := target.DBExists(ctx, tcp.Status.Storage.Setup.Schema); !ok {
+		if err := target.CreateDB(ctx, tcp.Status.Storage.Setup.Schema); err != nil {
+			return err
+		}
+	}
+	// Dumping the old datastore in a local file
+	dir, err := os.MkdirTemp("", string(tcp.GetUID()))
+	if err != nil {
+		return fmt.Errorf("unable to create temp directory for MySQL migration: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err = c.db.ExecContext(ctx, fmt.Sprintf("USE %s_%s", tcp.GetNamespace(), tcp.GetName())); err != nil {
+		return fmt.Errorf("unable to switch DB for MySQL migration: %w", err)
+	}
+
+	dumper, err := mysqldump.Register(c.db, dir, fmt.Sprintf("%d", time.Now().Unix()))
+	if err != nil {
+		return fmt.Errorf("unable to create MySQL dumper: %w", err)
+	}
+	defer dumper.Close()
+
+	dumpFile, err := dumper.Dump()
+	if err != nil {
+		return fmt.Errorf("unable to dump from MySQL: %w", err)
+	}
+
+	statements, err := os.ReadFile(dumpFile)
+	if err != nil {
+		return fmt.Errorf("cannot read dump file for MySQL: %w", err)
+	}
+	// Executing the import to the target datastore
+	targetClient := target.(*MySQLConnection) //nolint:forcetypeassert
+
+	if _, err = targetClient.db.ExecContext(ctx, fmt.Sprintf("USE %s_%s", tcp.GetNamespace(), tcp.GetName())); err != nil {
+		return fmt.Errorf("unable to switch DB for MySQL migration: %w", err)
+	}
+
+	if _, err = targetClient.db.ExecContext(ctx, string(statements)); err != nil {
+		return fmt.Errorf("cannot execute dump statements for MySQL: %w", err)
+	}
+
+	return nil
+}
+
+func (c *MySQLConnection) Driver() string {
+	return string(kamajiv1alpha1.KineMySQLDriver)
+}
+
+func (c *MySQLConnection) Capabilities() Capabilities {
+	return Capabilities{
+		Passwords:     true,
+		GrantOption:   true,
+		CloneDatabase: true,
+		Exec:          true,
+		Encoding:      true,
+	}
+}
+
+// mysqlTLSConfigSequence makes unique the keys of the TLS configurations registered in the driver, which is global,
+// since each connection has its own CA and server name.
+var mysqlTLSConfigSequence uint64
+
+func NewMySQLConnection(config ConnectionConfig) (Connection, error) {
+	mysqlConfig, err := newMySQLConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	// The registered TLS configuration is cloned by the connector, thus it's no more needed.
+	if len(mysqlConfig.TLSConfig) > 0 {
+		defer mysql.DeregisterTLSConfig(mysqlConfig.TLSConfig)
+	}
+
+	// The connector is built from the configuration rather than from its DSN, which can't hold the reserved
+	// characters of the credentials.
+	connector, err := mysql.NewConnector(mysqlConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MySQLConnection{db: sql.OpenDB(connector), connector: config.Endpoints[0]}, nil
+}
+
+// newMySQLConfig returns the driver configuration used to connect to the data store.
+func newMySQLConfig(config ConnectionConfig) (*mysql.Config, error) {
+	nameDB := fmt.Sprintf("%s(%s)", defaultProtocol, config.Endpoints[0].String())
+
+	var parameters string
+	if len(config.Parameters) > 0 {
+		parameters = url.Values(config.Parameters).Encode()
+	}
+
+	// The credentials are not part of the DSN, since they could contain characters breaking its parsing.
+	dsn := fmt.Sprintf("%s/%s?%s", nameDB, config.DBName, parameters)
+
+	mysqlConfig, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	mysqlConfig.User = config.User
+	mysqlConfig.Passwd = config.Password
+
+	mysqlConfig.DBName = config.DBName
+	mysqlConfig.ReadTimeout = config.ReadTimeout
+	mysqlConfig.WriteTimeout = config.WriteTimeout
+
+	if dialer := config.Dialer; dialer != nil {
+		mysqlConfig.Net = proxyNetworkName(config.ProxyURL)
+
+		mysql.RegisterDialContext(mysqlConfig.Net, func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, defaultProtocol, addr)
+		})
+	}
+
+	// The Unix domain socket is trusted as local, with no TLS nor proxy.
+	if len(config.UnixSocket) > 0 {
+		mysqlConfig.Net, mysqlConfig.Addr = "unix", config.UnixSocket
+	} else {
+		// The configuration must be deregistered once the connector has been built.
+		tlsKey := fmt.Sprintf("kamaji-%d", atomic.AddUint64(&mysqlTLSConfigSequence, 1))
+
+		if err = mysql.RegisterTLSConfig(tlsKey, config.TLSConfig); err != nil {
+			return nil, err
+		}
+
+		mysqlConfig.TLSConfig = tlsKey
+	}
+
+	if mysqlConfig.Params == nil {
+		mysqlConfig.Params = map[string]string{}
+	}
+
+	if config.ReadTimeout > 0 {
+		mysqlConfig.Params["net_read_timeout"] = strconv.Itoa(int(config.ReadTimeout.Seconds()))
+	}
+
+	if config.WriteTimeout > 0 {
+		mysqlConfig.Params["net_write_timeout"] = strconv.Itoa(int(config.WriteTimeout.Seconds()))
+	}
+	// The label is sent as the program_name connection attribute, reported by the
+	// performance_schema.session_connect_attrs table.
+	if len(config.ApplicationName) > 0 {
+		mysqlConfig.ConnectionAttributes = mysqlProgramNameAttribute + ":" + config.ApplicationName
+	}
+
+	return mysqlConfig, nil
+}
+
+func (c *MySQLConnection) GetConnectionString() string {
+	return c.connector.String()
+}
+
+func (c *MySQLConnection) Close() error {
+	if err := c.db.Close(); err != nil {
+		return errors.NewCloseConnectionError(err)
+	}
+
+	return nil
+}
+
+func (c *MySQLConnection) Check(ctx context.Context) error {
+	if err := c.db.PingContext(ctx); err != nil {
+		return checkConnectionError(err)
+	}
+
+	return nil
+}
+
+// Healthcheck verifies the Kamaji user holds the global CREATE and CREATE USER privileges,
+// required to create the tenant databases and users.
+func (c *MySQLConnection) Healthcheck(ctx context.Context) error {
+	user, err := c.CurrentUser(ctx)
+	if err != nil {
+		return errors.NewHealthcheckError(err)
+	}
+	// The current user is returned as user@host, while the grantees are quoted as 'user'@'host'.
+	name, host, _ := strings.Cut(user, "@")
+
+	rows, err := c.db.QueryContext(ctx, mysqlHealthcheckStatement, fmt.Sprintf("'%s'@'%s'", name, host))
+	logStatement(ctx, c.Driver(), mysqlHealthcheckStatement, err)
+
+	if err != nil {
+		return errors.NewHealthcheckError(mysqlStatementTimeout(err))
+	}
+	// The rows must be closed to release the underlying connection back to the pool.
+	defer rows.Close()
+
+	missing := sets.New("CREATE", "CREATE USER")
+
+	for rows.Next() {
+		var privilege string
+		if err = rows.Scan(&privilege); err != nil {
+			return errors.NewHealthcheckError(err)
+		}
+
+		missing.Delete(privilege)
+	}
+
+	if err = rows.Err(); err != nil {
+		return errors.NewHealthcheckError(err)
+	}
+
+	if missing.Len() > 0 {
+		return errors.NewMissingPrivilegesError(user, sets.List(missing))
+	}
+
+	return nil
+}
+
+func (c *MySQLConnection) CreateUser(ctx context.Context, user, password string) error {
+	password, err := c.quoteLiteral(ctx, password)
+	if err != nil {
+		return errors.NewCreateUserError(err)
+	}
+
+	if err = c.mutate(ctx, mysqlCreateUserStatement, user, password); err != nil {
+		if mysqlErr := (&mysql.MySQLError{}); goerrors.As(err, &mysqlErr) && mysqlErr.Number == mysqlCannotUserErrorNumber {
+			return errors.NewUserAlreadyExistsError(err)
+		}
+
+		return errors.NewCreateUserError(err)
+	}
+
+	return nil
+}
+
+func (c *MySQLConnection) SetUserPassword(ctx context.Context, user, password string) error {
+	password, err := c.quoteLiteral(ctx, password)
+	if err != nil {
+		return errors.NewSetUserPasswordError(err)
+	}
+
+	if err = c.mutate(ctx, mysqlSetUserPasswordStatement, user, password); err != nil {
+		return errors.NewSetUserPasswordError(err)
+	}
+
+	return nil
+}
+
+// SetUserPasswordExpiry sets the password lifetime, counted by MySQL from the latest password change:
+// the interval is rounded up to whole days, since it's the granularity supported by MySQL.
+func (c *MySQLConnection) SetUserPasswordExpiry(ctx context.Context, user string, expiry time.Duration) error {
+	var err error
+
+	if expiry == 0 {
+		err = c.mutate(ctx, mysqlPasswordNeverExpireStmt, user)
+	} else {
+		days := int64((expiry + 24*time.Hour - 1) / (24 * time.Hour))
+		err = c.mutate(ctx, mysqlPasswordExpireStatement, user, days)
+	}
+
+	if err != nil {
+		return errors.NewSetUserPasswordExpiryError(err)
+	}
+
+	return nil
+}
+
+// CreateDBWithOptions creates the database as CreateDB, since MySQL has no options to be applied.
+func (c *MySQLConnection) CreateDBWithOptions(ctx context.Context, dbName string, _ CreateDBOptions) error {
+	return c.CreateDB(ctx, dbName)
+}
+
+// CloneDB creates the database copying the structure of the template database tables, since MySQL has no statement
+// to copy a database: the data, the views, and the routines are not copied, nor the Kamaji metadata table.
+func (c *MySQLConnection) CloneDB(ctx context.Context, dbName, template string, _ CreateDBOptions) error {
+	template, err := c.dbName(ctx, template)
+	if err != nil {
+		return errors.NewCloneDBError(err)
+	}
+
+	exists, err := c.DBExists(ctx, template)
+	if err != nil {
+		return errors.NewCloneDBError(err)
+	}
+
+	if !exists {
+		return errors.NewCloneDBError(fmt.Errorf("the template database %s does not exist", template))
+	}
+
+	tables, err := c.tables(ctx, template)
+	if err != nil {
+		return errors.NewCloneDBError(err)
+	}
+
+	if err = c.CreateDB(ctx, dbName); err != nil {
+		return err
+	}
+
+	if dbName, err = c.dbName(ctx, dbName); err != nil {
+		return errors.NewCloneDBError(err)
+	}
+
+	for _, table := range tables {
+		if table == "kamaji_metadata" {
+			continue
+		}
+
+		if err = c.mutate(ctx, mysqlCreateTableLikeStatement, dbName, table, template, table); err != nil {
+			return errors.NewCloneDBError(err)
+		}
+	}
+
+	return nil
+}
+
+// tables returns the base tables of the given database.
+func (c *MySQLConnection) tables(ctx context.Context, dbName string) ([]string, error) {
+	rows, err := c.db.QueryContext(ctx, mysqlFetchTablesStatement, dbName)
+	logStatement(ctx, c.Driver(), mysqlFetchTablesStatement, err)
+
+	if err != nil {
+		return nil, mysqlStatementTimeout(err)
+	}
+	defer rows.Close()
+
+	var tables []string
+
+	for rows.Next() {
+		var table string
+		if err = rows.Scan(&table); err != nil {
+			return nil, err
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables, rows.Err()
+}
+
+// CreateDB is atomic thanks to the IF NOT EXISTS clause: the already existing database error is tolerated anyway,
+// since overlapping reconciliations could race upon the creation.
+func (c *MySQLConnection) CreateDB(ctx context.Context, dbName string) error {
+	dbName, err := c.dbName(ctx, dbName)
+	if err != nil {
+		return errors.NewCreateDBError(err)
+	}
+
+	var mysqlErr *mysql.MySQLError
+
+	if err := c.mutate(ctx, mysqlCreateDBStatement, dbName); err != nil && !(goerrors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDBCreateExistsErrorNumber) {
+		return errors.NewCreateDBError(err)
+	}
+
+	return nil
+}
+
+func (c *MySQLConnection) GrantPrivileges(ctx context.Context, user, dbName string) error {
+	return c.GrantPrivilegesWithOptions(ctx, user, dbName, GrantOptions{})
+}
+
+func (c *MySQLConnection) GrantPrivilegesWithOptions(ctx context.Context, user, dbName string, opts GrantOptions) error {
+	dbName, err := c.dbName(ctx, dbName)
+	if err != nil {
+		return errors.NewGrantPrivilegesError(err)
+	}
+
+	if opts.WithGrantOption {
+		if err = c.mutate(ctx, mysqlGrantPrivilegesStatement+mysqlGrantOptionClause, user, dbName); err != nil {
+			return errors.NewGrantPrivilegesError(err)
+		}
+
+		return nil
+	}
+
+	if err = c.mutate(ctx, mysqlGrantPrivilegesStatement, user, dbName); err != nil {
+		return errors.NewGrantPrivilegesError(err)
+	}
+	// Granting the privileges doesn't revoke the grant option previously granted.
+	var mysqlErr *mysql.MySQLError
+
+	if err = c.mutate(ctx, mysqlRevokeGrantOptionStatement, user, dbName); err != nil && !(goerrors.As(err, &mysqlErr) && mysqlErr.Number == mysqlNonExistingGrantErrorNumber) {
+		return errors.NewGrantPrivilegesError(err)
+	}
+
+	return nil
+}
+
+func (c *MySQLConnection) UserExists(ctx context.Context, user string) (bool, error) {
+	checker := func(row *sql.Row) (bool, error) {
+		var name string
+		if err := row.Scan(&name); err != nil {
+			if c.checkEmptyQueryResult(err) {
+				return false, nil
+			}
+
+			return false, err
+		}
+
+		return name == user, nil
+	}
+
+	ok, err := c.check(ctx, mysqlFetchUserStatement, checker, user)
+	if err != nil {
+		return false, errors.NewCheckUserExistsError(err)
+	}
+
+	return ok, nil
+}
+
+// UserHasLogin checks if the user account is not locked, as it happens upon the RevokeAllAndDisableUser function.
+func (c *MySQLConnection) UserHasLogin(ctx context.Context, user string) (bool, error) {
+	checker := func(row *sql.Row) (bool, error) {
+		var locked string
+		if err := row.Scan(&locked); err != nil {
+			if c.checkEmptyQueryResult(err) {
+				return false, nil
+			}
+
+			return false, err
+		}
+
+		return locked == "N", nil
+	}
+
+	ok, err := c.check(ctx, mysqlFetchUserLockStatement, checker, user)
+	if err != nil {
+		return false, errors.NewCheckUserLoginError(err)
+	}
+
+	return ok, nil
+}
+
+func (c *MySQLConnection) DBExists(ctx context.Context, dbName string) (bool, error) {
+	dbName, err := c.dbName(ctx, dbName)
+	if err != nil {
+		return false, errors.NewCheckDatabaseExistError(err)
+	}
+
+	checker := func(row *sql.Row) (bool, error) {
+		var name string
+		if err := row.Scan(&name); err != nil {
+			if c.checkEmptyQueryResult(err) {
+				return false, nil
+			}
+
+			return false, err
+		}
+
+		return name == dbName, nil
+	}
+
+	ok, err := c.check(ctx, mysqlFetchDBStatement, checker, dbName)
+	if err != nil {
+		return false, errors.NewCheckDatabaseExistError(err)
+	}
+
+	return ok, nil
+}
+
+// DBOverlaps always returns false since MySQL schemas are isolated namespaces.
+func (c *MySQLConnection) DBOverlaps(context.Context, string) (bool, error) {
+	return false, nil
+}
+
+func (c *MySQLConnection) GrantPrivilegesExists(ctx context.Context, user, dbName string) (bool, error) {
+	grants, err := c.showGrants(ctx, user, dbName)
+	if err != nil {
+		return false, err
+	}
+
+	return grants.withoutGrantOption || grants.withGrantOption, nil
+}
+
+func (c *MySQLConnection) GrantPrivilegesWithOptionsExists(ctx context.Context, user, dbName string, opts GrantOptions) (bool, error) {
+	grants, err := c.showGrants(ctx, user, dbName)
+	if err != nil {
+		return false, err
+	}
+
+	if opts.WithGrantOption {
+		return grants.withGrantOption, nil
+	}
+
+	return grants.withoutGrantOption, nil
+}
+
+// mysqlGrants reports the privileges granted on a database, distinguishing the grant option.
+type mysqlGrants struct {
+	withGrantOption    bool
+	withoutGrantOption bool
+}
+
+func (c *MySQLConnection) showGrants(ctx context.Context, user, dbName string) (grants mysqlGrants, err error) {
+	dbName, err = c.dbName(ctx, dbName)
+	if err != nil {
+		return grants, errors.NewGrantPrivilegesError(err)
+	}
+
+	statementShowGrantsStatement := fmt.Sprintf(mysqlShowGrantsStatement, user)
+	rows, err := c.db.QueryContext(ctx, statementShowGrantsStatement)
+	if err != nil {
+		return grants, errors.NewGrantPrivilegesError(mysqlStatementTimeout(err))
+	}
+	// The rows must be closed to release the underlying connection back to the pool.
+	defer rows.Close()
+
+	expected := fmt.Sprintf(mysqlGrantPrivilegesStatement, user, dbName)
+	var grant string
+
+	for rows.Next() {
+		if err = rows.Scan(&grant); err != nil {
+			return grants, errors.NewGrantPrivilegesError(err)
+		}
+
+		switch grant {
+		case expected:
+			grants.withoutGrantOption = true
+		case expected + mysqlGrantOptionClause:
+			grants.withGrantOption = true
+		}
+	}
+
+	return grants, nil
+}
+
+func (c *MySQLConnection) HasPrivilege(ctx context.Context, user, dbName, privilege string) (bool, error) {
+	dbName, err := c.dbName(ctx, dbName)
+	if err != nil {
+		return false, errors.NewCheckPrivilegeError(err)
+	}
+
+	privilege = strings.ToUpper(privilege)
+
+	checker := func(row *sql.Row) (bool, error) {
+		var name string
+		if err := row.Scan(&name); err != nil {
+			if c.checkEmptyQueryResult(err) {
+				return false, nil
+			}
+
+			return false, err
+		}
+
+		return name == privilege, nil
+	}
+
+	ok, err := c.check(ctx, mysqlFetchPrivilegeStatement, checker, fmt.Sprintf("'%s'@'%%'", user), dbName, privilege)
+	if err != nil {
+		return false, errors.NewCheckPrivilegeError(err)
+	}
+
+	return ok, nil
+}
+
+func (c *MySQLConnection) GrantedPrivileges(ctx context.Context, user, dbName string) ([]string, error) {
+	dbName, err := c.dbName(ctx, dbName)
+	if err != nil {
+		return nil, errors.NewGrantedPrivilegesError(err)
+	}
+
+	rows, err := c.db.QueryContext(ctx, mysqlGrantedPrivilegesStatement, fmt.Sprintf("'%s'@'%%'", user), dbName)
+	logStatement(ctx, c.Driver(), mysqlGrantedPrivilegesStatement, err)
+
+	if err != nil {
+		return nil, errors.NewGrantedPrivilegesError(mysqlStatementTimeout(err))
+	}
+	// The rows must be closed to release the underlying connection back to the pool.
+	defer rows.Close()
+
+	var privileges []string
+
+	for rows.Next() {
+		var privilege string
+		if err = rows.Scan(&privilege); err != nil {
+			return nil, errors.NewGrantedPrivilegesError(err)
+		}
+
+		privileges = append(privileges, privilege)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewGrantedPrivilegesError(err)
+	}
+
+	return privileges, nil
+}
+
+// ListGrants returns the schema privileges of the user, the grant option being reported by each one of them.
+func (c *MySQLConnection) ListGrants(ctx context.Context, user string) ([]Grant, error) {
+	rows, err := c.db.QueryContext(ctx, mysqlListGrantsStatement, fmt.Sprintf("'%s'@'%%'", user))
+	logStatement(ctx, c.Driver(), mysqlListGrantsStatement, err)
+
+	if err != nil {
+		return nil, errors.NewListGrantsError(mysqlStatementTimeout(err))
+	}
+	// The rows must be closed to release the underlying connection back to the pool.
+	defer rows.Close()
+
+	var grants []Grant
+
+	for rows.Next() {
+		var grant Grant
+
+		var grantable string
+		if err = rows.Scan(&grant.Schema, &grant.Privilege, &grantable); err != nil {
+			return nil, errors.NewListGrantsError(err)
+		}
+
+		grant.GrantOption = grantable == "YES"
+
+		grants = append(grants, grant)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.NewListGrantsError(err)
+	}
+
+	return grants, nil
+}
+
+// DatabaseExistsForUser checks the tenant ownership stored in the metadata table of the given database:
+// a database with no metadata is not considered as belonging to the tenant.
+func (c *MySQLConnection) DatabaseExistsForUser(ctx context.Context, user, dbName, tenant string) (bool, error) {
+	dbName, err := c.dbName(ctx, dbName)
+	if err != nil {
+		return false, errors.NewCheckDatabaseOwnershipError(err)
+	}
+
+	var metadataTenant, metadataUser string
+
+	statement := fmt.Sprintf(mysqlFetchMetadataStatement, dbName)
+
+	err = c.db.QueryRowContext(ctx, statement).Scan(&metadataTenant, &metadataUser)
+	logStatement(ctx, c.Driver(), statement, err)
+
+	switch mysqlErr := (&mysql.MySQLError{}); {
+	case err == nil:
+		return metadataTenant == tenant && metadataUser == user, nil
+	case c.checkEmptyQueryResult(err):
+		return false, nil
+	case goerrors.As(err, &mysqlErr) && (mysqlErr.Number == mysqlNoSuchTableErrorNumber || mysqlErr.Number == mysqlBadDBErrorNumber):
+		return false, nil
+	default:
+		return false, errors.NewCheckDatabaseOwnershipError(mysqlStatementTimeout(err))
+	}
+}
+
+// EnsureExtensions is a no-op, since MySQL has no extensions.
+func (c *MySQLConnection) EnsureExtensions(context.Context, string, []string) error {
+	return nil
+}
+
+func (c *MySQLConnection) SetUserSearchPath(context.Context, string, string, []string) error {
+	return nil
+}
+
+// Annotate stores the tenant ownership in a metadata table of the given database,
+// since MySQL has no native comments for schemas, and for users only starting from 8.0.21.
+func (c *MySQLConnection) Annotate(ctx context.Context, user, dbName, tenant string) error {
+	dbName, err := c.dbName(ctx, dbName)
+	if err != nil {
+		return errors.NewAnnotateError(err)
+	}
+
+	if err = c.mutate(ctx, mysqlCreateMetadataStatement, dbName); err != nil {
+		return errors.NewAnnotateError(err)
+	}
+
+	_, err = c.db.ExecContext(ctx, fmt.Sprintf(mysqlUpsertMetadataStatement, dbName), tenant, user)
+	logStatement(ctx, c.Driver(), fmt.Sprintf(mysqlUpsertMetadataStatement, dbName), err)
+
+	if err != nil {
+		return errors.NewAnnotateError(mysqlStatementTimeout(err))
+	}
+
+	return nil
+}
+
+// DatabaseEncoding returns the default character set and collation of the given database,
+// applied to the tables created with no explicit ones, such as the kine one.
+func (c *MySQLConnection) DatabaseEncoding(ctx context.Context, dbName string) (Encoding, error) {
+	dbName, err := c.dbName(ctx, dbName)
+	if err != nil {
+		return Encoding{}, errors.NewDatabaseEncodingError(err)
+	}
+
+	var encoding Encoding
+
+	err = c.db.QueryRowContext(ctx, mysqlDatabaseEncodingStatement, dbName).Scan(&encoding.Charset, &encoding.Collation)
+	logStatement(ctx, c.Driver(), mysqlDatabaseEncodingStatement, err)
+
+	if err != nil {
+		return Encoding{}, errors.NewDatabaseEncodingError(mysqlStatementTimeout(err))
+	}
+
+	return encoding, nil
+}
+
+// DefaultEncoding returns the server character set and collation, applied to the databases created with no explicit ones.
+func (c *MySQLConnection) DefaultEncoding(ctx context.Context) (Encoding, error) {
+	var encoding Encoding
+
+	err := c.db.QueryRowContext(ctx, mysqlDefaultEncodingStatement).Scan(&encoding.Charset, &encoding.Collation)
+	logStatement(ctx, c.Driver(), mysqlDefaultEncodingStatement, err)
+
+	if err != nil {
+		return Encoding{}, errors.NewDatabaseEncodingError(mysqlStatementTimeout(err))
+	}
+
+	return encoding, nil
+}
+
+// DatastoreSize returns the size of the data and indexes of all the tables,
+// as reported by the storage engines statistics.
+func (c *MySQLConnection) DatastoreSize(ctx context.Context) (int64, error) {
+	var size int64
+
+	err := c.db.QueryRowContext(ctx, mysqlDatastoreSizeStatement).Scan(&size)
+	logStatement(ctx, c.Driver(), mysqlDatastoreSizeStatement, err)
+
+	if err != nil {
+		return 0, errors.NewDatastoreSizeError(mysqlStatementTimeout(err))
+	}
+
+	return size, nil
+}
+
+// EndpointsHealth returns no endpoints, since the MySQL driver reports no per-endpoint health.
+func (c *MySQLConnection) EndpointsHealth(context.Context) ([]EndpointHealth, error) {
+	return nil, nil
+}
+
+func (c *MySQLConnection) CurrentUser(ctx context.Context) (string, error) {
+	var user string
+
+	err := c.db.QueryRowContext(ctx, mysqlCurrentUserStatement).Scan(&user)
+	logStatement(ctx, c.Driver(), mysqlCurrentUserStatement, err)
+
+	if err != nil {
+		return "", errors.NewCurrentUserError(mysqlStatementTimeout(err))
+	}
+
+	return user, nil
+}
+
+// Transaction executes the given function step-wise: MySQL DDL statements, such as CREATE USER and GRANT,
+// are causing an implicit commit, thus they can't be rolled back.
+func (c *MySQLConnection) Transaction(ctx context.Context, fn func(ctx context.Context, tx Connection) error) error {
+	return fn(ctx, c)
+}
+
+func (c *MySQLConnection) DeleteUser(ctx context.Context, user string) error {
+	if err := c.mutate(ctx, mysqlDropUserStatement, user); err != nil {
+		return errors.NewDeleteUserError(err)
+	}
+
+	return nil
+}
+
+func (c *MySQLConnection) DeleteDB(ctx context.Context, dbName string) error {
+	dbName, err := c.dbName(ctx, dbName)
+	if err != nil {
+		return errors.NewCannotDeleteDatabaseError(err)
+	}
+
+	if err := c.mutate(ctx, mysqlDropDBStatement, dbName); err != nil {
+		return errors.NewCannotDeleteDatabaseError(err)
+	}
+
+	return nil
+}
+
+// RenameDatabase is not supported, since MySQL has no statement to rename a database:
+// the tables should be moved one by one to a new database, along with the grants.
+func (c *MySQLConnection) RenameDatabase(context.Context, string, string) error {
+	return errors.NewRenameDatabaseError(fmt.Errorf("the MySQL driver does not support renaming a database"))
+}
+
+func (c *MySQLConnection) RevokePrivileges(ctx context.Context, user, dbName string) error {
+	dbName, err := c.dbName(ctx, dbName)
+	if err != nil {
+		return errors.NewRevokePrivilegesError(err)
+	}
+
+	if err := c.mutate(ctx, mysqlRevokePrivilegesStatement, user, dbName); err != nil {
+		return errors.NewRevokePrivilegesError(err)
+	}
+
+	return nil
+}
+
+// RevokeAllAndDisableUser locks the user account before revoking its privileges, cutting off the new connections first:
+// the statements can't be performed atomically since causing an implicit commit, although both are idempotent.
+// The established connections are not terminated.
+func (c *MySQLConnection) RevokeAllAndDisableUser(ctx context.Context, user, dbName string) error {
+	if err := c.mutate(ctx, mysqlLockUserStatement, user); err != nil {
+		return errors.NewDisableUserError(err)
+	}
+
+	return c.RevokePrivileges(ctx, user, dbName)
+}
+
+// Exec performs the statement with a dedicated connection, since the selected database is bound to it.
+func (c *MySQLConnection) Exec(ctx context.Context, dbName, statement string) error {
+	dbName, err := c.dbName(ctx, dbName)
+	if err != nil {
+		return errors.NewExecError(err)
+	}
+
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return errors.NewExecError(mysqlStatementTimeout(err))
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, fmt.Sprintf("USE `%s`", dbName)); err != nil {
+		return errors.NewExecError(mysqlStatementTimeout(err))
+	}
+
+	_, err = conn.ExecContext(ctx, statement)
+	audit(ctx, c.Driver(), statement, err)
+	logStatement(ctx, c.Driver(), statement, err)
+
+	if err != nil {
+		return errors.NewExecError(mysqlStatementTimeout(err))
+	}
+
+	return nil
+}
+
+// TruncateData drops the tables and views of the given database with a dedicated connection, disabling the foreign
+// key checks for its session only: the Kamaji metadata table is preserved, along with the database ownership.
+func (c *MySQLConnection) TruncateData(ctx context.Context, schema string) error {
+	dbName, err := c.dbName(ctx, schema)
+	if err != nil {
+		return errors.NewTruncateDataError(err)
+	}
+
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return errors.NewTruncateDataError(mysqlStatementTimeout(err))
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, mysqlDisableForeignKeyChecks); err != nil {
+		return errors.NewTruncateDataError(mysqlStatementTimeout(err))
+	}
+
+	rows, err := conn.QueryContext(ctx, mysqlFetchDropStatements, dbName)
+	logStatement(ctx, c.Driver(), mysqlFetchDropStatements, err)
+
+	if err != nil {
+		return errors.NewTruncateDataError(mysqlStatementTimeout(err))
+	}
+
+	var statements []string
+
+	for rows.Next() {
+		var statement string
+		if err = rows.Scan(&statement); err != nil {
+			rows.Close()
+
+			return errors.NewTruncateDataError(err)
+		}
+
+		statements = append(statements, statement)
+	}
+	// The rows must be closed before performing the statements with the same connection.
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return errors.NewTruncateDataError(err)
+	}
+
+	for _, statement := range statements {
+		_, err = conn.ExecContext(ctx, statement)
+		audit(ctx, c.Driver(), statement, err)
+		logStatement(ctx, c.Driver(), statement, err)
+
+		if err != nil {
+			return errors.NewTruncateDataError(mysqlStatementTimeout(err))
+		}
+	}
+
+	return nil
+}
+
+// dbName normalizes the given database name according to the lower_case_table_names server setting:
+// when enabled, the databases are stored in lowercase, and the lookups are expected to match it.
+func (c *MySQLConnection) dbName(ctx context.Context, name string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lowerCaseTableNames == nil {
+		var value int
+
+		err := c.db.QueryRowContext(ctx, mysqlLowerCaseTableNames).Scan(&value)
+		logStatement(ctx, c.Driver(), mysqlLowerCaseTableNames, err)
+
+		if err != nil {
+			return "", mysqlStatementTimeout(err)
+		}
+
+		c.lowerCaseTableNames = &value
+	}
+
+	if *c.lowerCaseTableNames != 0 {
+		return strings.ToLower(name), nil
+	}
+
+	return name, nil
+}
+
+// quoteLiteral returns the given value as a quoted string literal, such as the passwords which can't be passed
+// as parameters to the account management statements: the quotes are doubled, and the backslashes escaped,
+// unless the NO_BACKSLASH_ESCAPES SQL mode is set.
+func (c *MySQLConnection) quoteLiteral(ctx context.Context, value string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.noBackslashEscapes == nil {
+		var noBackslashEscapes bool
+
+		err := c.db.QueryRowContext(ctx, mysqlNoBackslashEscapes).Scan(&noBackslashEscapes)
+		logStatement(ctx, c.Driver(), mysqlNoBackslashEscapes, err)
+
+		if err != nil {
+			return "", mysqlStatementTimeout(err)
+		}
+
+		c.noBackslashEscapes = &noBackslashEscapes
+	}
+
+	replacer := strings.NewReplacer(`'`, `''`, `\`, `\\`, "\x00", `\0`)
+	if *c.noBackslashEscapes {
+		replacer = strings.NewReplacer(`'`, `''`)
+	}
+
+	return "'" + replacer.Replace(value) + "'", nil
+}
+
+func (c *MySQLConnection) check(ctx context.Context, nonFilledStatement string, checker func(*sql.Row) (bool, error), args ...any) (bool, error) {
+	statement, err := c.db.Prepare(nonFilledStatement)
+	if err != nil {
+		return false, mysqlStatementTimeout(err)
+	}
+	defer statement.Close()
+
+	row := statement.QueryRowContext(ctx, args...)
+
+	ok, err := checker(row)
+	logStatement(ctx, c.Driver(), nonFilledStatement, err)
+
+	if err != nil {
+		return false, mysqlStatementTimeout(err)
+	}
+
+	return ok, nil
+}
+
+func (c *MySQLConnection) mutate(ctx context.Context, nonFilledStatement string, args ...any) error {
+	statement := fmt.Sprintf(nonFilledStatement, args...)
+
+	_, err := c.db.ExecContext(ctx, statement)
+	audit(ctx, c.Driver(), statement, err)
+	logStatement(ctx, c.Driver(), statement, err)
+
+	if err != nil {
+		return mysqlStatementTimeout(err)
+	}
+
+	return nil
+}
+
+func (c *MySQLConnection) checkEmptyQueryResult(err error) bool {
+	return err.Error() == sqlErrorNoRows
+}
+
+// mysqlStatementTimeout returns a StatementTimeoutError if the given error has been caused
+// by the server-side interruption of the statement, or by the connection timeouts.
+func mysqlStatementTimeout(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if goerrors.As(err, &mysqlErr) && (mysqlErr.Number == mysqlQueryInterruptedErrorNumber || mysqlErr.Number == mysqlQueryTimeoutErrorNumber) {
+		return errors.NewStatementTimeoutError(err)
+	}
+
+	var netErr net.Error
+	if goerrors.As(err, &netErr) && netErr.Timeout() {
+		return errors.NewStatementTimeoutError(err)
+	}
+
+	return err
+}
+
+// Lock acquires the named lock with a dedicated connection, since it's bound to the session:
+// GET_LOCK returns 1 once acquired, and 0 if held by a different session.
+func (c *MySQLConnection) Lock(ctx context.Context, key string) (func(ctx context.Context) error, error) {
+	name := advisoryLockName(key)
+
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, errors.NewLockError(mysqlStatementTimeout(err))
+	}
+
+	var acquired sql.NullInt64
+
+	err = conn.QueryRowContext(ctx, mysqlGetLockStatement, name).Scan(&acquired)
+	logStatement(ctx, c.Driver(), mysqlGetLockStatement, err)
+
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, errors.NewLockError(mysqlStatementTimeout(err))
+	}
+
+	if acquired.Int64 != 1 {
+		_ = conn.Close()
+
+		return nil, errors.NewLockedError(key)
+	}
+
+	return func(ctx context.Context) error {
+		defer conn.Close()
+
+		_, err := conn.ExecContext(ctx, mysqlReleaseLockStatement, name)
+		logStatement(ctx, c.Driver(), mysqlReleaseLockStatement, err)
+
+		if err != nil {
+			return errors.NewUnlockError(mysqlStatementTimeout(err))
+		}
+
+		return nil
+	}, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// testMySQLTLSServer is a minimal MySQL server advertising the TLS support: it records the server name requested
+// by the TLS handshake of the clients, which is aborted, since no certificate is served.
+type testMySQLTLSServer struct {
+	listener net.Listener
+
+	mu          sync.Mutex
+	serverNames []string
+}
+
+func newTestMySQLTLSServer() *testMySQLTLSServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	server := &testMySQLTLSServer{listener: listener}
+
+	go server.serve()
+
+	DeferCleanup(listener.Close)
+
+	return server
+}
+
+// Endpoint returns the endpoint the server is listening to.
+func (s *testMySQLTLSServer) Endpoint() ConnectionEndpoint {
+	return ConnectionEndpoint{Host: "127.0.0.1", Port: s.listener.Addr().(*net.TCPAddr).Port} //nolint:forcetypeassert
+}
+
+// ServerNames returns the server names requested by the TLS handshakes so far.
+func (s *testMySQLTLSServer) ServerNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.serverNames...)
+}
+
+func (s *testMySQLTLSServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer GinkgoRecover()
+			defer conn.Close()
+
+			if err := s.handle(conn); err != nil && !errors.Is(err, io.EOF) {
+				GinkgoWriter.Printf("test MySQL server: %s\n", err)
+			}
+		}()
+	}
+}
+
+func (s *testMySQLTLSServer) handle(conn net.Conn) error {
+	var handshake bytes.Buffer
+	// The protocol version, the server version, the connection id, and the first part of the authentication data.
+	handshake.WriteByte(10)
+	handshake.WriteString("8.0.0\x00")
+	_ = binary.Write(&handshake, binary.LittleEndian, uint32(1))
+	handshake.WriteString("01234567\x00")
+	// The lower capabilities, the protocol 4.1, TLS, and secure connection ones, the charset, and the status.
+	_ = binary.Write(&handshake, binary.LittleEndian, uint16(0x8A00))
+	handshake.WriteByte(45)
+	_ = binary.Write(&handshake, binary.LittleEndian, uint16(2))
+	// The upper capabilities, the plugin authentication one, the authentication data length, and the reserved bytes.
+	_ = binary.Write(&handshake, binary.LittleEndian, uint16(0x0008))
+	handshake.WriteByte(21)
+	handshake.Write(make([]byte, 10))
+	handshake.WriteString("89abcdefghij\x00mysql_native_password\x00")
+
+	if err := writeTestMySQLPacket(conn, 0, handshake.Bytes()); err != nil {
+		return err
+	}
+	// The SSL request packet, made of the capabilities, the max packet size, the charset, and the filler.
+	if _, err := io.ReadFull(conn, make([]byte, 4+32)); err != nil {
+		return err
+	}
+
+	return tls.Server(conn, &tls.Config{ //nolint:gosec
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			s.mu.Lock()
+			s.serverNames = append(s.serverNames, hello.ServerName)
+			s.mu.Unlock()
+
+			return nil, errors.New("no certificate is served")
+		},
+	}).Handshake()
+}
+
+func writeTestMySQLPacket(w io.Writer, sequence byte, payload []byte) error {
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), sequence}
+
+	_, err := w.Write(append(header, payload...))
+
+	return err
+}
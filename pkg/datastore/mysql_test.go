@@ -0,0 +1,252 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"sync"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MySQL connection", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Describe("setting the user password", func() {
+		It("should escape the quotes and the backslashes", func() {
+			connection, mock := newTestMySQLConnection()
+
+			mock.ExpectQuery(mysqlNoBackslashEscapes).WillReturnRows(sqlmock.NewRows([]string{"mode"}).AddRow(0))
+			mock.ExpectExec("ALTER USER `tenant`@`%` IDENTIFIED BY 'it''s\\\\a'' OR ''1''=''1' ACCOUNT UNLOCK").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(connection.SetUserPassword(ctx, "tenant", `it's\a' OR '1'='1`)).To(Succeed())
+		})
+
+		It("should not escape the backslashes with the NO_BACKSLASH_ESCAPES SQL mode", func() {
+			connection, mock := newTestMySQLConnection()
+
+			mock.ExpectQuery(mysqlNoBackslashEscapes).WillReturnRows(sqlmock.NewRows([]string{"mode"}).AddRow(1))
+			mock.ExpectExec("ALTER USER `tenant`@`%` IDENTIFIED BY 'it''s\\a' ACCOUNT UNLOCK").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(connection.SetUserPassword(ctx, "tenant", `it's\a`)).To(Succeed())
+		})
+
+		It("should fetch the SQL mode only once", func() {
+			connection, mock := newTestMySQLConnection()
+
+			mock.ExpectQuery(mysqlNoBackslashEscapes).WillReturnRows(sqlmock.NewRows([]string{"mode"}).AddRow(0))
+			mock.ExpectExec("ALTER USER `tenant`@`%` IDENTIFIED BY 'first' ACCOUNT UNLOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("ALTER USER `tenant`@`%` IDENTIFIED BY 'second' ACCOUNT UNLOCK").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(connection.SetUserPassword(ctx, "tenant", "first")).To(Succeed())
+			Expect(connection.SetUserPassword(ctx, "tenant", "second")).To(Succeed())
+		})
+	})
+	DescribeTable("creating a mixed-case database",
+		func(lowerCaseTableNames int, expectedName string) {
+			connection, mock := newTestMySQLConnection()
+
+			mock.ExpectQuery(mysqlLowerCaseTableNames).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(lowerCaseTableNames))
+			mock.ExpectExec("CREATE DATABASE IF NOT EXISTS `" + expectedName + "`").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(connection.CreateDB(ctx, "Legacy_Schema")).To(Succeed())
+		},
+		Entry("should preserve the name with case-sensitive names", 0, "Legacy_Schema"),
+		Entry("should fold the name to lowercase with lower_case_table_names", 1, "legacy_schema"),
+	)
+
+	DescribeTable("checking the user login",
+		func(locked string, expected bool) {
+			connection, mock := newTestMySQLConnection()
+
+			mock.ExpectPrepare(mysqlFetchUserLockStatement).ExpectQuery().
+				WithArgs("tenant").
+				WillReturnRows(sqlmock.NewRows([]string{"account_locked"}).AddRow(locked))
+
+			Expect(connection.UserHasLogin(ctx, "tenant")).To(Equal(expected))
+		},
+		Entry("should report the unlocked account", "N", true),
+		Entry("should report the locked account", "Y", false),
+	)
+
+	It("should report no login for a missing user", func() {
+		connection, mock := newTestMySQLConnection()
+
+		mock.ExpectPrepare(mysqlFetchUserLockStatement).ExpectQuery().
+			WithArgs("tenant").
+			WillReturnRows(sqlmock.NewRows([]string{"account_locked"}))
+
+		Expect(connection.UserHasLogin(ctx, "tenant")).To(BeFalse())
+	})
+
+	Describe("listing the grants", func() {
+		It("should return the privileges of the user across the schemas", func() {
+			connection, mock := newTestMySQLConnection()
+
+			mock.ExpectQuery(mysqlListGrantsStatement).
+				WithArgs("'tenant'@'%'").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_SCHEMA", "PRIVILEGE_TYPE", "IS_GRANTABLE"}).
+					AddRow("archive", "SELECT", "NO").
+					AddRow("tenant", "CREATE", "YES").
+					AddRow("tenant", "DELETE", "YES").
+					AddRow("tenant", "SELECT", "YES"))
+
+			Expect(connection.ListGrants(ctx, "tenant")).To(Equal([]Grant{
+				{Schema: "archive", Privilege: "SELECT"},
+				{Schema: "tenant", Privilege: "CREATE", GrantOption: true},
+				{Schema: "tenant", Privilege: "DELETE", GrantOption: true},
+				{Schema: "tenant", Privilege: "SELECT", GrantOption: true},
+			}))
+		})
+
+		It("should return no grants for a user with no privileges", func() {
+			connection, mock := newTestMySQLConnection()
+
+			mock.ExpectQuery(mysqlListGrantsStatement).
+				WithArgs("'tenant'@'%'").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_SCHEMA", "PRIVILEGE_TYPE", "IS_GRANTABLE"}))
+
+			Expect(connection.ListGrants(ctx, "tenant")).To(BeEmpty())
+		})
+	})
+
+	Describe("checking a single privilege", func() {
+		It("should report the privilege held by the user", func() {
+			connection, mock := newTestMySQLConnection()
+
+			mock.ExpectQuery(mysqlLowerCaseTableNames).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(0))
+			mock.ExpectPrepare(mysqlFetchPrivilegeStatement).ExpectQuery().
+				WithArgs("'tenant'@'%'", "tenant", "CREATE").
+				WillReturnRows(sqlmock.NewRows([]string{"PRIVILEGE_TYPE"}).AddRow("CREATE"))
+
+			Expect(connection.HasPrivilege(ctx, "tenant", "tenant", "create")).To(BeTrue())
+		})
+
+		It("should report the privilege not held by the user", func() {
+			connection, mock := newTestMySQLConnection()
+
+			mock.ExpectQuery(mysqlLowerCaseTableNames).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(0))
+			mock.ExpectPrepare(mysqlFetchPrivilegeStatement).ExpectQuery().
+				WithArgs("'tenant'@'%'", "tenant", "DROP").
+				WillReturnRows(sqlmock.NewRows([]string{"PRIVILEGE_TYPE"}))
+
+			Expect(connection.HasPrivilege(ctx, "tenant", "tenant", "drop")).To(BeFalse())
+		})
+	})
+
+	Describe("creating the user", func() {
+		It("should escape the password", func() {
+			connection, mock := newTestMySQLConnection()
+
+			mock.ExpectQuery(mysqlNoBackslashEscapes).WillReturnRows(sqlmock.NewRows([]string{"mode"}).AddRow(0))
+			mock.ExpectExec("CREATE USER `tenant`@`%` IDENTIFIED BY 'it''s\\\\a''); DROP USER root; --'").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(connection.CreateUser(ctx, "tenant", `it's\a'); DROP USER root; --`)).To(Succeed())
+		})
+	})
+
+	It("should verify the concurrent connections with their own TLS configuration", func() {
+		servers := map[string]*testMySQLTLSServer{
+			"first.mysql.kamaji-system.svc":  newTestMySQLTLSServer(),
+			"second.mysql.kamaji-system.svc": newTestMySQLTLSServer(),
+		}
+		// Interleaving the configurations of the connections, as with the concurrent reconciliations.
+		configs := map[string]*mysql.Config{}
+
+		for serverName, server := range servers {
+			config, err := newMySQLConfig(ConnectionConfig{
+				User:      "root",
+				Password:  "secret",
+				Endpoints: []ConnectionEndpoint{server.Endpoint()},
+				DBName:    "kamaji",
+				TLSConfig: &tls.Config{ServerName: serverName}, //nolint:gosec
+			})
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(mysql.DeregisterTLSConfig, config.TLSConfig)
+
+			configs[serverName] = config
+		}
+
+		var wg sync.WaitGroup
+
+		for _, config := range configs {
+			wg.Add(1)
+
+			go func(config *mysql.Config) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				connector, err := mysql.NewConnector(config)
+				Expect(err).ToNot(HaveOccurred())
+
+				db := sql.OpenDB(connector)
+				defer db.Close()
+
+				Expect(db.PingContext(ctx)).ToNot(Succeed())
+			}(config)
+		}
+
+		wg.Wait()
+
+		for serverName, server := range servers {
+			Expect(server.ServerNames()).To(Equal([]string{serverName}))
+		}
+	})
+
+	It("should label the connections with the program_name connection attribute", func() {
+		config, err := newMySQLConfig(ConnectionConfig{
+			User:            "root",
+			Password:        "secret",
+			Endpoints:       []ConnectionEndpoint{{Host: "mysql.kamaji-system.svc", Port: 3306}},
+			DBName:          "kamaji",
+			TLSConfig:       &tls.Config{}, //nolint:gosec
+			ApplicationName: "kamaji/kamaji-7d9f8b-x2x4q/default/tenant-00",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(mysql.DeregisterTLSConfig, config.TLSConfig)
+
+		Expect(config.ConnectionAttributes).To(Equal("program_name:kamaji/kamaji-7d9f8b-x2x4q/default/tenant-00"))
+	})
+
+	DescribeTable("building the DSN",
+		func(user, password string) {
+			config, err := newMySQLConfig(ConnectionConfig{
+				User:      user,
+				Password:  password,
+				Endpoints: []ConnectionEndpoint{{Host: "mysql.kamaji-system.svc", Port: 3306}},
+				DBName:    "kamaji",
+				TLSConfig: &tls.Config{}, //nolint:gosec
+			})
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(mysql.DeregisterTLSConfig, config.TLSConfig)
+
+			Expect(config.User).To(Equal(user))
+			Expect(config.Passwd).To(Equal(password))
+			Expect(config.Addr).To(Equal("mysql.kamaji-system.svc:3306"))
+			Expect(config.DBName).To(Equal("kamaji"))
+			Expect(config.ConnectionAttributes).To(BeEmpty())
+
+			_, err = mysql.NewConnector(config)
+			Expect(err).ToNot(HaveOccurred())
+		},
+		Entry("with a plain password", "root", "secret"),
+		Entry("with an at sign", "root", "p@ss@word"),
+		Entry("with a colon", "root", "pass:word"),
+		Entry("with a slash", "root", "pass/word"),
+		Entry("with spaces", "root", " pass word "),
+		Entry("with reserved characters in the user too", "us:er", "p@ss:w/ rd?&"),
+	)
+})
@@ -0,0 +1,983 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"bytes"
+	"context"
+	goerrors "errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+	"github.com/clastix/kamaji/pkg/datastore/errors"
+)
+
+// postgresqlIdentifierRegexp matches the unquoted identifiers, such as the tablespace and template ones.
+var postgresqlIdentifierRegexp = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+const (
+	postgresqlFetchDBStatement             = "SELECT FROM pg_database WHERE datname = ?"
+	postgresqlCreateDBStatement            = "CREATE DATABASE %s"
+	postgresqlTryAdvisoryLockStatement     = "SELECT pg_try_advisory_lock(hashtext(?))"
+	postgresqlAdvisoryUnlockStatement      = "SELECT pg_advisory_unlock(hashtext(?))"
+	postgresqlTemplateClause               = " TEMPLATE %s"
+	postgresqlTablespaceClause             = " TABLESPACE %s"
+	postgresqlTablespaceExistsStatement    = "SELECT 1 FROM pg_tablespace WHERE spcname = ?"
+	postgresqlUserExists                   = "SELECT 1 FROM pg_roles WHERE rolname = ?"
+	postgresqlUserHasLogin                 = "SELECT 1 FROM pg_roles WHERE rolcanlogin AND rolname = ?"
+	postgresqlCreateUserStatement          = "CREATE ROLE %s LOGIN PASSWORD ?"
+	postgresqlShowGrantsStatement          = "SELECT has_database_privilege(rolname, ?, 'create') from pg_roles where rolcanlogin and rolname = ?"
+	postgresqlHasPrivilegeStatement        = "SELECT has_database_privilege(rolname, ?, ?) from pg_roles where rolname = ?"
+	postgresqlShowOwnershipStatement       = "SELECT 't' FROM pg_catalog.pg_database AS d WHERE d.datname = ? AND pg_catalog.pg_get_userbyid(d.datdba) = ?"
+	postgresqlShowTableOwnershipStatement  = "SELECT 't' from pg_tables where tableowner = ? AND tablename = ?"
+	postgresqlKineTableExistsStatement     = "SELECT 't' FROM pg_tables WHERE schemaname = ? AND tablename  = ?"
+	postgresqlCurrentUserStatement         = "SELECT CURRENT_USER"
+	postgresqlHealthcheckStatement         = "SELECT rolsuper OR rolcreatedb, rolsuper OR rolcreaterole FROM pg_roles WHERE rolname = CURRENT_USER"
+	postgresqlDatabaseEncodingStatement    = "SELECT pg_encoding_to_char(encoding), datcollate FROM pg_database WHERE datname = ?"
+	postgresqlDatastoreSizeStatement       = "SELECT COALESCE(SUM(pg_database_size(datname)), 0) FROM pg_database"
+	postgresqlGrantPrivilegesStatement     = "GRANT ALL PRIVILEGES ON DATABASE %s TO %s"
+	postgresqlGrantOptionClause            = " WITH GRANT OPTION"
+	postgresqlChangeOwnerStatement         = "ALTER DATABASE %s OWNER TO %s"
+	postgresqlRevokePrivilegesStatement    = "REVOKE ALL PRIVILEGES ON DATABASE %s FROM %s"
+	postgresqlCommentDBStatement           = "COMMENT ON DATABASE %s IS ?"
+	postgresqlCommentRoleStatement         = "COMMENT ON ROLE %s IS ?"
+	postgresqlFetchDBOwnershipStatement    = "SELECT COALESCE(shobj_description(oid, 'pg_database'), ''), pg_get_userbyid(datdba) FROM pg_database WHERE datname = ?"
+	postgresqlDropRoleStatement            = "DROP ROLE %s"
+	postgresqlDisableRoleStatement         = "ALTER ROLE %s NOLOGIN"
+	postgresqlSetPasswordStatement         = "ALTER ROLE %s LOGIN PASSWORD ?"
+	postgresqlValidUntilStatement          = "ALTER ROLE %s VALID UNTIL ?"
+	postgresqlTerminateSessionsStatement   = "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE usename = ?"
+	postgresqlDropDBStatement              = "DROP DATABASE %s WITH (FORCE)"
+	postgresqlRenameDBStatement            = "ALTER DATABASE %s RENAME TO %s"
+	postgresqlTerminateDBSessionsStatement = "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = ? AND pid <> pg_backend_pid()"
+	postgresqlStatementTimeoutStatement    = "SET statement_timeout = %d"
+	postgresqlCreateExtensionStatement     = "CREATE EXTENSION IF NOT EXISTS \"%s\""
+	postgresqlSetSearchPathStatement       = "ALTER ROLE %s IN DATABASE %s SET search_path TO %s"
+	postgresqlGrantTablesStatement         = "GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA %s TO %s"
+	postgresqlGrantSequencesStatement      = "GRANT ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA %s TO %s"
+	postgresqlGrantDefaultTablesStatement  = "ALTER DEFAULT PRIVILEGES IN SCHEMA %s GRANT ALL PRIVILEGES ON TABLES TO %s"
+	postgresqlGrantDefaultSeqStatement     = "ALTER DEFAULT PRIVILEGES IN SCHEMA %s GRANT ALL PRIVILEGES ON SEQUENCES TO %s"
+	postgresqlRevokeDefaultTablesStatement = "ALTER DEFAULT PRIVILEGES IN SCHEMA %s REVOKE ALL PRIVILEGES ON TABLES FROM %s"
+	postgresqlRevokeDefaultSeqStatement    = "ALTER DEFAULT PRIVILEGES IN SCHEMA %s REVOKE ALL PRIVILEGES ON SEQUENCES FROM %s"
+	// postgresqlGrantedPrivilegesStatement checks each database privilege, taking into account the ownership and the roles membership.
+	postgresqlGrantedPrivilegesStatement = "SELECT p FROM pg_roles, unnest(ARRAY['CONNECT', 'CREATE', 'TEMPORARY']) AS p " +
+		"WHERE rolname = ? AND has_database_privilege(rolname, ?, p) ORDER BY p"
+	// postgresqlListGrantsStatement returns the database privileges granted to the given role, including the implicit
+	// ones of the owner, as recorded by the database ACLs: the privileges inherited by the roles membership are skipped.
+	postgresqlListGrantsStatement = "SELECT d.datname AS schema, a.privilege_type AS privilege, a.is_grantable AS grant_option " +
+		"FROM pg_database AS d CROSS JOIN LATERAL aclexplode(COALESCE(d.datacl, acldefault('d', d.datdba))) AS a " +
+		"JOIN pg_roles AS g ON g.oid = a.grantee WHERE g.rolname = ? ORDER BY d.datname, a.privilege_type"
+	// postgresqlShowDefaultPrivilegesStatement checks the default privileges granted by the current user to the given one
+	// on the tables created in the given schema.
+	postgresqlShowDefaultPrivilegesStatement = "SELECT 't' FROM pg_default_acl AS d JOIN pg_namespace AS n ON n.oid = d.defaclnamespace " +
+		"WHERE n.nspname = ? AND d.defaclobjtype = 'r' AND d.defaclrole = (SELECT oid FROM pg_roles WHERE rolname = CURRENT_USER) " +
+		"AND EXISTS (SELECT 1 FROM aclexplode(d.defaclacl) AS a JOIN pg_roles AS g ON g.oid = a.grantee WHERE g.rolname = ?)"
+	// postgresqlFetchDropStatements returns the statements dropping the relations of the given schema, such as tables,
+	// views, and sequences, skipping the ones belonging to the extensions, which are dropped along with them.
+	postgresqlFetchDropStatements = "SELECT format('DROP %s IF EXISTS %I.%I CASCADE', CASE c.relkind WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATERIALIZED VIEW' " +
+		"WHEN 'S' THEN 'SEQUENCE' WHEN 'f' THEN 'FOREIGN TABLE' ELSE 'TABLE' END, n.nspname, c.relname) " +
+		"FROM pg_class AS c JOIN pg_namespace AS n ON n.oid = c.relnamespace WHERE n.nspname = ? AND c.relkind IN ('r', 'p', 'v', 'm', 'S', 'f') " +
+		"AND NOT EXISTS (SELECT 1 FROM pg_depend AS d WHERE d.classid = 'pg_class'::regclass AND d.objid = c.oid AND d.deptype = 'e') ORDER BY c.relkind DESC"
+	// postgresqlDefaultTemplate is the template database copied by CREATE DATABASE when none is given,
+	// determining the encoding and the collation of the new databases.
+	postgresqlDefaultTemplate = "template1"
+	// postgresqlTenantSchema is the schema of the tenant database the kine tables are created in.
+	postgresqlTenantSchema = "public"
+	// postgresqlQueryCanceledCode is the SQLSTATE returned when a statement has been canceled due to statement_timeout.
+	postgresqlQueryCanceledCode = "57014"
+	// postgresqlInvalidCatalogNameCode is the SQLSTATE returned when connecting to a non-existing database.
+	postgresqlInvalidCatalogNameCode = "3D000"
+	// postgresqlDuplicateObjectCode is the SQLSTATE returned when the role already exists.
+	postgresqlDuplicateObjectCode = "42710"
+	// postgresqlDuplicateDatabaseCode is the SQLSTATE returned when the database already exists.
+	postgresqlDuplicateDatabaseCode = "42P04"
+	// postgresqlUniqueViolationCode is the SQLSTATE returned by the concurrent creation of the same database,
+	// since the pg_database catalog unique index is violated.
+	postgresqlUniqueViolationCode = "23505"
+	// postgresqlInsufficientPrivilegeCode is the SQLSTATE returned when the user lacks the permission for the statement.
+	postgresqlInsufficientPrivilegeCode = "42501"
+	// postgresqlUndefinedFileCode is the SQLSTATE returned when the extension is not available on the server.
+	postgresqlUndefinedFileCode = "58P01"
+)
+
+type PostgreSQLConnection struct {
+	db *pg.DB
+	// directDB bypasses the connection pooler for the database level statements, such as CREATE DATABASE:
+	// it's the same as db when no direct endpoints are specified.
+	directDB *pg.DB
+	// tx is the transaction the statements against db are performed in, set only for the Connection
+	// provided by Transaction.
+	tx               *pg.Tx
+	connection       ConnectionEndpoint
+	switchDatabaseFn func(dbName string) *pg.DB
+	// dbName is the maintenance database, empty when relying on the default one.
+	dbName string
+}
+
+func (r *PostgreSQLConnection) Migrate(ctx context.Context, tcp kamajiv1alpha1.TenantControlPlane, target Connection) error {
+	// Ensuring the connection is working as expected
+	if err := target.Check(ctx); err != nil {
+		return fmt.Errorf("unable to check target datastore: %w", err)
+	}
+	// Creating the target schema if it doesn't exist
+	if ok, _ := target.DBExists(ctx, tcp.Status.Storage.Setup.Schema); !ok {
+		if err := target.CreateDB(ctx, tcp.Status.Storage.Setup.Schema); err != nil {
+			return err
+		}
+	}
+
+	targetConn := target.(*PostgreSQLConnection).switchDatabaseFn(postgresqlIdentifier(tcp.Status.Storage.Setup.Schema)) //nolint:forcetypeassert
+	defer targetConn.Close()
+
+	originConn := r.switchDatabaseFn(postgresqlIdentifier(tcp.Status.Storage.Setup.Schema))
+	defer originConn.Close()
+
+	err := targetConn.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		for _, stm := range []string{
+			`CREATE TABLE IF NOT EXISTS kine (
+				id SERIAL PRIMARY KEY,
+				name VARCHAR(630),
+				created INTEGER,
+				deleted INTEGER,
+				create_revision INTEGER,
+				prev_revision INTEGER,
+				lease INTEGER,
+				value bytea,
+				old_value bytea
+			)`,
+			`TRUNCATE TABLE kine`,
+			`CREATE INDEX IF NOT EXISTS kine_name_index ON kine (name)`,
+			`CREATE INDEX IF NOT EXISTS kine_name_id_index ON kine (name,id)`,
+			`CREATE INDEX IF NOT EXISTS kine_id_deleted_index ON kine (id,deleted)`,
+			`CREATE INDEX IF NOT EXISTS kine_prev_revision_index ON kine (prev_revision)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS kine_name_prev_revision_uindex ON kine (name, prev_revision)`,
+		} {
+			_, err := tx.ExecContext(ctx, stm)
+			audit(ctx, r.Driver(), stm, err)
+
+			if err != nil {
+				return fmt.Errorf("unable to perform schema creation: %w", err)
+			}
+		}
+		// Dumping the old datastore in a local buffer
+		var buf bytes.Buffer
+
+		if _, err := originConn.WithContext(ctx).CopyTo(&buf, "COPY kine TO STDOUT"); err != nil { //nolint:contextcheck
+			return fmt.Errorf("unable to copy from the origin datastore: %w", err)
+		}
+
+		if _, err := tx.CopyFrom(&buf, "COPY kine FROM STDIN"); err != nil {
+			return fmt.Errorf("unable to copy to the target datastore: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to perform migration transaction: %w", err)
+	}
+
+	return nil
+}
+
+func NewPostgreSQLConnection(config ConnectionConfig) (Connection, error) {
+	opt := &pg.Options{
+		Addr:         config.Endpoints[0].String(),
+		Database:     config.DBName,
+		User:         config.User,
+		Password:     config.Password,
+		TLSConfig:    config.TLSConfig,
+		WriteTimeout: config.WriteTimeout,
+		// The application_name is reported by pg_stat_activity.
+		ApplicationName: config.ApplicationName,
+	}
+
+	if config.Dialer != nil {
+		opt.Dialer = config.Dialer.DialContext
+	}
+	// PostgreSQL doesn't negotiate TLS over the Unix domain sockets.
+	if len(config.UnixSocket) > 0 {
+		opt.Network, opt.Addr, opt.TLSConfig, opt.Dialer = "unix", config.UnixSocket, nil, nil
+	}
+
+	if config.ReadTimeout > 0 {
+		opt.OnConnect = func(ctx context.Context, cn *pg.Conn) error {
+			_, err := cn.ExecContext(ctx, fmt.Sprintf(postgresqlStatementTimeoutStatement, config.ReadTimeout.Milliseconds()))
+
+			return err
+		}
+	}
+
+	// The options are copied since retained by the connection pool, which must keep targeting the maintenance database.
+	fn := func(dbName string) *pg.DB {
+		o := *opt
+		o.Database = dbName
+
+		switched := pg.Connect(&o)
+		switched.AddQueryHook(postgresqlStatementLogger{})
+
+		return switched
+	}
+
+	db := pg.Connect(opt)
+	db.AddQueryHook(postgresqlStatementLogger{})
+
+	directDB := db
+	if len(config.DirectEndpoints) > 0 && len(config.UnixSocket) == 0 {
+		directOpt := *opt
+		directOpt.Addr = config.DirectEndpoints[0].String()
+
+		directDB = pg.Connect(&directOpt)
+		directDB.AddQueryHook(postgresqlStatementLogger{})
+	}
+
+	return &PostgreSQLConnection{
+		db:               db,
+		dbName:           config.DBName,
+		directDB:         directDB,
+		switchDatabaseFn: fn,
+		connection:       config.Endpoints[0],
+	}, nil
+}
+
+func (r *PostgreSQLConnection) Driver() string {
+	return string(kamajiv1alpha1.KinePostgreSQLDriver)
+}
+
+func (r *PostgreSQLConnection) Capabilities() Capabilities {
+	return Capabilities{
+		Transactions:   true,
+		Passwords:      true,
+		GrantOption:    true,
+		Extensions:     true,
+		SearchPath:     true,
+		RenameDatabase: true,
+		Tablespaces:    true,
+		CloneDatabase:  true,
+		Exec:           true,
+		Encoding:       true,
+	}
+}
+
+func (r *PostgreSQLConnection) UserExists(ctx context.Context, user string) (bool, error) {
+	user = postgresqlIdentifier(user)
+
+	res, err := r.db.ExecContext(ctx, postgresqlUserExists, user)
+	if err != nil {
+		return false, errors.NewCheckUserExistsError(postgresqlStatementTimeout(err))
+	}
+
+	return res.RowsReturned() > 0, nil
+}
+
+// UserHasLogin checks the LOGIN attribute of the user, removed upon the RevokeAllAndDisableUser function.
+func (r *PostgreSQLConnection) UserHasLogin(ctx context.Context, user string) (bool, error) {
+	user = postgresqlIdentifier(user)
+
+	res, err := r.db.ExecContext(ctx, postgresqlUserHasLogin, user)
+	if err != nil {
+		return false, errors.NewCheckUserLoginError(postgresqlStatementTimeout(err))
+	}
+
+	return res.RowsReturned() > 0, nil
+}
+
+func (r *PostgreSQLConnection) CreateUser(ctx context.Context, user, password string) error {
+	user = postgresqlIdentifier(user)
+
+	_, err := r.exec(ctx, r.db, fmt.Sprintf(postgresqlCreateUserStatement, user), password)
+	if err != nil {
+		if postgresqlErrorHasCode(err, postgresqlDuplicateObjectCode) {
+			return errors.NewUserAlreadyExistsError(err)
+		}
+
+		return errors.NewCreateUserError(postgresqlStatementTimeout(err))
+	}
+
+	return nil
+}
+
+func (r *PostgreSQLConnection) SetUserPassword(ctx context.Context, user, password string) error {
+	user = postgresqlIdentifier(user)
+
+	if _, err := r.exec(ctx, r.db, fmt.Sprintf(postgresqlSetPasswordStatement, user), password); err != nil {
+		return errors.NewSetUserPasswordError(postgresqlStatementTimeout(err))
+	}
+
+	return nil
+}
+
+// SetUserPasswordExpiry sets the VALID UNTIL attribute of the user, which is not affected by the password changes.
+func (r *PostgreSQLConnection) SetUserPasswordExpiry(ctx context.Context, user string, expiry time.Duration) error {
+	user = postgresqlIdentifier(user)
+
+	validUntil := "infinity"
+	if expiry > 0 {
+		validUntil = time.Now().Add(expiry).UTC().Format(time.RFC3339)
+	}
+
+	if _, err := r.exec(ctx, r.db, fmt.Sprintf(postgresqlValidUntilStatement, user), validUntil); err != nil {
+		return errors.NewSetUserPasswordExpiryError(postgresqlStatementTimeout(err))
+	}
+
+	return nil
+}
+
+func (r *PostgreSQLConnection) DBExists(ctx context.Context, dbName string) (bool, error) {
+	dbName = postgresqlIdentifier(dbName)
+
+	rows, err := r.db.ExecContext(ctx, postgresqlFetchDBStatement, dbName)
+	if err != nil {
+		if postgresqlErrorHasCode(err, postgresqlInvalidCatalogNameCode) {
+			return false, errors.NewCheckDatabaseExistError(r.missingMaintenanceDatabase(err))
+		}
+
+		return false, errors.NewCheckDatabaseExistError(postgresqlStatementTimeout(err))
+	}
+
+	return rows.RowsReturned() > 0, nil
+}
+
+// DBOverlaps always returns false since PostgreSQL databases are isolated namespaces.
+func (r *PostgreSQLConnection) DBOverlaps(context.Context, string) (bool, error) {
+	return false, nil
+}
+
+func (r *PostgreSQLConnection) CreateDB(ctx context.Context, dbName string) error {
+	return r.CreateDBWithOptions(ctx, dbName, CreateDBOptions{})
+}
+
+func (r *PostgreSQLConnection) CreateDBWithOptions(ctx context.Context, dbName string, opts CreateDBOptions) error {
+	return r.createDB(ctx, dbName, "", opts)
+}
+
+// CloneDB terminates the sessions connected to the template database, since PostgreSQL refuses to copy it otherwise:
+// the copied objects keep their owner, while the tenant user is granted the privileges on them as for its own ones.
+func (r *PostgreSQLConnection) CloneDB(ctx context.Context, dbName, template string, opts CreateDBOptions) error {
+	template = postgresqlIdentifier(template)
+
+	if !postgresqlIdentifierRegexp.MatchString(template) {
+		return errors.NewCloneDBError(errors.NewInvalidConfigError(fmt.Errorf("the template %q is not a valid identifier", template)))
+	}
+
+	if _, err := r.exec(ctx, r.directDB, postgresqlTerminateDBSessionsStatement, template); err != nil {
+		return errors.NewCloneDBError(postgresqlStatementTimeout(err))
+	}
+
+	return r.createDB(ctx, dbName, template, opts)
+}
+
+// createDB creates the database, copying the given template one when not empty.
+func (r *PostgreSQLConnection) createDB(ctx context.Context, dbName, template string, opts CreateDBOptions) error {
+	dbName = postgresqlIdentifier(dbName)
+
+	statement := fmt.Sprintf(postgresqlCreateDBStatement, dbName)
+
+	if len(template) > 0 {
+		statement += fmt.Sprintf(postgresqlTemplateClause, template)
+	}
+
+	if len(opts.Tablespace) > 0 {
+		tablespace := postgresqlIdentifier(opts.Tablespace)
+		// The tablespace can be provided by the Tenant Control Plane annotation, thus it's validated being interpolated.
+		if !postgresqlIdentifierRegexp.MatchString(tablespace) {
+			return errors.NewCreateDBError(errors.NewInvalidConfigError(fmt.Errorf("the tablespace %q is not a valid identifier", opts.Tablespace)))
+		}
+		// Validating the tablespace upfront, reporting its absence rather than the generic statement failure.
+		res, err := r.db.ExecContext(ctx, postgresqlTablespaceExistsStatement, tablespace)
+		if err != nil {
+			return errors.NewCreateDBError(postgresqlStatementTimeout(err))
+		}
+
+		if res.RowsReturned() == 0 {
+			return errors.NewCreateDBError(fmt.Errorf("the tablespace %s does not exist", tablespace))
+		}
+
+		statement += fmt.Sprintf(postgresqlTablespaceClause, tablespace)
+	}
+	// PostgreSQL doesn't support CREATE DATABASE IF NOT EXISTS, neither in a DO block since it can't be executed
+	// in a transaction: the creation performed concurrently by overlapping reconciliations is not considered a failure.
+	_, err := r.exec(ctx, r.directDB, statement)
+	if err != nil && !postgresqlErrorHasCode(err, postgresqlDuplicateDatabaseCode, postgresqlUniqueViolationCode) {
+		return errors.NewCreateDBError(postgresqlStatementTimeout(err))
+	}
+
+	return nil
+}
+
+func (r *PostgreSQLConnection) GrantPrivilegesExists(ctx context.Context, user, dbName string) (bool, error) {
+	user, dbName = postgresqlIdentifier(user), postgresqlIdentifier(dbName)
+
+	var hasDatabasePrivilege string
+
+	_, err := r.db.QueryContext(ctx, pg.Scan(&hasDatabasePrivilege), postgresqlShowGrantsStatement, dbName, user)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return false, nil
+		}
+
+		return false, errors.NewCheckGrantExistsError(postgresqlStatementTimeout(err))
+	}
+
+	var isOwner string
+
+	if _, err = r.db.QueryContext(ctx, pg.Scan(&isOwner), postgresqlShowOwnershipStatement, dbName, user); err != nil {
+		return false, errors.NewCheckGrantExistsError(postgresqlStatementTimeout(err))
+	}
+
+	var isTableOwner string
+
+	dbConn := r.switchDatabaseFn(dbName)
+	defer dbConn.Close()
+
+	tableExists, err := r.kineTableExists(ctx, dbConn)
+	if err != nil {
+		return false, errors.NewGrantPrivilegesError(postgresqlStatementTimeout(err))
+	}
+
+	if tableExists {
+		if _, err = dbConn.QueryContext(ctx, pg.Scan(&isTableOwner), postgresqlShowTableOwnershipStatement, user, "kine"); err != nil {
+			return false, errors.NewCheckGrantExistsError(postgresqlStatementTimeout(err))
+		}
+
+		if isTableOwner != "t" {
+			return false, nil
+		}
+	}
+	// The tables created later by the roles other than the user, such as by Kamaji, must be accessible too.
+	var hasDefaultPrivileges string
+
+	if _, err = dbConn.QueryContext(ctx, pg.Scan(&hasDefaultPrivileges), postgresqlShowDefaultPrivilegesStatement, postgresqlTenantSchema, user); err != nil {
+		return false, errors.NewCheckGrantExistsError(postgresqlStatementTimeout(err))
+	}
+
+	return hasDatabasePrivilege == "t" && isOwner == "t" && hasDefaultPrivileges == "t", nil
+}
+
+// GrantPrivilegesWithOptionsExists is equivalent to GrantPrivilegesExists, since the user owning the database
+// is implicitly holding the grant option on it.
+func (r *PostgreSQLConnection) GrantPrivilegesWithOptionsExists(ctx context.Context, user, dbName string, _ GrantOptions) (bool, error) {
+	return r.GrantPrivilegesExists(ctx, user, dbName)
+}
+
+func (r *PostgreSQLConnection) HasPrivilege(ctx context.Context, user, dbName, privilege string) (bool, error) {
+	user, dbName = postgresqlIdentifier(user), postgresqlIdentifier(dbName)
+
+	var hasPrivilege string
+
+	res, err := r.db.QueryContext(ctx, pg.Scan(&hasPrivilege), postgresqlHasPrivilegeStatement, dbName, privilege, user)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return false, nil
+		}
+
+		return false, errors.NewCheckPrivilegeError(postgresqlStatementTimeout(err))
+	}
+
+	return res.RowsReturned() > 0 && hasPrivilege == "t", nil
+}
+
+func (r *PostgreSQLConnection) GrantedPrivileges(ctx context.Context, user, dbName string) ([]string, error) {
+	user, dbName = postgresqlIdentifier(user), postgresqlIdentifier(dbName)
+
+	var privileges []string
+
+	if _, err := r.db.QueryContext(ctx, &privileges, postgresqlGrantedPrivilegesStatement, user, dbName); err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return nil, nil
+		}
+
+		return nil, errors.NewGrantedPrivilegesError(postgresqlStatementTimeout(err))
+	}
+
+	return privileges, nil
+}
+
+func (r *PostgreSQLConnection) ListGrants(ctx context.Context, user string) ([]Grant, error) {
+	var grants []Grant
+
+	if _, err := r.db.QueryContext(ctx, &grants, postgresqlListGrantsStatement, postgresqlIdentifier(user)); err != nil {
+		return nil, errors.NewListGrantsError(postgresqlStatementTimeout(err))
+	}
+
+	return grants, nil
+}
+
+func (r *PostgreSQLConnection) GrantPrivileges(ctx context.Context, user, dbName string) error {
+	return r.GrantPrivilegesWithOptions(ctx, user, dbName, GrantOptions{})
+}
+
+// GrantPrivilegesWithOptions grants the privileges, along with the grant option when requested: since the user owns
+// the database, it's implicitly holding the grant option on it, thus it's not revoked when not requested.
+func (r *PostgreSQLConnection) GrantPrivilegesWithOptions(ctx context.Context, user, dbName string, opts GrantOptions) error {
+	user, dbName = postgresqlIdentifier(user), postgresqlIdentifier(dbName)
+
+	statement := postgresqlGrantPrivilegesStatement
+	if opts.WithGrantOption {
+		statement += postgresqlGrantOptionClause
+	}
+
+	if _, err := r.exec(ctx, r.db, fmt.Sprintf(statement, dbName, user)); err != nil {
+		return errors.NewGrantPrivilegesError(postgresqlStatementTimeout(err))
+	}
+
+	if _, err := r.exec(ctx, r.directDB, fmt.Sprintf(postgresqlChangeOwnerStatement, dbName, user)); err != nil {
+		return errors.NewGrantPrivilegesError(postgresqlStatementTimeout(err))
+	}
+	// The kine table belongs to the tenant database, thus its ownership can't be changed in the same transaction:
+	// it's up to the caller to grant the privileges again, once the transaction is committed.
+	if r.tx != nil {
+		return nil
+	}
+
+	dbConn := r.switchDatabaseFn(dbName)
+	defer dbConn.Close()
+
+	tableExists, err := r.kineTableExists(ctx, dbConn)
+	if err != nil {
+		return errors.NewGrantPrivilegesError(postgresqlStatementTimeout(err))
+	}
+
+	if tableExists {
+		if _, err = r.exec(ctx, dbConn, fmt.Sprintf("ALTER TABLE kine OWNER TO %s", user)); err != nil {
+			return errors.NewGrantPrivilegesError(postgresqlStatementTimeout(err))
+		}
+	}
+	// Granting the privileges on the existing tables of the schema, and on the ones created later,
+	// since the database privileges don't apply to them.
+	for _, statement := range []string{postgresqlGrantTablesStatement, postgresqlGrantSequencesStatement, postgresqlGrantDefaultTablesStatement, postgresqlGrantDefaultSeqStatement} {
+		if _, err = r.exec(ctx, dbConn, fmt.Sprintf(statement, postgresqlTenantSchema, user)); err != nil {
+			return errors.NewGrantPrivilegesError(postgresqlStatementTimeout(err))
+		}
+	}
+
+	return nil
+}
+
+// DatabaseExistsForUser checks the tenant ownership stored in the database comment,
+// falling back to the database owner for the databases not annotated yet.
+func (r *PostgreSQLConnection) DatabaseExistsForUser(ctx context.Context, user, dbName, tenant string) (bool, error) {
+	user, dbName = postgresqlIdentifier(user), postgresqlIdentifier(dbName)
+
+	var comment, owner string
+
+	if _, err := r.db.QueryOneContext(ctx, pg.Scan(&comment, &owner), postgresqlFetchDBOwnershipStatement, dbName); err != nil {
+		if goerrors.Is(err, pg.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, errors.NewCheckDatabaseOwnershipError(postgresqlStatementTimeout(err))
+	}
+
+	if len(comment) > 0 {
+		return comment == tenant, nil
+	}
+
+	return owner == user, nil
+}
+
+// EnsureExtensions creates the given extensions in the database, reporting the missing permissions
+// and the extensions not available on the server.
+func (r *PostgreSQLConnection) EnsureExtensions(ctx context.Context, dbName string, extensions []string) error {
+	if len(extensions) == 0 {
+		return nil
+	}
+
+	dbConn := r.switchDatabaseFn(postgresqlIdentifier(dbName))
+	defer dbConn.Close()
+
+	for _, extension := range extensions {
+		_, err := r.exec(ctx, dbConn, fmt.Sprintf(postgresqlCreateExtensionStatement, extension))
+
+		switch {
+		case err == nil:
+			continue
+		case postgresqlErrorHasCode(err, postgresqlInsufficientPrivilegeCode):
+			return errors.NewEnsureExtensionsError(fmt.Errorf("the DataStore user lacks the permission to create the %s extension, requiring the superuser role, or the CREATE privilege on the database for the trusted ones: %w", extension, err))
+		case postgresqlErrorHasCode(err, postgresqlUndefinedFileCode):
+			return errors.NewEnsureExtensionsError(fmt.Errorf("the %s extension is not available on the DataStore server: %w", extension, err))
+		default:
+			return errors.NewEnsureExtensionsError(postgresqlStatementTimeout(err))
+		}
+	}
+
+	return nil
+}
+
+// SetUserSearchPath sets the search_path of the user when connected to the given database,
+// overriding the one set for the role or the database by the DataStore administrators.
+func (r *PostgreSQLConnection) SetUserSearchPath(ctx context.Context, user, dbName string, searchPath []string) error {
+	user, dbName = postgresqlIdentifier(user), postgresqlIdentifier(dbName)
+
+	schemas := make([]string, 0, len(searchPath))
+	for _, schema := range searchPath {
+		schemas = append(schemas, postgresqlIdentifier(schema))
+	}
+
+	if _, err := r.exec(ctx, r.db, fmt.Sprintf(postgresqlSetSearchPathStatement, user, dbName, strings.Join(schemas, ", "))); err != nil {
+		return errors.NewSetUserSearchPathError(postgresqlStatementTimeout(err))
+	}
+
+	return nil
+}
+
+func (r *PostgreSQLConnection) Annotate(ctx context.Context, user, dbName, tenant string) error {
+	user, dbName = postgresqlIdentifier(user), postgresqlIdentifier(dbName)
+
+	if _, err := r.exec(ctx, r.db, fmt.Sprintf(postgresqlCommentDBStatement, dbName), tenant); err != nil {
+		return errors.NewAnnotateError(postgresqlStatementTimeout(err))
+	}
+
+	if _, err := r.exec(ctx, r.db, fmt.Sprintf(postgresqlCommentRoleStatement, user), tenant); err != nil {
+		return errors.NewAnnotateError(postgresqlStatementTimeout(err))
+	}
+
+	return nil
+}
+
+// EndpointsHealth returns no endpoints, since the PostgreSQL driver reports no per-endpoint health.
+func (r *PostgreSQLConnection) EndpointsHealth(context.Context) ([]EndpointHealth, error) {
+	return nil, nil
+}
+
+func (r *PostgreSQLConnection) DatabaseEncoding(ctx context.Context, dbName string) (Encoding, error) {
+	var encoding Encoding
+
+	if _, err := r.db.QueryOneContext(ctx, pg.Scan(&encoding.Charset, &encoding.Collation), postgresqlDatabaseEncodingStatement, postgresqlIdentifier(dbName)); err != nil {
+		return Encoding{}, errors.NewDatabaseEncodingError(postgresqlStatementTimeout(err))
+	}
+
+	return encoding, nil
+}
+
+// DefaultEncoding returns the encoding and the collation of the default template database,
+// which are copied to the databases created with no template.
+func (r *PostgreSQLConnection) DefaultEncoding(ctx context.Context) (Encoding, error) {
+	return r.DatabaseEncoding(ctx, postgresqlDefaultTemplate)
+}
+
+func (r *PostgreSQLConnection) CurrentUser(ctx context.Context) (string, error) {
+	var user string
+
+	if _, err := r.db.QueryOneContext(ctx, pg.Scan(&user), postgresqlCurrentUserStatement); err != nil {
+		return "", errors.NewCurrentUserError(postgresqlStatementTimeout(err))
+	}
+
+	return user, nil
+}
+
+func (r *PostgreSQLConnection) DatastoreSize(ctx context.Context) (int64, error) {
+	var size int64
+
+	if _, err := r.db.QueryOneContext(ctx, pg.Scan(&size), postgresqlDatastoreSizeStatement); err != nil {
+		return 0, errors.NewDatastoreSizeError(postgresqlStatementTimeout(err))
+	}
+
+	return size, nil
+}
+
+// Transaction executes the given function in a single transaction against the server database, rolling back
+// the performed statements on failure. Since a transaction can't span multiple connections, the function is executed
+// step-wise when the direct endpoints are specified, or when already in a transaction.
+func (r *PostgreSQLConnection) Transaction(ctx context.Context, fn func(ctx context.Context, tx Connection) error) error {
+	if r.tx != nil || r.directDB != r.db {
+		return fn(ctx, r)
+	}
+
+	return r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		txConnection := *r
+		txConnection.tx = tx
+
+		return fn(ctx, &txConnection)
+	})
+}
+
+func (r *PostgreSQLConnection) DeleteUser(ctx context.Context, user string) error {
+	user = postgresqlIdentifier(user)
+
+	if _, err := r.exec(ctx, r.db, fmt.Sprintf(postgresqlDropRoleStatement, user)); err != nil {
+		return errors.NewDeleteUserError(postgresqlStatementTimeout(err))
+	}
+
+	return nil
+}
+
+func (r *PostgreSQLConnection) DeleteDB(ctx context.Context, dbName string) error {
+	dbName = postgresqlIdentifier(dbName)
+
+	if _, err := r.exec(ctx, r.directDB, fmt.Sprintf(postgresqlDropDBStatement, dbName)); err != nil {
+		return errors.NewCannotDeleteDatabaseError(postgresqlStatementTimeout(err))
+	}
+
+	return nil
+}
+
+// RenameDatabase terminates the sessions connected to the database, such as the kine ones, since PostgreSQL
+// refuses to rename it otherwise: the privileges are preserved, being bound to the database rather than its name.
+func (r *PostgreSQLConnection) RenameDatabase(ctx context.Context, oldName, newName string) error {
+	oldName, newName = postgresqlIdentifier(oldName), postgresqlIdentifier(newName)
+
+	if _, err := r.exec(ctx, r.directDB, postgresqlTerminateDBSessionsStatement, oldName); err != nil {
+		return errors.NewRenameDatabaseError(postgresqlStatementTimeout(err))
+	}
+
+	if _, err := r.exec(ctx, r.directDB, fmt.Sprintf(postgresqlRenameDBStatement, oldName, newName)); err != nil {
+		return errors.NewRenameDatabaseError(postgresqlStatementTimeout(err))
+	}
+
+	return nil
+}
+
+func (r *PostgreSQLConnection) RevokePrivileges(ctx context.Context, user, dbName string) error {
+	user, dbName = postgresqlIdentifier(user), postgresqlIdentifier(dbName)
+
+	if _, err := r.exec(ctx, r.db, fmt.Sprintf(postgresqlRevokePrivilegesStatement, dbName, user)); err != nil {
+		return errors.NewRevokePrivilegesError(postgresqlStatementTimeout(err))
+	}
+	// The default privileges are stored in the tenant database, thus they can't be revoked in the same transaction:
+	// it's up to the caller to revoke them, once the transaction is committed.
+	if r.tx != nil {
+		return nil
+	}
+
+	return r.revokeDefaultPrivileges(ctx, user, dbName)
+}
+
+// revokeDefaultPrivileges revokes the privileges granted on the objects created later in the tenant schema.
+func (r *PostgreSQLConnection) revokeDefaultPrivileges(ctx context.Context, user, dbName string) error {
+	dbConn := r.switchDatabaseFn(dbName)
+	defer dbConn.Close()
+
+	for _, statement := range []string{postgresqlRevokeDefaultTablesStatement, postgresqlRevokeDefaultSeqStatement} {
+		if _, err := r.exec(ctx, dbConn, fmt.Sprintf(statement, postgresqlTenantSchema, user)); err != nil {
+			// Nothing to revoke, since the database has been already deleted.
+			if postgresqlErrorHasCode(err, postgresqlInvalidCatalogNameCode) {
+				return nil
+			}
+
+			return errors.NewRevokePrivilegesError(postgresqlStatementTimeout(err))
+		}
+	}
+
+	return nil
+}
+
+// RevokeAllAndDisableUser revokes the privileges and disables the login of the user in a single transaction,
+// terminating its established sessions afterwards, since the NOLOGIN attribute is enforced only upon new connections.
+// The default privileges of the tenant database are revoked once committed, since they can't be part of it.
+func (r *PostgreSQLConnection) RevokeAllAndDisableUser(ctx context.Context, user, dbName string) error {
+	user, dbName = postgresqlIdentifier(user), postgresqlIdentifier(dbName)
+
+	err := r.Transaction(ctx, func(ctx context.Context, tx Connection) error {
+		if err := tx.RevokePrivileges(ctx, user, dbName); err != nil {
+			return err
+		}
+
+		if _, err := tx.(*PostgreSQLConnection).exec(ctx, r.db, fmt.Sprintf(postgresqlDisableRoleStatement, user)); err != nil { //nolint:forcetypeassert
+			return errors.NewDisableUserError(postgresqlStatementTimeout(err))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err = r.exec(ctx, r.db, postgresqlTerminateSessionsStatement, user); err != nil {
+		return errors.NewDisableUserError(postgresqlStatementTimeout(err))
+	}
+
+	return r.revokeDefaultPrivileges(ctx, user, dbName)
+}
+
+func (r *PostgreSQLConnection) GetConnectionString() string {
+	return r.connection.String()
+}
+
+func (r *PostgreSQLConnection) Close() error {
+	if err := r.db.Close(); err != nil {
+		return errors.NewCloseConnectionError(err)
+	}
+
+	if r.directDB != r.db {
+		if err := r.directDB.Close(); err != nil {
+			return errors.NewCloseConnectionError(err)
+		}
+	}
+
+	return nil
+}
+
+func (r *PostgreSQLConnection) Check(ctx context.Context) error {
+	if err := r.db.Ping(ctx); err != nil {
+		if postgresqlErrorHasCode(err, postgresqlInvalidCatalogNameCode) {
+			return errors.NewCheckConnectionError(r.missingMaintenanceDatabase(err))
+		}
+
+		return checkConnectionError(postgresqlStatementTimeout(err))
+	}
+
+	return nil
+}
+
+// Healthcheck verifies the Kamaji role is allowed to create the tenant databases and roles,
+// as granted by the CREATEDB and CREATEROLE attributes, or by the superuser one.
+func (r *PostgreSQLConnection) Healthcheck(ctx context.Context) error {
+	var canCreateDB, canCreateRole bool
+
+	if _, err := r.db.QueryOneContext(ctx, pg.Scan(&canCreateDB, &canCreateRole), postgresqlHealthcheckStatement); err != nil {
+		return errors.NewHealthcheckError(postgresqlStatementTimeout(err))
+	}
+
+	var missing []string
+
+	if !canCreateDB {
+		missing = append(missing, "CREATEDB")
+	}
+
+	if !canCreateRole {
+		missing = append(missing, "CREATEROLE")
+	}
+
+	if len(missing) > 0 {
+		return errors.NewMissingPrivilegesError(r.db.Options().User, missing)
+	}
+
+	return nil
+}
+
+func (r *PostgreSQLConnection) Exec(ctx context.Context, dbName, statement string) error {
+	dbConn := r.switchDatabaseFn(postgresqlIdentifier(dbName))
+	defer dbConn.Close()
+
+	if _, err := r.exec(ctx, dbConn, statement); err != nil {
+		return errors.NewExecError(postgresqlStatementTimeout(err))
+	}
+
+	return nil
+}
+
+// TruncateData drops the relations of the tenant schema of the given database, such as the kine table and its sequence:
+// the database, its ownership, and the default privileges of the tenant schema are preserved.
+func (r *PostgreSQLConnection) TruncateData(ctx context.Context, schema string) error {
+	dbConn := r.switchDatabaseFn(postgresqlIdentifier(schema))
+	defer dbConn.Close()
+
+	var statements []string
+
+	if _, err := dbConn.QueryContext(ctx, &statements, postgresqlFetchDropStatements, postgresqlTenantSchema); err != nil {
+		return errors.NewTruncateDataError(postgresqlStatementTimeout(err))
+	}
+
+	for _, statement := range statements {
+		if _, err := r.exec(ctx, dbConn, statement); err != nil {
+			return errors.NewTruncateDataError(postgresqlStatementTimeout(err))
+		}
+	}
+
+	return nil
+}
+
+// exec performs the given DDL statement, recording it to the audit sink: the parameters are not recorded,
+// since used for the sensitive values, such as the user password.
+// The statements against the server database are performed in the transaction, if any.
+func (r *PostgreSQLConnection) exec(ctx context.Context, db *pg.DB, statement string, params ...any) (res pg.Result, err error) {
+	switch {
+	case r.tx != nil && db == r.db:
+		res, err = r.tx.ExecContext(ctx, statement, params...)
+	default:
+		res, err = db.ExecContext(ctx, statement, params...)
+	}
+	audit(ctx, r.Driver(), statement, err)
+
+	return res, err
+}
+
+// missingMaintenanceDatabase decorates the error returned when connecting to a non-existing maintenance database.
+func (r *PostgreSQLConnection) missingMaintenanceDatabase(err error) error {
+	return fmt.Errorf("the maintenance database %s does not exist: %w", r.dbName, err)
+}
+
+func (r *PostgreSQLConnection) kineTableExists(ctx context.Context, db *pg.DB) (bool, error) {
+	var tableExists string
+
+	if _, err := db.QueryContext(ctx, pg.Scan(&tableExists), postgresqlKineTableExistsStatement, postgresqlTenantSchema, "kine"); err != nil {
+		return false, err
+	}
+
+	return tableExists == "t", nil
+}
+
+// postgresqlIdentifier normalizes the given identifier to lowercase, since PostgreSQL folds the unquoted ones
+// used in the DDL statements: the catalog lookups, as well as the connections to a database, are case-sensitive.
+func postgresqlIdentifier(name string) string {
+	return strings.ToLower(name)
+}
+
+// postgresqlErrorHasCode returns true if the given error is a PostgreSQL one with any of the given SQLSTATE codes.
+func postgresqlErrorHasCode(err error, codes ...string) bool {
+	var pgErr pg.Error
+	if !goerrors.As(err, &pgErr) {
+		return false
+	}
+
+	for _, code := range codes {
+		if pgErr.Field('C') == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// postgresqlStatementTimeout returns a StatementTimeoutError if the given error has been caused
+// by the statement_timeout, or by the connection write timeout.
+func postgresqlStatementTimeout(err error) error {
+	if postgresqlErrorHasCode(err, postgresqlQueryCanceledCode) {
+		return errors.NewStatementTimeoutError(err)
+	}
+
+	var netErr net.Error
+	if goerrors.As(err, &netErr) && netErr.Timeout() {
+		return errors.NewStatementTimeoutError(err)
+	}
+
+	return err
+}
+
+// Lock acquires the session-level advisory lock with a dedicated connection, bypassing the connection pooler
+// since the session could be shared otherwise: the lock is released along with the session upon failures.
+func (r *PostgreSQLConnection) Lock(ctx context.Context, key string) (func(ctx context.Context) error, error) {
+	name := advisoryLockName(key)
+
+	conn := r.directDB.Conn()
+
+	var acquired bool
+	if _, err := conn.QueryOneContext(ctx, pg.Scan(&acquired), postgresqlTryAdvisoryLockStatement, name); err != nil {
+		_ = conn.Close()
+
+		return nil, errors.NewLockError(postgresqlStatementTimeout(err))
+	}
+
+	if !acquired {
+		_ = conn.Close()
+
+		return nil, errors.NewLockedError(key)
+	}
+
+	return func(ctx context.Context) error {
+		defer conn.Close()
+
+		if _, err := conn.ExecContext(ctx, postgresqlAdvisoryUnlockStatement, name); err != nil {
+			return errors.NewUnlockError(postgresqlStatementTimeout(err))
+		}
+
+		return nil
+	}, nil
+}
@@ -0,0 +1,259 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// testPostgreSQLStatement is a statement received by the testPostgreSQLServer, along with the database
+// the connection has been established to.
+type testPostgreSQLStatement struct {
+	Database string
+	Query    string
+}
+
+// testPostgreSQLResult is the reply to a statement: the rows are returned in the text format,
+// and an error with the given SQLSTATE, and the optional message, is returned instead when the code is set.
+type testPostgreSQLResult struct {
+	Columns []string
+	Rows    [][]string
+	Code    string
+	Message string
+}
+
+// testPostgreSQLServer is a minimal PostgreSQL server speaking the simple query protocol used by go-pg:
+// it replies to the statements with the results registered by Reply, returning no rows otherwise.
+type testPostgreSQLServer struct {
+	listener net.Listener
+
+	mu         sync.Mutex
+	results    map[string]testPostgreSQLResult
+	statements []testPostgreSQLStatement
+}
+
+func newTestPostgreSQLServer() *testPostgreSQLServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	server := &testPostgreSQLServer{listener: listener, results: map[string]testPostgreSQLResult{}}
+
+	go server.serve()
+
+	DeferCleanup(listener.Close)
+
+	return server
+}
+
+// newTestPostgreSQLConnection returns a PostgreSQLConnection to a new testPostgreSQLServer,
+// targeting the postgres maintenance database.
+func newTestPostgreSQLConnection() (*PostgreSQLConnection, *testPostgreSQLServer) {
+	server := newTestPostgreSQLServer()
+
+	connection, err := NewPostgreSQLConnection(ConnectionConfig{
+		User:      "kamaji",
+		Password:  "secret",
+		Endpoints: []ConnectionEndpoint{{Host: "127.0.0.1", Port: server.listener.Addr().(*net.TCPAddr).Port}}, //nolint:forcetypeassert
+		DBName:    "postgres",
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	DeferCleanup(connection.Close)
+
+	return connection.(*PostgreSQLConnection), server //nolint:forcetypeassert
+}
+
+// testPostgreSQLQuery formats the given statement as go-pg does, replacing the placeholders with the quoted parameters.
+func testPostgreSQLQuery(statement string, params ...string) string {
+	for _, param := range params {
+		statement = strings.Replace(statement, "?", "'"+strings.ReplaceAll(param, "'", "''")+"'", 1)
+	}
+
+	return statement
+}
+
+// Reply registers the result returned for the given query, matched verbatim.
+func (s *testPostgreSQLServer) Reply(query string, result testPostgreSQLResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.results[query] = result
+}
+
+// Statements returns the statements received so far, in order.
+func (s *testPostgreSQLServer) Statements() []testPostgreSQLStatement {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]testPostgreSQLStatement{}, s.statements...)
+}
+
+// Queries returns the queries received so far against the given database, in order.
+func (s *testPostgreSQLServer) Queries(database string) []string {
+	var queries []string
+
+	for _, statement := range s.Statements() {
+		if statement.Database == database {
+			queries = append(queries, statement.Query)
+		}
+	}
+
+	return queries
+}
+
+func (s *testPostgreSQLServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer GinkgoRecover()
+			defer conn.Close()
+
+			if err := s.handle(conn); err != nil && !errors.Is(err, io.EOF) {
+				GinkgoWriter.Printf("test PostgreSQL server: %s\n", err)
+			}
+		}()
+	}
+}
+
+func (s *testPostgreSQLServer) handle(conn net.Conn) error {
+	reader := bufio.NewReader(conn)
+	// The startup message has no type, being made of the protocol version, and of the parameters.
+	startup, err := s.readMessage(reader)
+	if err != nil {
+		return err
+	}
+
+	parameters := bytes.Split(startup[4:], []byte{0})
+
+	var database string
+
+	for i := 0; i+1 < len(parameters); i += 2 {
+		if string(parameters[i]) == "database" {
+			database = string(parameters[i+1])
+		}
+	}
+
+	var out bytes.Buffer
+
+	writeTestPostgreSQLMessage(&out, 'R', int32(0))
+	writeTestPostgreSQLMessage(&out, 'Z', []byte("I"))
+
+	if _, err = conn.Write(out.Bytes()); err != nil {
+		return err
+	}
+
+	for {
+		typ, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		body, err := s.readMessage(reader)
+		if err != nil {
+			return err
+		}
+
+		switch typ {
+		case 'X':
+			return nil
+		case 'Q':
+			query := string(bytes.TrimSuffix(body, []byte{0}))
+
+			s.mu.Lock()
+			s.statements = append(s.statements, testPostgreSQLStatement{Database: database, Query: query})
+			result := s.results[query]
+			s.mu.Unlock()
+
+			out.Reset()
+			writeTestPostgreSQLResult(&out, result)
+			writeTestPostgreSQLMessage(&out, 'Z', []byte("I"))
+
+			if _, err = conn.Write(out.Bytes()); err != nil {
+				return err
+			}
+		default:
+			return errors.New("unsupported message " + string(typ))
+		}
+	}
+}
+
+func (s *testPostgreSQLServer) readMessage(reader io.Reader) ([]byte, error) {
+	var length int32
+	if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, length-4)
+	_, err := io.ReadFull(reader, body)
+
+	return body, err
+}
+
+func writeTestPostgreSQLResult(out *bytes.Buffer, result testPostgreSQLResult) {
+	if len(result.Code) > 0 {
+		message := result.Message
+		if len(message) == 0 {
+			message = "test error " + result.Code
+		}
+
+		writeTestPostgreSQLMessage(out, 'E', []byte("SERROR\x00C"+result.Code+"\x00M"+message+"\x00\x00"))
+
+		return
+	}
+
+	if len(result.Columns) > 0 {
+		var description bytes.Buffer
+
+		_ = binary.Write(&description, binary.BigEndian, int16(len(result.Columns)))
+
+		for _, column := range result.Columns {
+			description.WriteString(column + "\x00")
+			// The table OID, the attribute number, the text type OID, its size, modifier, and format.
+			for _, field := range []any{int32(0), int16(0), int32(25), int16(-1), int32(-1), int16(0)} {
+				_ = binary.Write(&description, binary.BigEndian, field)
+			}
+		}
+
+		writeTestPostgreSQLMessage(out, 'T', description.Bytes())
+
+		for _, row := range result.Rows {
+			var data bytes.Buffer
+
+			_ = binary.Write(&data, binary.BigEndian, int16(len(row)))
+
+			for _, value := range row {
+				_ = binary.Write(&data, binary.BigEndian, int32(len(value)))
+				data.WriteString(value)
+			}
+
+			writeTestPostgreSQLMessage(out, 'D', data.Bytes())
+		}
+	}
+
+	writeTestPostgreSQLMessage(out, 'C', []byte("OK\x00"))
+}
+
+func writeTestPostgreSQLMessage(out *bytes.Buffer, typ byte, body any) {
+	var payload bytes.Buffer
+
+	_ = binary.Write(&payload, binary.BigEndian, body)
+
+	out.WriteByte(typ)
+	_ = binary.Write(out, binary.BigEndian, int32(payload.Len()+4))
+	out.Write(payload.Bytes())
+}
@@ -0,0 +1,189 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PostgreSQL connection", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should fold the mixed-case names to lowercase", func() {
+		connection, server := newTestPostgreSQLConnection()
+		server.Reply(testPostgreSQLQuery(postgresqlUserExists, "legacy_user"), testPostgreSQLResult{Columns: []string{"exists"}, Rows: [][]string{{"1"}}})
+
+		Expect(connection.UserExists(ctx, "Legacy_User")).To(BeTrue())
+		Expect(connection.GrantPrivileges(ctx, "Legacy_User", "Legacy_Schema")).To(Succeed())
+
+		Expect(server.Queries("postgres")).To(ContainElement("GRANT ALL PRIVILEGES ON DATABASE legacy_schema TO legacy_user"))
+		Expect(server.Queries("legacy_schema")).ToNot(BeEmpty())
+	})
+
+	Describe("granting the privileges in a transaction", func() {
+		It("should leave the tenant database privileges to the caller", func() {
+			connection, server := newTestPostgreSQLConnection()
+
+			Expect(connection.Transaction(ctx, func(ctx context.Context, tx Connection) error {
+				return tx.GrantPrivileges(ctx, "tenant", "tenant")
+			})).To(Succeed())
+
+			Expect(server.Queries("postgres")).To(Equal([]string{
+				"BEGIN",
+				"GRANT ALL PRIVILEGES ON DATABASE tenant TO tenant",
+				"ALTER DATABASE tenant OWNER TO tenant",
+				"COMMIT",
+			}))
+			Expect(server.Queries("tenant")).To(BeEmpty())
+
+			Expect(connection.GrantPrivileges(ctx, "tenant", "tenant")).To(Succeed())
+
+			Expect(server.Queries("tenant")).To(ContainElements(
+				"ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT ALL PRIVILEGES ON TABLES TO tenant",
+				"ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT ALL PRIVILEGES ON SEQUENCES TO tenant",
+			))
+		})
+
+		It("should change the kine table ownership out of the transaction", func() {
+			connection, server := newTestPostgreSQLConnection()
+			server.Reply(testPostgreSQLQuery(postgresqlKineTableExistsStatement, "public", "kine"), testPostgreSQLResult{Columns: []string{"exists"}, Rows: [][]string{{"t"}}})
+
+			Expect(connection.GrantPrivileges(ctx, "tenant", "tenant")).To(Succeed())
+
+			Expect(server.Queries("tenant")).To(ContainElement("ALTER TABLE kine OWNER TO tenant"))
+		})
+	})
+
+	DescribeTable("creating the database concurrently created",
+		func(code string) {
+			connection, server := newTestPostgreSQLConnection()
+			server.Reply("CREATE DATABASE tenant", testPostgreSQLResult{Code: code})
+
+			Expect(connection.CreateDB(ctx, "tenant")).To(Succeed())
+		},
+		Entry("should succeed when the database already exists", postgresqlDuplicateDatabaseCode),
+		Entry("should succeed when the catalog entry is concurrently inserted", postgresqlUniqueViolationCode),
+	)
+
+	It("should fail the database creation for any other error", func() {
+		connection, server := newTestPostgreSQLConnection()
+		server.Reply("CREATE DATABASE tenant", testPostgreSQLResult{Code: "53100"})
+
+		Expect(connection.CreateDB(ctx, "tenant")).ToNot(Succeed())
+	})
+
+	Describe("checking the user login", func() {
+		It("should report the user allowed to log in", func() {
+			connection, server := newTestPostgreSQLConnection()
+			server.Reply(testPostgreSQLQuery(postgresqlUserHasLogin, "tenant"), testPostgreSQLResult{Columns: []string{"?column?"}, Rows: [][]string{{"1"}}})
+
+			Expect(connection.UserHasLogin(ctx, "tenant")).To(BeTrue())
+		})
+
+		It("should report the user with the NOLOGIN attribute", func() {
+			connection, _ := newTestPostgreSQLConnection()
+
+			Expect(connection.UserHasLogin(ctx, "tenant")).To(BeFalse())
+		})
+
+		It("should restore the login upon setting the password", func() {
+			connection, server := newTestPostgreSQLConnection()
+
+			Expect(connection.SetUserPassword(ctx, "tenant", "secret")).To(Succeed())
+
+			Expect(server.Queries("postgres")).To(Equal([]string{"ALTER ROLE tenant LOGIN PASSWORD 'secret'"}))
+		})
+	})
+
+	Describe("listing the grants", func() {
+		It("should return the privileges of the user across the databases", func() {
+			connection, server := newTestPostgreSQLConnection()
+			server.Reply(testPostgreSQLQuery(postgresqlListGrantsStatement, "tenant"), testPostgreSQLResult{
+				Columns: []string{"schema", "privilege", "grant_option"},
+				Rows: [][]string{
+					{"archive", "CONNECT", "f"},
+					{"tenant", "CONNECT", "t"},
+					{"tenant", "CREATE", "t"},
+					{"tenant", "TEMPORARY", "f"},
+				},
+			})
+
+			Expect(connection.ListGrants(ctx, "Tenant")).To(Equal([]Grant{
+				{Schema: "archive", Privilege: "CONNECT"},
+				{Schema: "tenant", Privilege: "CONNECT", GrantOption: true},
+				{Schema: "tenant", Privilege: "CREATE", GrantOption: true},
+				{Schema: "tenant", Privilege: "TEMPORARY"},
+			}))
+		})
+
+		It("should return no grants for a user with no privileges", func() {
+			connection, _ := newTestPostgreSQLConnection()
+
+			Expect(connection.ListGrants(ctx, "tenant")).To(BeEmpty())
+		})
+	})
+
+	Describe("checking a single privilege", func() {
+		It("should report the privilege held by the user", func() {
+			connection, server := newTestPostgreSQLConnection()
+			server.Reply(testPostgreSQLQuery(postgresqlHasPrivilegeStatement, "tenant", "create", "tenant"), testPostgreSQLResult{Columns: []string{"has_database_privilege"}, Rows: [][]string{{"t"}}})
+
+			Expect(connection.HasPrivilege(ctx, "tenant", "tenant", "create")).To(BeTrue())
+		})
+
+		It("should report the privilege not held by the user", func() {
+			connection, server := newTestPostgreSQLConnection()
+			server.Reply(testPostgreSQLQuery(postgresqlHasPrivilegeStatement, "tenant", "create", "tenant"), testPostgreSQLResult{Columns: []string{"has_database_privilege"}, Rows: [][]string{{"f"}}})
+
+			Expect(connection.HasPrivilege(ctx, "tenant", "tenant", "create")).To(BeFalse())
+		})
+
+		It("should report no privilege for a missing user", func() {
+			connection, _ := newTestPostgreSQLConnection()
+
+			Expect(connection.HasPrivilege(ctx, "tenant", "tenant", "create")).To(BeFalse())
+		})
+
+		It("should report no privilege for a missing database", func() {
+			connection, server := newTestPostgreSQLConnection()
+			server.Reply(testPostgreSQLQuery(postgresqlHasPrivilegeStatement, "tenant", "create", "tenant"), testPostgreSQLResult{Code: postgresqlInvalidCatalogNameCode, Message: `database "tenant" does not exist`})
+
+			Expect(connection.HasPrivilege(ctx, "tenant", "tenant", "create")).To(BeFalse())
+		})
+	})
+
+	Describe("revoking all the privileges and disabling the user", func() {
+		It("should revoke the default privileges once the transaction is committed", func() {
+			connection, server := newTestPostgreSQLConnection()
+
+			Expect(connection.RevokeAllAndDisableUser(ctx, "tenant", "tenant")).To(Succeed())
+
+			Expect(server.Queries("postgres")).To(Equal([]string{
+				"BEGIN",
+				"REVOKE ALL PRIVILEGES ON DATABASE tenant FROM tenant",
+				"ALTER ROLE tenant NOLOGIN",
+				"COMMIT",
+				testPostgreSQLQuery(postgresqlTerminateSessionsStatement, "tenant"),
+			}))
+			Expect(server.Queries("tenant")).To(Equal([]string{
+				"ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE ALL PRIVILEGES ON TABLES FROM tenant",
+				"ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE ALL PRIVILEGES ON SEQUENCES FROM tenant",
+			}))
+		})
+
+		It("should succeed when the tenant database has been already deleted", func() {
+			connection, server := newTestPostgreSQLConnection()
+			server.Reply("ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE ALL PRIVILEGES ON TABLES FROM tenant", testPostgreSQLResult{Code: postgresqlInvalidCatalogNameCode})
+
+			Expect(connection.RevokeAllAndDisableUser(ctx, "tenant", "tenant")).To(Succeed())
+		})
+	})
+})
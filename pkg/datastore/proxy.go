@@ -0,0 +1,147 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+func init() {
+	// The x/net proxy package is supporting just the SOCKS5 scheme:
+	// registering the HTTP one allows using HTTP CONNECT proxies, also from the ALL_PROXY environment variable.
+	proxy.RegisterDialerType("http", newHTTPConnectDialer)
+}
+
+// newProxyDialer returns the dialer used to reach the data store endpoints through the given proxy URL:
+// if empty, the ALL_PROXY and NO_PROXY environment variables are used.
+// A nil dialer is returned when no proxy is configured, letting the drivers use their default dialers.
+func newProxyDialer(proxyURL string) (proxy.ContextDialer, error) {
+	var dialer proxy.Dialer
+
+	switch {
+	case len(proxyURL) > 0:
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse the proxy URL: %w", err)
+		}
+
+		if dialer, err = proxy.FromURL(u, proxy.Direct); err != nil {
+			return nil, fmt.Errorf("cannot create the proxy dialer: %w", err)
+		}
+	default:
+		if dialer = proxy.FromEnvironment(); dialer == proxy.Direct {
+			return nil, nil
+		}
+	}
+
+	contextDialer, ok := dialer.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("the proxy dialer %T doesn't support context", dialer)
+	}
+
+	return contextDialer, nil
+}
+
+// proxyNetworkName returns the name of the network registered in the MySQL driver for the given proxy URL:
+// the driver registry is global, thus the name must be unique per proxy and free of DSN reserved characters.
+func proxyNetworkName(proxyURL string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(proxyURL))
+
+	return fmt.Sprintf("proxy-%x", h.Sum64())
+}
+
+// httpConnectDialer tunnels the connections through an HTTP proxy by means of the CONNECT method.
+type httpConnectDialer struct {
+	proxyURL *url.URL
+	forward  proxy.Dialer
+}
+
+func newHTTPConnectDialer(proxyURL *url.URL, forward proxy.Dialer) (proxy.Dialer, error) {
+	return &httpConnectDialer{proxyURL: proxyURL, forward: forward}, nil
+}
+
+func (h *httpConnectDialer) Dial(network, addr string) (net.Conn, error) {
+	return h.DialContext(context.Background(), network, addr)
+}
+
+func (h *httpConnectDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	var conn net.Conn
+	var err error
+
+	if forward, ok := h.forward.(proxy.ContextDialer); ok {
+		conn, err = forward.DialContext(ctx, network, h.proxyURL.Host)
+	} else {
+		conn, err = h.forward.Dial(network, h.proxyURL.Host)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+
+	if user := h.proxyURL.User; user != nil {
+		password, _ := user.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password)))
+	}
+
+	if err = req.Write(conn); err != nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("cannot send the CONNECT request to the proxy: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+
+	res, err := http.ReadResponse(reader, req)
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("cannot read the CONNECT response from the proxy: %w", err)
+	}
+	_ = res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("the proxy refused the connection to %s: %s", addr, res.Status)
+	}
+	// Some data stores, such as MySQL, are sending the greeting as soon as the connection is established:
+	// the bytes already buffered by the reader must not get lost.
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+
+	return conn, nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (b *bufferedConn) Read(p []byte) (int, error) {
+	return b.reader.Read(p)
+}
@@ -0,0 +1,43 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"github.com/clastix/kamaji/pkg/datastore/errors"
+)
+
+// waitReadyInterval is the interval between the connection checks performed by WaitReady.
+const waitReadyInterval = time.Second
+
+// WaitReady waits for the data store to accept connections, checking it until the given timeout elapses,
+// rather than failing immediately when it's not ready yet, such as a just created instance: a NotReadyError
+// is returned upon the timeout. The errors not due to the connectivity, such as the refused credentials,
+// are returned immediately, since they're not going to be solved by waiting.
+func WaitReady(ctx context.Context, connection Connection, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(waitReadyInterval)
+	defer ticker.Stop()
+
+	for {
+		err := connection.Check(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if ClassifyError(err) != TransientErrorClass {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.NewNotReadyError(err)
+		case <-ticker.C:
+		}
+	}
+}
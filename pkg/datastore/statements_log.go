@@ -0,0 +1,62 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+
+	"github.com/go-pg/pg/v10"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+)
+
+// statementLogVerbosity is the verbosity level the statements performed against the SQL data stores are logged at,
+// since too noisy for production, and possibly leaking the schema details.
+const statementLogVerbosity = 2
+
+type statementLoggingKey struct{}
+
+// WithStatementLogging returns a context logging the statements performed against the SQL data stores regardless
+// of the configured verbosity, allowing to troubleshoot a single Tenant Control Plane.
+func WithStatementLogging(ctx context.Context) context.Context {
+	return context.WithValue(ctx, statementLoggingKey{}, true)
+}
+
+// logStatement logs the given statement with the redacted passwords, if enabled by the verbosity or the context.
+func logStatement(ctx context.Context, driver, statement string, err error) {
+	logger := log.FromContext(ctx)
+
+	if enabled, _ := ctx.Value(statementLoggingKey{}).(bool); !enabled {
+		logger = logger.V(statementLogVerbosity)
+	}
+
+	if !logger.Enabled() {
+		return
+	}
+
+	keysAndValues := []any{"driver", driver, "statement", redactStatement(statement)}
+	if err != nil {
+		keysAndValues = append(keysAndValues, "error", err.Error())
+	}
+
+	logger.Info("datastore statement performed", keysAndValues...)
+}
+
+// postgresqlStatementLogger is the PostgreSQL query hook logging the performed statements,
+// including the queries and the ones performed in a transaction.
+type postgresqlStatementLogger struct{}
+
+func (postgresqlStatementLogger) BeforeQuery(ctx context.Context, _ *pg.QueryEvent) (context.Context, error) {
+	return ctx, nil
+}
+
+func (postgresqlStatementLogger) AfterQuery(ctx context.Context, event *pg.QueryEvent) error {
+	// The formatted query is logged, since the parameters are redacted as the statement literals.
+	if query, err := event.FormattedQuery(); err == nil {
+		logStatement(ctx, string(kamajiv1alpha1.KinePostgreSQLDriver), string(query), event.Err)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDataStore(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "DataStore Suite")
+}
+
+// newTestMySQLConnection returns a MySQLConnection backed by a mocked database, matching the statements verbatim:
+// the expectations are verified once the spec is completed.
+func newTestMySQLConnection() (*MySQLConnection, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	Expect(err).ToNot(HaveOccurred())
+
+	DeferCleanup(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+		_ = db.Close()
+	})
+
+	return &MySQLConnection{db: db}, mock
+}
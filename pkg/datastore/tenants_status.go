@@ -0,0 +1,94 @@
+// Copyright 2022 Clastix Labs
+// SPDX-License-Identifier: Apache-2.0
+
+package datastore
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kamajiv1alpha1 "github.com/clastix/kamaji/api/v1alpha1"
+)
+
+// TenantStatus is the provisioning state of a Tenant Control Plane data, as found in its DataStore.
+type TenantStatus struct {
+	TenantControlPlane string      `json:"tenantControlPlane"`
+	Schema             string      `json:"schema"`
+	User               string      `json:"user"`
+	SchemaExists       bool        `json:"schemaExists"`
+	UserExists         bool        `json:"userExists"`
+	PrivilegesExist    bool        `json:"privilegesExist"`
+	LastSetup          metav1.Time `json:"lastSetup,omitempty"`
+	// Grants are all the privileges held by the user across the schemas, helping to troubleshoot the permission issues.
+	Grants []Grant `json:"grants,omitempty"`
+	// Error reports the failure of the existence checks, leaving the other ones unset.
+	Error string `json:"error,omitempty"`
+}
+
+// GetTenantsStatus returns the provisioning state of all the Tenant Control Planes using the given DataStore:
+// it performs only the existence checks, with no changes against the datastore.
+func GetTenantsStatus(ctx context.Context, client client.Client, ds kamajiv1alpha1.DataStore) ([]TenantStatus, error) {
+	tcpList := &kamajiv1alpha1.TenantControlPlaneList{}
+	if err := client.List(ctx, tcpList); err != nil {
+		return nil, errors.Wrap(err, "cannot list the Tenant Control Planes")
+	}
+
+	connection, err := NewStorageConnection(ctx, client, ds)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create the storage connection")
+	}
+	defer connection.Close()
+
+	statuses := make([]TenantStatus, 0, len(tcpList.Items))
+
+	for i := range tcpList.Items {
+		tcp := tcpList.Items[i]
+
+		if tcp.Status.Storage.DataStoreName != ds.GetName() {
+			continue
+		}
+
+		statuses = append(statuses, getTenantStatus(ctx, connection, ds, tcp))
+	}
+
+	return statuses, nil
+}
+
+func getTenantStatus(ctx context.Context, connection Connection, ds kamajiv1alpha1.DataStore, tcp kamajiv1alpha1.TenantControlPlane) TenantStatus {
+	schema, user := tcp.DataStoreSchemaAndSharedUser(ds)
+
+	status := TenantStatus{
+		TenantControlPlane: types.NamespacedName{Namespace: tcp.GetNamespace(), Name: tcp.GetName()}.String(),
+		Schema:             schema,
+		User:               user,
+		LastSetup:          tcp.Status.Storage.Setup.LastUpdate,
+	}
+
+	if err := getTenantChecks(ctx, connection, &status); err != nil {
+		status.Error = err.Error()
+	}
+
+	return status
+}
+
+func getTenantChecks(ctx context.Context, connection Connection, status *TenantStatus) (err error) {
+	if status.SchemaExists, err = connection.DBExists(ctx, status.Schema); err != nil {
+		return err
+	}
+
+	if status.UserExists, err = connection.UserExists(ctx, status.User); err != nil {
+		return err
+	}
+
+	if status.PrivilegesExist, err = connection.GrantPrivilegesExists(ctx, status.User, status.Schema); err != nil {
+		return err
+	}
+
+	status.Grants, err = connection.ListGrants(ctx, status.User)
+
+	return err
+}